@@ -0,0 +1,190 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+    "time"
+
+    "clicker/internal/application/usecase"
+    "clicker/internal/config"
+    "clicker/internal/infrastructure/persistence/archive"
+    "clicker/internal/infrastructure/persistence/postgres"
+    "clicker/internal/infrastructure/persistence/redis"
+
+    "github.com/jackc/pgx/v5/pgxpool"
+    goredis "github.com/redis/go-redis/v9"
+)
+
+const dateLayout = "2006-01-02"
+
+const usage = `usage:
+  clicker archive [-before YYYY-MM-DD]
+      move clicks partitions ending before the date (CLICKS_ARCHIVE_AFTER_DAYS
+      ago by default) to CLICKS_ARCHIVE_DIR and drop them
+  clicker restore -from YYYY-MM-DD -to YYYY-MM-DD
+      load archived clicks in [from, to) into the restored_clicks table,
+      readable through GET /export?source=restored
+  clicker reconcile [-dry-run]
+      compare the hourly click counters in Redis with Postgres for the last
+      day and rewrite the ones that drifted
+  clicker rebuild-cache
+      rewrite the clicks of the last 24 hours in Redis from Postgres`
+
+// clicker runs maintenance commands against the clicker database. Dates
+// are UTC.
+func main() {
+    if len(os.Args) < 2 {
+        fmt.Fprintln(os.Stderr, usage)
+        os.Exit(2)
+    }
+
+    cfg, err := config.New()
+    if err != nil {
+        log.Fatalf("Failed to load config: %v", err)
+    }
+
+    ctx := context.Background()
+    db, err := pgxpool.New(ctx, cfg.GetPostgresDSN())
+    if err != nil {
+        log.Fatalf("Failed to connect to database: %v", err)
+    }
+    defer db.Close()
+
+    archives := usecase.NewArchiveUseCase(
+        postgres.NewClickPartitionRepository(db),
+        postgres.NewClickArchiveRepository(db),
+        archive.NewLocalStore(cfg.Archive.Dir),
+        postgres.NewRetentionRepository(db),
+        usecase.ArchivePolicy{After: time.Duration(cfg.Archive.AfterDays) * 24 * time.Hour},
+    )
+
+    switch os.Args[1] {
+    case "archive":
+        runArchive(ctx, archives, cfg, os.Args[2:])
+    case "restore":
+        runRestore(ctx, archives, os.Args[2:])
+    case "reconcile":
+        runReconcile(ctx, db, cfg, os.Args[2:])
+    case "rebuild-cache":
+        runRebuildCache(ctx, db, cfg)
+    default:
+        fmt.Fprintln(os.Stderr, usage)
+        os.Exit(2)
+    }
+}
+
+func runArchive(ctx context.Context, archives usecase.ArchiveUseCase, cfg *config.Config, args []string) {
+    flags := flag.NewFlagSet("archive", flag.ExitOnError)
+    beforeFlag := flags.String("before", "", "archive partitions ending before this date")
+    flags.Parse(args)
+
+    var before time.Time
+    switch {
+    case *beforeFlag != "":
+        before = parseDate("before", *beforeFlag)
+    case cfg.Archive.AfterDays > 0:
+        before = time.Now().AddDate(0, 0, -cfg.Archive.AfterDays)
+    default:
+        log.Fatalf("Pass -before or set CLICKS_ARCHIVE_AFTER_DAYS")
+    }
+
+    written, err := archives.Archive(ctx, before)
+    if err != nil {
+        log.Fatalf("Archive failed after %d partitions: %v", len(written), err)
+    }
+
+    for _, a := range written {
+        log.Printf("%s: %d rows in %s", a.Partition, a.Rows, a.File)
+    }
+    log.Printf("Archived %d partitions to %s", len(written), cfg.Archive.Dir)
+}
+
+func runRestore(ctx context.Context, archives usecase.ArchiveUseCase, args []string) {
+    flags := flag.NewFlagSet("restore", flag.ExitOnError)
+    fromFlag := flags.String("from", "", "first day to restore")
+    toFlag := flags.String("to", "", "day after the last one to restore")
+    flags.Parse(args)
+
+    if *fromFlag == "" || *toFlag == "" {
+        log.Fatalf("Both -from and -to are required")
+    }
+
+    restored, err := archives.Restore(ctx, parseDate("from", *fromFlag), parseDate("to", *toFlag))
+    if err != nil {
+        log.Fatalf("Restore failed after %d clicks: %v", restored, err)
+    }
+
+    log.Printf("Restored %d clicks into restored_clicks, export them with source=restored", restored)
+}
+
+func runReconcile(ctx context.Context, db *pgxpool.Pool, cfg *config.Config, args []string) {
+    flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+    dryRun := flags.Bool("dry-run", false, "only report drift, do not repair")
+    flags.Parse(args)
+
+    client := newRedis(cfg)
+    defer client.Close()
+
+    reconciler := usecase.NewReconcileUseCase(
+        postgres.NewClickHourSource(db),
+        redis.NewClickHourCache(client),
+        redis.NewClickCoverage(client),
+        redis.NewStatsCache(client),
+    )
+
+    report, err := reconciler.Reconcile(ctx, *dryRun)
+    if err != nil {
+        log.Fatalf("Reconcile failed: %v", err)
+    }
+    if report == nil {
+        log.Fatalf("Another replica is reconciling, try again later")
+    }
+
+    for _, d := range report.Drifted {
+        fmt.Printf("tenant %d\tbanner %d\t%s\tpostgres %d\tredis %d\n",
+            d.TenantID, d.BannerID, d.Hour.Format(time.RFC3339), d.Postgres, d.Redis)
+    }
+    log.Printf("Checked %d hours, %d drifted, %d repaired", report.Checked, len(report.Drifted), report.Repaired)
+}
+
+func runRebuildCache(ctx context.Context, db *pgxpool.Pool, cfg *config.Config) {
+    client := newRedis(cfg)
+    defer client.Close()
+
+    rebuilder := usecase.NewCacheRebuildUseCase(
+        postgres.NewClickHourSource(db),
+        redis.NewClickHourCache(client),
+        redis.NewClickCoverage(client),
+        redis.NewStatsCache(client),
+    )
+
+    rebuild, err := rebuilder.Rebuild(ctx)
+    if err != nil {
+        log.Fatalf("Rebuild failed: %v", err)
+    }
+    if rebuild == nil {
+        log.Fatalf("Another replica is rebuilding or reconciling, try again later")
+    }
+
+    log.Printf("Rebuilt %d banner hours from %s to %s", rebuild.HoursDone,
+        rebuild.From.Format(time.RFC3339), rebuild.To.Format(time.RFC3339))
+}
+
+func newRedis(cfg *config.Config) *goredis.Client {
+    return goredis.NewClient(&goredis.Options{
+        Addr:     cfg.GetRedisAddress(),
+        Password: cfg.Redis.Password,
+        DB:       cfg.Redis.DB,
+    })
+}
+
+func parseDate(name, value string) time.Time {
+    t, err := time.Parse(dateLayout, value)
+    if err != nil {
+        log.Fatalf("Invalid -%s %q, expected %s", name, value, dateLayout)
+    }
+    return t
+}
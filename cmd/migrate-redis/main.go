@@ -0,0 +1,34 @@
+package main
+
+import (
+    "context"
+    "log"
+
+    "clicker/internal/config"
+    "clicker/internal/infrastructure/persistence/redis"
+
+    goredis "github.com/redis/go-redis/v9"
+)
+
+// migrate-redis moves click counters written before the hourly hash layout
+// into it. Run it once after upgrading; counters it misses simply expire.
+func main() {
+    cfg, err := config.New()
+    if err != nil {
+        log.Fatalf("Failed to load config: %v", err)
+    }
+
+    client := goredis.NewClient(&goredis.Options{
+        Addr:     cfg.GetRedisAddress(),
+        Password: cfg.Redis.Password,
+        DB:       cfg.Redis.DB,
+    })
+    defer client.Close()
+
+    moved, err := redis.MigrateClickKeys(context.Background(), client)
+    if err != nil {
+        log.Fatalf("Migration failed after %d keys: %v", moved, err)
+    }
+
+    log.Printf("Moved %d click keys to hourly hashes", moved)
+}
@@ -2,6 +2,7 @@ package app
 
 import (
     "context"
+    "crypto/rand"
     "fmt"
     "log"
     "net"
@@ -13,11 +14,23 @@ import (
 
     "clicker/internal/application/usecase"
     "clicker/internal/config"
+    "clicker/internal/infrastructure/persistence/archive"
+    "clicker/internal/infrastructure/persistence/embedded"
+    "clicker/internal/infrastructure/persistence/memory"
     "clicker/internal/infrastructure/persistence/redis"
     "clicker/internal/infrastructure/persistence/postgres"
     "clicker/internal/domain/repository"
     "clicker/internal/interfaces/grpc/handler"
+    "clicker/internal/interfaces/grpc/interceptor"
+    "clicker/internal/interfaces/redirect"
+    "clicker/internal/interfaces/sse"
+    "clicker/pkg/banner"
+    "clicker/pkg/cache"
     "clicker/pkg/counter"
+    "clicker/pkg/retention"
+    "clicker/pkg/serving"
+    "clicker/pkg/signed"
+    tenantpb "clicker/pkg/tenant"
     "clicker/pkg/stats"
     
     "github.com/gorilla/mux"
@@ -25,19 +38,52 @@ import (
     "google.golang.org/grpc"
     "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
     "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/protobuf/encoding/protojson"
     "github.com/jackc/pgx/v5/pgxpool"
 )
 
+// partitionMaintenanceInterval is how often click partitions are checked.
+// Partitions are made ahead of time, so this only has to be well below the
+// partition interval.
+const partitionMaintenanceInterval = time.Hour
+
+// rollupInterval is how often click rollups are brought up to date.
+const rollupInterval = 5 * time.Minute
+
+// retentionInterval is how often expired clicks are deleted. Horizons are
+// whole hours and days, so running more often gains nothing.
+const retentionInterval = time.Hour
+
+// archiveInterval is how often partitions are checked for archiving.
+const archiveInterval = time.Hour
+
+// cacheWarmUpInterval is how often Redis is checked for having lost the
+// recent clicks, so a flush is noticed within a minute.
+const cacheWarmUpInterval = time.Minute
+
+// reconcileInterval is how often Redis click counters are checked against
+// Postgres.
+const reconcileInterval = 15 * time.Minute
+
 type ServerManager struct {
     cfg        *config.Config
     httpServer *http.Server
     grpcServer *grpc.Server
     services   *Services
+    partitions usecase.PartitionUseCase
+    rollups    usecase.RollupUseCase
+    retention  usecase.RetentionUseCase
+    archive    usecase.ArchiveUseCase
+    reconcile  usecase.ReconcileUseCase
+    rebuild    usecase.CacheRebuildUseCase
 }
 
+// Services are the connections of the storage backend: Postgres and Redis,
+// the embedded file or, with STORAGE_BACKEND=memory, none at all.
 type Services struct {
-    db    *pgxpool.Pool
-    redis *goredis.Client
+    db       *pgxpool.Pool
+    redis    *goredis.Client
+    embedded *embedded.Store
 }
 
 func NewServerManager(cfg *config.Config) (*ServerManager, error) {
@@ -48,8 +94,14 @@ func NewServerManager(cfg *config.Config) (*ServerManager, error) {
         return nil, fmt.Errorf("failed to init services: %w", err)
     }
     
-    handlers := buildHandlers(services)
-    servers, err := buildServers(cfg, handlers)
+    repos := buildRepositories(cfg, services)
+    signer, err := buildSigner(cfg)
+    if err != nil {
+        return nil, err
+    }
+    useCases := buildUseCases(cfg, repos, signer)
+    handlers := buildHandlers(useCases)
+    servers, err := buildServers(cfg, handlers, useCases)
     if err != nil {
         return nil, fmt.Errorf("failed to build servers: %w", err)
     }
@@ -59,50 +111,234 @@ func NewServerManager(cfg *config.Config) (*ServerManager, error) {
         httpServer: servers.http,
         grpcServer: servers.grpc,
         services:   services,
+        partitions: useCases.partition,
+        rollups:    useCases.rollup,
+        retention:  useCases.retention,
+        archive:    useCases.archive,
+        reconcile:  useCases.reconcile,
+        rebuild:    useCases.rebuild,
     }, nil
 }
 
 func initServices(ctx context.Context, cfg *config.Config) (*Services, error) {
+    services := &Services{}
+    // Ни памяти, ни встроенному хранилищу не нужны Postgres и Redis.
+    switch cfg.Storage.Backend {
+    case config.StorageMemory:
+        return services, nil
+    case config.StorageEmbedded:
+        store, err := embedded.Open(cfg.Storage.Path)
+        if err != nil {
+            return nil, err
+        }
+        services.embedded = store
+        return services, nil
+    }
+
     db, err := initDatabase(ctx, cfg)
     if err != nil {
         return nil, err
     }
+    services.db = db
+    services.redis = initRedis(cfg)
 
-    redis := initRedis(cfg)
-
-    return &Services{
-        db:    db,
-        redis: redis,
-    }, nil
+    return services, nil
 }
 
 type Repositories struct {
-    click repository.ClickRepository
-    stats repository.StatsRepository
+    click        repository.ClickRepository
+    stats        repository.StatsRepository
+    banner       repository.BannerRepository
+    variant      repository.VariantRepository
+    variantStats repository.VariantStatsRepository
+    impression   repository.ImpressionRepository
+    tenant       repository.TenantRepository
+    quota        repository.QuotaRepository
+    totals       repository.BannerTotalsRepository
+    revision     repository.BannerRevisionRepository
+    top          repository.TopBannersRepository
+    feed         repository.ClickFeed
+    uniques      repository.UniqueClickRepository
+    export       repository.ClickExportRepository
+    statsCache   repository.StatsCache
+    partition    repository.ClickPartitionRepository
+    rollup       repository.RollupRepository
+    retention    repository.RetentionRepository
+    archive      repository.ClickArchiveRepository
+    archiveStore repository.ClickArchiveStore
+    hourSource   repository.ClickHourSource
+    hourCache    repository.ClickHourCache
+    coverage     repository.ClickCoverage
 }
 
-func buildRepositories(services *Services) *Repositories {
+// buildRepositories wires the repositories of the storage backend. The jobs
+// of a backend that does not need them (partitions, rollups, archiving and
+// the Redis cache) are left nil.
+func buildRepositories(cfg *config.Config, services *Services) *Repositories {
+    switch cfg.Storage.Backend {
+    case config.StorageMemory:
+        return buildMemoryRepositories()
+    case config.StorageEmbedded:
+        return buildEmbeddedRepositories(services.embedded)
+    }
+
     pgClick := postgres.NewClickRepository(services.db)
     pgStats := postgres.NewStatsRepository(services.db)
     redisClick := redis.NewClickRepository(services.redis)
     redisStats := redis.NewStatsRepository(services.redis)
+    pgTop := postgres.NewTopBannersRepository(services.db)
+    redisTop := redis.NewTopBannersRepository(services.redis)
+    pgUniques := postgres.NewUniqueClickRepository(services.db)
+    redisUniques := redis.NewUniqueClickRepository(services.redis)
+    coverage := repository.NewPendingGapCoverage(redis.NewClickCoverage(services.redis))
+
+    repos := &Repositories{
+        click:        repository.NewCompositeClickRepository(pgClick, redisClick, coverage),
+        stats:        repository.NewCompositeStatsRepository(pgStats, redisStats, coverage),
+        banner:       postgres.NewBannerRepository(services.db),
+        variant:      postgres.NewVariantRepository(services.db),
+        variantStats: postgres.NewVariantStatsRepository(services.db),
+        impression:   postgres.NewImpressionRepository(services.db),
+        tenant:       postgres.NewTenantRepository(services.db),
+        quota:        redis.NewQuotaRepository(services.redis),
+        totals:       postgres.NewBannerTotalsRepository(services.db),
+        revision:     postgres.NewBannerRevisionRepository(services.db),
+        top:          repository.NewCompositeTopBannersRepository(pgTop, redisTop, coverage),
+        feed:         redis.NewClickFeed(services.redis),
+        uniques:      repository.NewCompositeUniqueClickRepository(pgUniques, redisUniques, coverage),
+        export:       postgres.NewClickExportRepository(services.db),
+        statsCache:   redis.NewStatsCache(services.redis),
+        partition:    postgres.NewClickPartitionRepository(services.db),
+        rollup:       postgres.NewRollupRepository(services.db),
+        retention:    postgres.NewRetentionRepository(services.db),
+        archive:      postgres.NewClickArchiveRepository(services.db),
+        archiveStore: archive.NewLocalStore(cfg.Archive.Dir),
+        hourSource:   postgres.NewClickHourSource(services.db),
+        hourCache:    redis.NewClickHourCache(services.redis),
+        coverage:     coverage,
+    }
 
+    return repos
+}
+
+// buildEmbeddedRepositories keeps in the file everything that must survive
+// a restart. Quotas, the click feed and the stats cache stay in memory:
+// only one process ever has the file open.
+func buildEmbeddedRepositories(store *embedded.Store) *Repositories {
+    return &Repositories{
+        click:        embedded.NewClickRepository(store),
+        stats:        embedded.NewStatsRepository(store),
+        banner:       embedded.NewBannerRepository(store),
+        variant:      embedded.NewVariantRepository(store),
+        variantStats: embedded.NewVariantStatsRepository(store),
+        impression:   embedded.NewImpressionRepository(store),
+        tenant:       embedded.NewTenantRepository(store),
+        quota:        memory.NewQuotaRepository(),
+        totals:       embedded.NewBannerTotalsRepository(store),
+        revision:     embedded.NewBannerRevisionRepository(store),
+        top:          embedded.NewTopBannersRepository(store),
+        feed:         memory.NewClickFeed(),
+        uniques:      embedded.NewUniqueClickRepository(store),
+        export:       embedded.NewClickExportRepository(store),
+        statsCache:   memory.NewStatsCache(),
+        retention:    embedded.NewRetentionRepository(store),
+    }
+}
+
+func buildMemoryRepositories() *Repositories {
+    store := memory.NewStore()
     return &Repositories{
-        click: repository.NewCompositeClickRepository(pgClick, redisClick),
-        stats: repository.NewCompositeStatsRepository(pgStats, redisStats),
+        click:        memory.NewClickRepository(store),
+        stats:        memory.NewStatsRepository(store),
+        banner:       memory.NewBannerRepository(store),
+        variant:      memory.NewVariantRepository(store),
+        variantStats: memory.NewVariantStatsRepository(store),
+        impression:   memory.NewImpressionRepository(store),
+        tenant:       memory.NewTenantRepository(store),
+        quota:        memory.NewQuotaRepository(),
+        totals:       memory.NewBannerTotalsRepository(store),
+        revision:     memory.NewBannerRevisionRepository(store),
+        top:          memory.NewTopBannersRepository(store),
+        feed:         memory.NewClickFeed(),
+        uniques:      memory.NewUniqueClickRepository(store),
+        export:       memory.NewClickExportRepository(store),
+        statsCache:   memory.NewStatsCache(),
     }
 }
 
 type UseCases struct {
-    click usecase.ClickUseCase
-    stats usecase.StatsUseCase
+    click     usecase.ClickUseCase
+    stats     usecase.StatsUseCase
+    banner    usecase.BannerUseCase
+    serving   usecase.ServingUseCase
+    tenant    usecase.TenantUseCase
+    partition usecase.PartitionUseCase
+    rollup    usecase.RollupUseCase
+    retention usecase.RetentionUseCase
+    archive   usecase.ArchiveUseCase
+    reconcile usecase.ReconcileUseCase
+    rebuild   usecase.CacheRebuildUseCase
+    watch     *usecase.WatchTokens
+}
+
+// buildSigner returns the signer of tracking links and watch tokens.
+func buildSigner(cfg *config.Config) (*signed.Signer, error) {
+    if cfg.Auth.SigningKey != "" {
+        return signed.NewSigner([]byte(cfg.Auth.SigningKey)), nil
+    }
+
+    log.Printf("SIGNING_KEY is not set, click links and watch tokens will stop working on restart")
+    key := make([]byte, 32)
+    if _, err := rand.Read(key); err != nil {
+        return nil, fmt.Errorf("failed to generate signing key: %w", err)
+    }
+    return signed.NewSigner(key), nil
 }
 
-func buildUseCases(repos *Repositories) *UseCases {
-    return &UseCases{
-        click: usecase.NewClickUseCase(repos.click),
-        stats: usecase.NewStatsUseCase(repos.stats),
+func buildUseCases(cfg *config.Config, repos *Repositories, signer *signed.Signer) *UseCases {
+    links := usecase.NewClickLinks(signer, time.Duration(cfg.Serving.ClickLinkTTLHours)*time.Hour)
+    watch := usecase.NewWatchTokens(signer)
+    click := usecase.NewClickUseCase(repos.click, repos.impression, repos.banner, repos.variant, repos.tenant,
+        repos.quota, repos.totals, repos.feed, repos.uniques, repos.statsCache, links)
+
+    useCases := &UseCases{
+        click:   click,
+        stats:   usecase.NewStatsUseCase(repos.stats, repos.banner, repos.variantStats, repos.top, repos.feed,
+            repos.uniques, repos.export, repos.statsCache, watch),
+        banner:  usecase.NewBannerUseCase(repos.banner, repos.variant, repos.tenant, repos.revision),
+        serving: usecase.NewServingUseCase(repos.banner, repos.variant, click, links, cfg.Serving.PublicURL),
+        tenant:  usecase.NewTenantUseCase(repos.tenant),
+        watch:   watch,
+    }
+
+    // Задачи обслуживания есть не у всех хранилищ, без репозитория нет и
+    // задачи.
+    if repos.retention != nil {
+        useCases.retention = usecase.NewRetentionUseCase(repos.retention, usecase.RetentionPolicy{
+            Raw:              time.Duration(cfg.Retention.RawDays) * 24 * time.Hour,
+            HourlyMonths:     cfg.Retention.HourlyMonths,
+            DetachPartitions: cfg.Partition.Expire == "detach",
+        })
     }
+    if repos.partition != nil {
+        useCases.partition = usecase.NewPartitionUseCase(repos.partition, usecase.PartitionPolicy{
+            Interval: cfg.Partition.Interval,
+            Premake:  cfg.Partition.Premake,
+        })
+        useCases.rollup = usecase.NewRollupUseCase(repos.rollup)
+        useCases.archive = usecase.NewArchiveUseCase(repos.partition, repos.archive, repos.archiveStore,
+            repos.retention, usecase.ArchivePolicy{
+                After: time.Duration(cfg.Archive.AfterDays) * 24 * time.Hour,
+            })
+    }
+    if repos.hourCache != nil {
+        useCases.reconcile = usecase.NewReconcileUseCase(repos.hourSource, repos.hourCache, repos.coverage,
+            repos.statsCache)
+        useCases.rebuild = usecase.NewCacheRebuildUseCase(repos.hourSource, repos.hourCache, repos.coverage,
+            repos.statsCache)
+    }
+
+    return useCases
 }
 
 type Servers struct {
@@ -110,32 +346,60 @@ type Servers struct {
     grpc *grpc.Server
 }
 
-func buildServers(cfg *config.Config, h *handler.Handler) (*Servers, error) {
+func buildServers(cfg *config.Config, h *handler.Handler, useCases *UseCases) (*Servers, error) {
     gwmux, err := buildGatewayMux(cfg)
     if err != nil {
         return nil, fmt.Errorf("failed to build gateway mux: %w", err)
     }
 
+    auth := interceptor.NewAuthInterceptor(useCases.tenant, cfg.Auth.AdminToken)
+    watch := sse.NewStatsHandler(useCases.stats, useCases.tenant, useCases.watch)
+    clicks := redirect.NewClickHandler(useCases.click)
+
     return &Servers{
-        http: buildHTTPServer(cfg, gwmux),
-        grpc: buildGRPCServer(h),
+        http: buildHTTPServer(cfg, gwmux, watch, clicks),
+        grpc: buildGRPCServer(h, auth),
     }, nil
 }
 
-func buildHandlers(services *Services) *handler.Handler {
-    repos := buildRepositories(services)
-    useCases := buildUseCases(repos)
-    
+func buildHandlers(useCases *UseCases) *handler.Handler {
     clickHandler := handler.NewClickHandler(useCases.click)
     statsHandler := handler.NewStatsHandler(useCases.stats)
-    
-    return handler.NewHandler(clickHandler, statsHandler)
+    bannerHandler := handler.NewBannerHandler(useCases.banner)
+    servingHandler := handler.NewServingHandler(useCases.serving)
+    tenantHandler := handler.NewTenantHandler(useCases.tenant)
+    // Службы без задачи за ними не регистрируются и отвечают Unimplemented.
+    var retentionHandler handler.RetentionService
+    if useCases.retention != nil {
+        retentionHandler = handler.NewRetentionHandler(useCases.retention)
+    }
+    var cacheHandler handler.CacheService
+    if useCases.rebuild != nil {
+        cacheHandler = handler.NewCacheHandler(useCases.rebuild)
+    }
+
+    return handler.NewHandler(clickHandler, statsHandler, bannerHandler, servingHandler, tenantHandler,
+        retentionHandler, cacheHandler)
 }
 
 func (m *ServerManager) Run() error {
     ctx, cancel := context.WithCancel(context.Background())
     defer cancel()
 
+    if m.partitions != nil {
+        go m.partitions.Run(ctx, partitionMaintenanceInterval)
+        go m.rollups.Run(ctx, rollupInterval)
+        go m.archive.Run(ctx, archiveInterval)
+    }
+    if m.retention != nil {
+        go m.retention.Run(ctx, retentionInterval)
+    }
+    // Без Redis-кэша кликов сверять нечего.
+    if m.reconcile != nil {
+        go m.reconcile.Run(ctx, reconcileInterval)
+        go m.rebuild.Run(ctx, cacheWarmUpInterval)
+    }
+
     errChan := make(chan error, 2)
     go m.runHTTPServer(errChan)
     go m.runGRPCServer(errChan)
@@ -168,11 +432,21 @@ func (m *ServerManager) gracefulShutdown(ctx context.Context) error {
 
     m.grpcServer.GracefulStop()
     
-    if err := m.services.redis.Close(); err != nil {
-        errs = append(errs, fmt.Errorf("redis connection close error: %w", err))
+    if m.services.redis != nil {
+        if err := m.services.redis.Close(); err != nil {
+            errs = append(errs, fmt.Errorf("redis connection close error: %w", err))
+        }
+    }
+
+    if m.services.db != nil {
+        m.services.db.Close()
     }
 
-    m.services.db.Close()
+    if m.services.embedded != nil {
+        if err := m.services.embedded.Close(); err != nil {
+            errs = append(errs, fmt.Errorf("embedded storage close error: %w", err))
+        }
+    }
 
     log.Println("graceful shutdown completed")
     
@@ -213,15 +487,21 @@ func initRedis(cfg *config.Config) *goredis.Client {
     })
 }
 
-func buildGRPCServer(h *handler.Handler) *grpc.Server {
-    server := grpc.NewServer()
+func buildGRPCServer(h *handler.Handler, auth *interceptor.AuthInterceptor) *grpc.Server {
+    server := grpc.NewServer(
+        grpc.UnaryInterceptor(auth.Unary()),
+        grpc.StreamInterceptor(auth.Stream()),
+    )
     h.Register(server)
     return server
 }
 
-func buildHTTPServer(cfg *config.Config, gwmux *runtime.ServeMux) *http.Server {
+func buildHTTPServer(cfg *config.Config, gwmux *runtime.ServeMux, watch *sse.StatsHandler,
+    clicks *redirect.ClickHandler) *http.Server {
     router := mux.NewRouter()
     router.HandleFunc("/health", healthCheckHandler)
+    router.Handle("/stats/watch", watch).Methods(http.MethodGet)
+    router.Handle("/click/{token}", clicks).Methods(http.MethodGet)
     router.PathPrefix("/").Handler(gwmux)
 
     return &http.Server{
@@ -236,7 +516,14 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func buildGatewayMux(cfg *config.Config) (*runtime.ServeMux, error) {
-    gwmux := runtime.NewServeMux()
+    gwmux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &rawStreamMarshaler{
+        HTTPBodyMarshaler: runtime.HTTPBodyMarshaler{
+            Marshaler: &runtime.JSONPb{
+                MarshalOptions:   protojson.MarshalOptions{EmitUnpopulated: true},
+                UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+            },
+        },
+    }))
     opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
     
     if err := counter.RegisterCounterServiceHandlerFromEndpoint(context.Background(), 
@@ -249,9 +536,46 @@ func buildGatewayMux(cfg *config.Config) (*runtime.ServeMux, error) {
         return nil, fmt.Errorf("failed to register stats gateway: %w", err)
     }
 
+    if err := banner.RegisterBannerServiceHandlerFromEndpoint(context.Background(), 
+        gwmux, cfg.GetGrpcAddress(), opts); err != nil {
+        return nil, fmt.Errorf("failed to register banner gateway: %w", err)
+    }
+
+    if err := serving.RegisterServingServiceHandlerFromEndpoint(context.Background(), 
+        gwmux, cfg.GetGrpcAddress(), opts); err != nil {
+        return nil, fmt.Errorf("failed to register serving gateway: %w", err)
+    }
+
+    if err := tenantpb.RegisterTenantServiceHandlerFromEndpoint(context.Background(), 
+        gwmux, cfg.GetGrpcAddress(), opts); err != nil {
+        return nil, fmt.Errorf("failed to register tenant gateway: %w", err)
+    }
+
+    if err := retention.RegisterRetentionServiceHandlerFromEndpoint(context.Background(), 
+        gwmux, cfg.GetGrpcAddress(), opts); err != nil {
+        return nil, fmt.Errorf("failed to register retention gateway: %w", err)
+    }
+
+    if err := cache.RegisterCacheServiceHandlerFromEndpoint(context.Background(), 
+        gwmux, cfg.GetGrpcAddress(), opts); err != nil {
+        return nil, fmt.Errorf("failed to register cache gateway: %w", err)
+    }
+
     return gwmux, nil
 }
 
+// rawStreamMarshaler is the gateway's default marshaler, except that it
+// writes nothing between streamed messages. The default newline would end
+// up inside the CSV and protobuf bodies of ExportClicks, the only streaming
+// RPC routed through the gateway.
+type rawStreamMarshaler struct {
+    runtime.HTTPBodyMarshaler
+}
+
+func (m *rawStreamMarshaler) Delimiter() []byte {
+    return nil
+}
+
 func (m *ServerManager) runHTTPServer(errChan chan<- error) {
     log.Printf("Starting HTTP server on %s", m.httpServer.Addr)
     if err := m.httpServer.ListenAndServe(); err != http.ErrServerClosed {
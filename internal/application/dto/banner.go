@@ -0,0 +1,56 @@
+package dto
+
+import "clicker/internal/domain/entity"
+
+// UpdateBannerRequest changes the fields that are not nil and keeps the
+// rest of the banner as it is.
+type UpdateBannerRequest struct {
+    BannerID           int64
+    Name               *string
+    URL                *string
+    CreativeURL        *string
+    Placement          *string
+    Status             *string
+    Weight             *int
+    DailyImpressionCap *int
+}
+
+// Apply writes the set fields into banner.
+func (r *UpdateBannerRequest) Apply(banner *entity.Banner) {
+    setString(&banner.Name, r.Name)
+    setString(&banner.URL, r.URL)
+    setString(&banner.CreativeURL, r.CreativeURL)
+    setString(&banner.Placement, r.Placement)
+    setString(&banner.Status, r.Status)
+    setInt(&banner.Weight, r.Weight)
+    setInt(&banner.DailyImpressionCap, r.DailyImpressionCap)
+}
+
+// UpdateVariantRequest changes the fields that are not nil and keeps the
+// rest of the variant as it is.
+type UpdateVariantRequest struct {
+    BannerID    int64
+    VariantID   int64
+    Name        *string
+    CreativeURL *string
+    Weight      *int
+}
+
+// Apply writes the set fields into variant.
+func (r *UpdateVariantRequest) Apply(variant *entity.Variant) {
+    setString(&variant.Name, r.Name)
+    setString(&variant.CreativeURL, r.CreativeURL)
+    setInt(&variant.Weight, r.Weight)
+}
+
+func setString(dst *string, src *string) {
+    if src != nil {
+        *dst = *src
+    }
+}
+
+func setInt(dst *int, src *int) {
+    if src != nil {
+        *dst = *src
+    }
+}
@@ -1,9 +1,24 @@
 package dto
 
 type CounterRequest struct {
-    BannerID int64
+    BannerID  int64
+    VariantID int64
+    ClickerID string
+    Window    string
+    Timezone  string
+}
+
+// FollowRequest is a browser following a tracking link.
+type FollowRequest struct {
+    Token     string
+    ClickerID string
 }
 
 type CounterResponse struct {
     TotalClicks int64
 }
+
+type ImpressionRequest struct {
+    BannerID  int64
+    VariantID int64
+}
@@ -1,6 +1,11 @@
 package dto
 
 import (
+    "clicker/pkg/banner"
+    "clicker/pkg/cache"
+    "clicker/pkg/retention"
+    "clicker/pkg/serving"
+    "clicker/pkg/tenant"
     "clicker/pkg/stats"
     "clicker/pkg/counter"
     "clicker/internal/domain/entity"
@@ -11,7 +16,21 @@ func CounterRequestFromProto(req *counter.CounterRequest) *CounterRequest {
         return nil
     }
     return &CounterRequest{
-        BannerID: req.BannerId,
+        BannerID:  req.BannerId,
+        VariantID: req.VariantId,
+        ClickerID: req.ClickerId,
+        Window:    req.Window,
+        Timezone:  req.Timezone,
+    }
+}
+
+func ImpressionRequestFromProto(req *counter.ImpressionRequest) *ImpressionRequest {
+    if req == nil {
+        return nil
+    }
+    return &ImpressionRequest{
+        BannerID:  req.BannerId,
+        VariantID: req.VariantId,
     }
 }
 
@@ -29,19 +48,414 @@ func StatsRequestFromProto(req *stats.StatsRequest) *StatsRequest {
         return nil
     }
     return &StatsRequest{
-        BannerID: req.BannerId,
+        BannerID:    req.BannerId,
+        TsFrom:      req.TsFrom,
+        TsTo:        req.TsTo,
+        Granularity: req.Granularity,
+        Cumulative:  req.Cumulative,
+        Timezone:    req.Timezone,
+        Compare:     req.Compare,
+    }
+}
+
+func ToProtoResponse(resp *StatsResponse) *stats.StatsResponse {
+    if resp == nil {
+        return nil
+    }
+    out := &stats.StatsResponse{
+        TotalClicks:  resp.TotalClicks,
+        Series:       toStatsPointsProto(resp.Series),
+        UniqueClicks: resp.UniqueClicks,
+        Freshness:    toFreshnessProto(resp.Freshness),
+    }
+    if c := resp.Comparison; c != nil {
+        out.Comparison = &stats.StatsComparison{
+            Mode:         c.Mode,
+            TsFrom:       c.TsFrom,
+            TsTo:         c.TsTo,
+            TotalClicks:  c.TotalClicks,
+            Series:       toStatsPointsProto(c.Series),
+            Delta:        c.Delta,
+            DeltaPercent: c.DeltaPercent,
+            UniqueClicks: c.UniqueClicks,
+        }
+    }
+    return out
+}
+
+func toStatsPointsProto(points []*StatsPoint) []*stats.StatsPoint {
+    series := make([]*stats.StatsPoint, 0, len(points))
+    for _, p := range points {
+        series = append(series, &stats.StatsPoint{
+            BucketStart: p.BucketStart,
+            Clicks:      p.Clicks,
+        })
+    }
+    return series
+}
+
+func LabelStatsRequestFromProto(req *stats.LabelStatsRequest) *LabelStatsRequest {
+    if req == nil {
+        return nil
+    }
+    return &LabelStatsRequest{
+        Selector: req.Selector,
         TsFrom:   req.TsFrom,
         TsTo:     req.TsTo,
     }
 }
 
-func ToProtoResponse(resp *StatsResponse) *stats.StatsResponse {
+func ToLabelStatsProtoResponse(resp *LabelStatsResponse) *stats.LabelStatsResponse {
+    if resp == nil {
+        return nil
+    }
+    banners := make([]*stats.BannerClicks, 0, len(resp.Banners))
+    for _, b := range resp.Banners {
+        banners = append(banners, &stats.BannerClicks{
+            BannerId:    b.BannerID,
+            TotalClicks: b.TotalClicks,
+        })
+    }
+    return &stats.LabelStatsResponse{
+        TotalClicks: resp.TotalClicks,
+        Banners:     banners,
+        Freshness:   toFreshnessProto(resp.Freshness),
+    }
+}
+
+func BatchStatsRequestFromProto(req *stats.BatchStatsRequest) *BatchStatsRequest {
+    if req == nil {
+        return nil
+    }
+    return &BatchStatsRequest{
+        BannerIDs: req.BannerIds,
+        TsFrom:    req.TsFrom,
+        TsTo:      req.TsTo,
+    }
+}
+
+func ToBatchStatsProtoResponse(resp *BatchStatsResponse) *stats.BatchStatsResponse {
     if resp == nil {
         return nil
     }
-    return &stats.StatsResponse{
+    banners := make([]*stats.BannerClicks, 0, len(resp.Banners))
+    for _, b := range resp.Banners {
+        banners = append(banners, &stats.BannerClicks{
+            BannerId:    b.BannerID,
+            TotalClicks: b.TotalClicks,
+        })
+    }
+    return &stats.BatchStatsResponse{
         TotalClicks: resp.TotalClicks,
+        Banners:     banners,
+        Freshness:   toFreshnessProto(resp.Freshness),
+    }
+}
+
+func WatchStatsRequestFromProto(req *stats.WatchStatsRequest) *WatchStatsRequest {
+    if req == nil {
+        return nil
+    }
+    return &WatchStatsRequest{
+        BannerIDs: req.BannerIds,
+        TsFrom:    req.TsFrom,
+    }
+}
+
+func CreateWatchTokenRequestFromProto(req *stats.CreateWatchTokenRequest) *CreateWatchTokenRequest {
+    if req == nil {
+        return nil
+    }
+    return &CreateWatchTokenRequest{
+        BannerIDs: req.BannerIds,
+    }
+}
+
+func ToWatchTokenProto(token *WatchToken) *stats.WatchToken {
+    if token == nil {
+        return nil
+    }
+    return &stats.WatchToken{
+        Token:     token.Token,
+        ExpiresAt: token.ExpiresAt,
+    }
+}
+
+func ExportClicksRequestFromProto(req *stats.ExportClicksRequest) *ExportClicksRequest {
+    if req == nil {
+        return nil
+    }
+    return &ExportClicksRequest{
+        BannerIDs:   req.BannerIds,
+        TsFrom:      req.TsFrom,
+        TsTo:        req.TsTo,
+        Granularity: req.Granularity,
+        Timezone:    req.Timezone,
+        Source:      req.Source,
+    }
+}
+
+func ToExportRowProto(row *ExportRow) *stats.ExportRow {
+    if row == nil {
+        return nil
+    }
+    return &stats.ExportRow{
+        Id:          row.ID,
+        BannerId:    row.BannerID,
+        VariantId:   row.VariantID,
+        TimestampUs: row.Timestamp.UnixMicro(),
+        Clicks:      row.Clicks,
+    }
+}
+
+func ToStatsUpdateProto(update *StatsUpdate) *stats.StatsUpdate {
+    if update == nil {
+        return nil
+    }
+    banners := make([]*stats.BannerClicks, 0, len(update.Banners))
+    for _, b := range update.Banners {
+        banners = append(banners, &stats.BannerClicks{
+            BannerId:    b.BannerID,
+            TotalClicks: b.TotalClicks,
+        })
+    }
+    return &stats.StatsUpdate{
+        Snapshot: update.Snapshot,
+        Ts:       update.Ts,
+        Banners:  banners,
+    }
+}
+
+func TopBannersRequestFromProto(req *stats.TopBannersRequest) *TopBannersRequest {
+    if req == nil {
+        return nil
+    }
+    return &TopBannersRequest{
+        Window: req.Window,
+        TsFrom: req.TsFrom,
+        TsTo:   req.TsTo,
+        Limit:  int(req.Limit),
+    }
+}
+
+func ToTopBannersProtoResponse(resp *TopBannersResponse) *stats.TopBannersResponse {
+    if resp == nil {
+        return nil
+    }
+    banners := make([]*stats.BannerClicks, 0, len(resp.Banners))
+    for _, b := range resp.Banners {
+        banners = append(banners, &stats.BannerClicks{
+            BannerId:    b.BannerID,
+            TotalClicks: b.TotalClicks,
+        })
+    }
+    return &stats.TopBannersResponse{
+        TsFrom:    resp.TsFrom,
+        TsTo:      resp.TsTo,
+        Banners:   banners,
+        Freshness: toFreshnessProto(resp.Freshness),
+    }
+}
+
+func CompareVariantsRequestFromProto(req *stats.CompareVariantsRequest) *CompareVariantsRequest {
+    if req == nil {
+        return nil
+    }
+    return &CompareVariantsRequest{
+        BannerID:         req.BannerId,
+        TsFrom:           req.TsFrom,
+        TsTo:             req.TsTo,
+        ControlVariantID: req.ControlVariantId,
+        ConfidenceLevel:  req.ConfidenceLevel,
+    }
+}
+
+func ToCompareVariantsProtoResponse(resp *CompareVariantsResponse) *stats.CompareVariantsResponse {
+    if resp == nil {
+        return nil
+    }
+    variants := make([]*stats.VariantComparison, 0, len(resp.Variants))
+    for _, v := range resp.Variants {
+        variants = append(variants, &stats.VariantComparison{
+            VariantId:   v.VariantID,
+            Name:        v.Name,
+            Weight:      int32(v.Weight),
+            Impressions: v.Impressions,
+            Clicks:      v.Clicks,
+            Ctr:         v.CTR,
+            CtrLower:    v.CTRLower,
+            CtrUpper:    v.CTRUpper,
+            Lift:        v.Lift,
+            ZScore:      v.ZScore,
+            PValue:      v.PValue,
+            Significant: v.Significant,
+        })
+    }
+    return &stats.CompareVariantsResponse{
+        ControlVariantId: resp.ControlVariantID,
+        ConfidenceLevel:  resp.ConfidenceLevel,
+        Variants:         variants,
+    }
+}
+
+func ToBannerProto(b *entity.Banner) *banner.Banner {
+    if b == nil {
+        return nil
+    }
+    return &banner.Banner{
+        Id:                 b.ID,
+        Name:               b.Name,
+        Labels:             b.Labels,
+        Url:                b.URL,
+        CreativeUrl:        b.CreativeURL,
+        Placement:          b.Placement,
+        Status:             b.Status,
+        Weight:             int32(b.Weight),
+        DailyImpressionCap: int32(b.DailyImpressionCap),
+    }
+}
+
+func ToBannerRevisionProto(r *entity.BannerRevision) *banner.BannerRevision {
+    if r == nil {
+        return nil
+    }
+    changes := make([]*banner.FieldChange, 0, len(r.Changes))
+    for _, c := range r.Changes {
+        changes = append(changes, &banner.FieldChange{
+            Field:    c.Field,
+            OldValue: c.Old,
+            NewValue: c.New,
+        })
+    }
+    return &banner.BannerRevision{
+        Id:        r.ID,
+        BannerId:  r.BannerID,
+        VariantId: r.VariantID,
+        Action:    r.Action,
+        Actor:     r.Actor,
+        Changes:   changes,
+        CreatedAt: r.CreatedAt.Unix(),
+    }
+}
+
+func ToServeBannerProtoResponse(b *ServedBanner) *serving.ServeBannerResponse {
+    if b == nil {
+        return nil
+    }
+    return &serving.ServeBannerResponse{
+        BannerId:    b.BannerID,
+        VariantId:   b.VariantID,
+        Name:        b.Name,
+        CreativeUrl: b.CreativeURL,
+        Url:         b.URL,
+        ClickUrl:    b.ClickURL,
+    }
+}
+
+func ToVariantProto(v *entity.Variant) *banner.Variant {
+    if v == nil {
+        return nil
+    }
+    return &banner.Variant{
+        Id:          v.ID,
+        BannerId:    v.BannerID,
+        Name:        v.Name,
+        CreativeUrl: v.CreativeURL,
+        Weight:      int32(v.Weight),
+    }
+}
+
+func ToTenantProto(t *entity.Tenant) *tenant.Tenant {
+    if t == nil {
+        return nil
+    }
+    return &tenant.Tenant{
+        Id:             t.ID,
+        Name:           t.Name,
+        MaxBanners:     int32(t.MaxBanners),
+        MaxDailyClicks: t.MaxDailyClicks,
+        CreatedAt:      t.CreatedAt.Unix(),
+    }
+}
+
+func ToRetentionRunProto(run *entity.RetentionRun) *retention.RetentionRun {
+    if run == nil {
+        return nil
+    }
+    return &retention.RetentionRun{
+        Id:         run.ID,
+        StartedAt:  run.StartedAt.Unix(),
+        FinishedAt: run.FinishedAt.Unix(),
+        Raw:        toTierExpiryProto(run.Raw),
+        Hourly:     toTierExpiryProto(run.Hourly),
+        Error:      run.Error,
+    }
+}
+
+func toTierExpiryProto(expiry *entity.TierExpiry) *retention.TierExpiry {
+    if expiry == nil {
+        return nil
+    }
+    return &retention.TierExpiry{
+        Horizon:           expiry.Horizon.Unix(),
+        Deleted:           expiry.Deleted,
+        PartitionsDropped: int32(expiry.PartitionsDropped),
+    }
+}
+
+func ToCacheRebuildProto(rebuild *entity.CacheRebuild) *cache.CacheRebuild {
+    resp := &cache.CacheRebuild{
+        State:     rebuild.State,
+        Hours:     rebuild.Hours,
+        HoursDone: rebuild.HoursDone,
+        Error:     rebuild.Error,
+    }
+    // Пустые времена отдаём нулями, а не отрицательными секундами.
+    if !rebuild.StartedAt.IsZero() {
+        resp.StartedAt = rebuild.StartedAt.Unix()
+        resp.From = rebuild.From.Unix()
+        resp.To = rebuild.To.Unix()
+    }
+    if !rebuild.FinishedAt.IsZero() {
+        resp.FinishedAt = rebuild.FinishedAt.Unix()
+    }
+    return resp
+}
+
+func FreshnessFromEntity(f *entity.Freshness) *Freshness {
+    if f == nil {
+        return nil
+    }
+    out := &Freshness{
+        AsOf:    f.AsOf.Unix(),
+        Sources: make([]*DataSource, 0, len(f.Sources)),
+    }
+    for _, s := range f.Sources {
+        out.Sources = append(out.Sources, &DataSource{
+            Tier:   s.Tier,
+            TsFrom: s.From.Unix(),
+            TsTo:   s.To.Unix(),
+        })
+    }
+    return out
+}
+
+func toFreshnessProto(f *Freshness) *stats.Freshness {
+    if f == nil {
+        return nil
+    }
+    out := &stats.Freshness{
+        AsOf:    f.AsOf,
+        Sources: make([]*stats.DataSource, 0, len(f.Sources)),
+    }
+    for _, s := range f.Sources {
+        out.Sources = append(out.Sources, &stats.DataSource{
+            Tier:   s.Tier,
+            TsFrom: s.TsFrom,
+            TsTo:   s.TsTo,
+        })
     }
+    return out
 }
 
 func TotalClicksFromEntity(clicks []*entity.Click) int64 {
@@ -0,0 +1,10 @@
+package dto
+
+type ServedBanner struct {
+    BannerID    int64  `json:"banner_id"`
+    VariantID   int64  `json:"variant_id"`
+    Name        string `json:"name"`
+    CreativeURL string `json:"creative_url"`
+    URL         string `json:"url"`
+    ClickURL    string `json:"click_url"`
+}
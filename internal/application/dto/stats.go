@@ -1,11 +1,166 @@
 package dto
 
+import "time"
+
 type StatsRequest struct {
-    BannerID int64
+    BannerID    int64
+    TsFrom      int64
+    TsTo        int64
+    Granularity string
+    Cumulative  bool
+    Timezone    string
+    Compare     string
+}
+
+type StatsPoint struct {
+    BucketStart int64 `json:"bucket_start"`
+    Clicks      int64 `json:"clicks"`
+}
+
+// StatsComparison is the compared period and how the requested one
+// differs from it. DeltaPercent is 0 when the compared period had no clicks.
+type StatsComparison struct {
+    Mode         string        `json:"mode"`
+    TsFrom       int64         `json:"ts_from"`
+    TsTo         int64         `json:"ts_to"`
+    TotalClicks  int64         `json:"total_clicks"`
+    Series       []*StatsPoint `json:"series,omitempty"`
+    Delta        int64         `json:"delta"`
+    DeltaPercent float64       `json:"delta_percent"`
+    UniqueClicks int64         `json:"unique_clicks"`
+}
+
+type StatsResponse struct {
+    TotalClicks  int64            `json:"total_clicks"`
+    Series       []*StatsPoint    `json:"series,omitempty"`
+    Comparison   *StatsComparison `json:"comparison,omitempty"`
+    UniqueClicks int64            `json:"unique_clicks"`
+    Freshness    *Freshness       `json:"freshness,omitempty"`
+}
+
+// DataSource is a part of the queried range answered by one tier.
+type DataSource struct {
+    Tier   string `json:"tier"`
+    TsFrom int64  `json:"ts_from"`
+    TsTo   int64  `json:"ts_to"`
+}
+
+// Freshness is when the numbers were read and where from. It is cached
+// along with the response, so a cached answer shows its age.
+type Freshness struct {
+    AsOf    int64         `json:"as_of"`
+    Sources []*DataSource `json:"sources,omitempty"`
+}
+
+type LabelStatsRequest struct {
+    Selector map[string]string
     TsFrom   int64
     TsTo     int64
 }
 
-type StatsResponse struct {
+type BannerClicks struct {
+    BannerID    int64 `json:"banner_id"`
     TotalClicks int64 `json:"total_clicks"`
 }
+
+type LabelStatsResponse struct {
+    TotalClicks int64           `json:"total_clicks"`
+    Banners     []*BannerClicks `json:"banners"`
+    Freshness   *Freshness      `json:"freshness,omitempty"`
+}
+
+type BatchStatsRequest struct {
+    BannerIDs []int64
+    TsFrom    int64
+    TsTo      int64
+}
+
+type BatchStatsResponse struct {
+    TotalClicks int64           `json:"total_clicks"`
+    Banners     []*BannerClicks `json:"banners"`
+    Freshness   *Freshness      `json:"freshness,omitempty"`
+}
+
+type WatchStatsRequest struct {
+    BannerIDs []int64
+    TsFrom    int64
+}
+
+type CreateWatchTokenRequest struct {
+    BannerIDs []int64
+}
+
+type WatchToken struct {
+    Token     string
+    ExpiresAt int64
+}
+
+// StatsUpdate carries running totals. The first one is a snapshot of all
+// watched banners, later ones only list banners that got new clicks.
+type StatsUpdate struct {
+    Snapshot bool            `json:"snapshot"`
+    Ts       int64           `json:"ts"`
+    Banners  []*BannerClicks `json:"banners"`
+}
+
+type ExportClicksRequest struct {
+    BannerIDs   []int64
+    TsFrom      int64
+    TsTo        int64
+    Granularity string
+    Timezone    string
+    Source      string
+}
+
+// ExportRow is a stored click row, or the clicks of a banner in one bucket
+// when exporting with a granularity, in which case ID and VariantID are 0.
+type ExportRow struct {
+    ID        int64     `json:"id,omitempty"`
+    BannerID  int64     `json:"banner_id"`
+    VariantID int64     `json:"variant_id,omitempty"`
+    Timestamp time.Time `json:"timestamp"`
+    Clicks    int64     `json:"clicks"`
+}
+
+type TopBannersRequest struct {
+    Window string
+    TsFrom int64
+    TsTo   int64
+    Limit  int
+}
+
+type TopBannersResponse struct {
+    TsFrom    int64           `json:"ts_from"`
+    TsTo      int64           `json:"ts_to"`
+    Banners   []*BannerClicks `json:"banners"`
+    Freshness *Freshness      `json:"freshness,omitempty"`
+}
+
+type CompareVariantsRequest struct {
+    BannerID         int64
+    TsFrom           int64
+    TsTo             int64
+    ControlVariantID int64
+    ConfidenceLevel  float64
+}
+
+type VariantComparison struct {
+    VariantID   int64   `json:"variant_id"`
+    Name        string  `json:"name"`
+    Weight      int     `json:"weight"`
+    Impressions int64   `json:"impressions"`
+    Clicks      int64   `json:"clicks"`
+    CTR         float64 `json:"ctr"`
+    CTRLower    float64 `json:"ctr_lower"`
+    CTRUpper    float64 `json:"ctr_upper"`
+    Lift        float64 `json:"lift"`
+    ZScore      float64 `json:"z_score"`
+    PValue      float64 `json:"p_value"`
+    Significant bool    `json:"significant"`
+}
+
+type CompareVariantsResponse struct {
+    ControlVariantID int64                `json:"control_variant_id"`
+    ConfidenceLevel  float64              `json:"confidence_level"`
+    Variants         []*VariantComparison `json:"variants"`
+}
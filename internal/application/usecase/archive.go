@@ -0,0 +1,188 @@
+package usecase
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+)
+
+// restoreBatchSize is how many clicks are copied into Postgres at once.
+const restoreBatchSize = 5000
+
+// ArchivePolicy says when clicks partitions leave the database.
+type ArchivePolicy struct {
+    // After is how long a partition is kept after its range ends, 0 turns
+    // the archival job off.
+    After time.Duration
+}
+
+type ArchiveUseCase interface {
+    // Archive moves partitions ending before the given time to the archive
+    // store, oldest first, and drops them. A partition is dropped only once
+    // it is rolled up, until then it stays archived and attached.
+    Archive(ctx context.Context, before time.Time) ([]*entity.ClickArchive, error)
+    // Restore loads archived clicks in [from, to) into restored_clicks and
+    // returns how many it loaded. They are read back through ExportClicks
+    // with source restored.
+    Restore(ctx context.Context, from, to time.Time) (int64, error)
+    // Run archives per the policy right away and then every interval,
+    // until ctx is done. It does nothing when archival is off.
+    Run(ctx context.Context, interval time.Duration)
+}
+
+type archiveUseCase struct {
+    partitions repository.ClickPartitionRepository
+    archives   repository.ClickArchiveRepository
+    store      repository.ClickArchiveStore
+    retention  repository.RetentionRepository
+    policy     ArchivePolicy
+}
+
+func NewArchiveUseCase(partitions repository.ClickPartitionRepository, archives repository.ClickArchiveRepository,
+    store repository.ClickArchiveStore, retention repository.RetentionRepository, policy ArchivePolicy) ArchiveUseCase {
+    return &archiveUseCase{
+        partitions: partitions,
+        archives:   archives,
+        store:      store,
+        retention:  retention,
+        policy:     policy,
+    }
+}
+
+func (uc *archiveUseCase) Run(ctx context.Context, interval time.Duration) {
+    if uc.policy.After <= 0 {
+        return
+    }
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if _, err := uc.Archive(ctx, time.Now().Add(-uc.policy.After)); err != nil {
+            log.Printf("Failed to archive clicks: %v", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (uc *archiveUseCase) Archive(ctx context.Context, before time.Time) ([]*entity.ClickArchive, error) {
+    // Архивация удаляет сырые клики, поэтому делит блокировку с retention.
+    unlock, ok, err := uc.retention.TryLock(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock retention: %w", err)
+    }
+    if !ok {
+        log.Printf("Clicks are being expired by another replica")
+        return nil, nil
+    }
+    defer unlock()
+
+    partitions, err := uc.partitions.List(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list partitions: %w", err)
+    }
+    existing, err := uc.store.List(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read archive manifest: %w", err)
+    }
+    archived := make(map[string]bool, len(existing))
+    for _, a := range existing {
+        archived[a.Partition] = true
+    }
+
+    var written []*entity.ClickArchive
+    for _, p := range partitions {
+        if p.To.After(before) {
+            break
+        }
+
+        // Уже заархивированную секцию не переписываем: retention могла
+        // успеть удалить из неё часть строк.
+        if !archived[p.Name] {
+            a, err := uc.store.Write(ctx, p, func(fn func(*entity.Click) error) error {
+                return uc.archives.ReadPartition(ctx, p.Name, fn)
+            })
+            if err != nil {
+                return written, fmt.Errorf("failed to archive partition %s: %w", p.Name, err)
+            }
+            log.Printf("Archived clicks partition %s: %d rows, %d bytes", p.Name, a.Rows, a.Bytes)
+            written = append(written, a)
+        }
+
+        // Секция уже в архиве, отсоединять её и хранить копию незачем.
+        expiry, err := uc.retention.ExpireRaw(ctx, p.To, false)
+        if err != nil {
+            return written, fmt.Errorf("failed to drop archived partition %s: %w", p.Name, err)
+        }
+        if expiry.Horizon.Before(p.To) {
+            log.Printf("Keeping archived partition %s until it is rolled up", p.Name)
+            break
+        }
+    }
+
+    return written, nil
+}
+
+func (uc *archiveUseCase) Restore(ctx context.Context, from, to time.Time) (int64, error) {
+    if !from.Before(to) {
+        return 0, fmt.Errorf("%w: from must be before to", ErrInvalidArgument)
+    }
+
+    archives, err := uc.store.List(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to read archive manifest: %w", err)
+    }
+
+    if err := uc.archives.ClearRestored(ctx, from, to); err != nil {
+        return 0, fmt.Errorf("failed to clear restored clicks: %w", err)
+    }
+
+    var restored int64
+    batch := make([]*entity.Click, 0, restoreBatchSize)
+    flush := func() error {
+        if len(batch) == 0 {
+            return nil
+        }
+        if err := uc.archives.SaveRestored(ctx, batch); err != nil {
+            return fmt.Errorf("failed to save restored clicks: %w", err)
+        }
+        restored += int64(len(batch))
+        batch = batch[:0]
+        return nil
+    }
+
+    for _, a := range archives {
+        if !a.From.Before(to) || !from.Before(a.To) {
+            continue
+        }
+
+        err := uc.store.Read(ctx, a, func(click *entity.Click) error {
+            if click.Timestamp.Before(from) || !click.Timestamp.Before(to) {
+                return nil
+            }
+            batch = append(batch, click)
+            if len(batch) == restoreBatchSize {
+                return flush()
+            }
+            return nil
+        })
+        if err != nil {
+            return restored, fmt.Errorf("failed to restore %s: %w", a.Partition, err)
+        }
+        log.Printf("Restored clicks from %s", a.File)
+    }
+
+    if err := flush(); err != nil {
+        return restored, err
+    }
+    return restored, nil
+}
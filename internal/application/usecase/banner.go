@@ -0,0 +1,236 @@
+package usecase
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "regexp"
+
+    "clicker/internal/application/dto"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+var ErrInvalidArgument = errors.New("invalid argument")
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,62}$`)
+
+const maxLabelValueLength = 255
+
+type BannerUseCase interface {
+    CreateBanner(ctx context.Context, banner *entity.Banner) (*entity.Banner, error)
+    UpdateBanner(ctx context.Context, req *dto.UpdateBannerRequest) (*entity.Banner, error)
+    GetBanner(ctx context.Context, bannerID int64) (*entity.Banner, error)
+    DeleteBanner(ctx context.Context, bannerID int64) error
+    GetBannerHistory(ctx context.Context, bannerID int64) ([]*entity.BannerRevision, error)
+    SetLabels(ctx context.Context, bannerID int64, labels map[string]string) (*entity.Banner, error)
+    CreateVariant(ctx context.Context, variant *entity.Variant) (*entity.Variant, error)
+    UpdateVariant(ctx context.Context, req *dto.UpdateVariantRequest) (*entity.Variant, error)
+    ListVariants(ctx context.Context, bannerID int64) ([]*entity.Variant, error)
+}
+
+type bannerUseCase struct {
+    repo      repository.BannerRepository
+    variants  repository.VariantRepository
+    tenants   repository.TenantRepository
+    revisions repository.BannerRevisionRepository
+}
+
+func NewBannerUseCase(repo repository.BannerRepository, variants repository.VariantRepository,
+    tenants repository.TenantRepository, revisions repository.BannerRevisionRepository) BannerUseCase {
+    return &bannerUseCase{
+        repo:      repo,
+        variants:  variants,
+        tenants:   tenants,
+        revisions: revisions,
+    }
+}
+
+func (uc *bannerUseCase) CreateBanner(ctx context.Context, banner *entity.Banner) (*entity.Banner, error) {
+    if banner.Weight == 0 {
+        banner.Weight = 1
+    }
+    if err := validateBanner(banner); err != nil {
+        return nil, err
+    }
+    if err := validateLabels(banner.Labels); err != nil {
+        return nil, err
+    }
+    if err := uc.checkBannerQuota(ctx); err != nil {
+        return nil, err
+    }
+
+    if err := uc.repo.Create(ctx, banner); err != nil {
+        return nil, err
+    }
+
+    if len(banner.Labels) > 0 {
+        if err := uc.repo.SetLabels(ctx, banner.ID, banner.Labels); err != nil {
+            return nil, err
+        }
+    }
+
+    return uc.repo.GetByID(ctx, banner.ID)
+}
+
+// UpdateBanner changes only the fields set in req. Two concurrent updates of
+// different fields may still overwrite each other.
+func (uc *bannerUseCase) UpdateBanner(ctx context.Context, req *dto.UpdateBannerRequest) (*entity.Banner, error) {
+    banner, err := uc.repo.GetByID(ctx, req.BannerID)
+    if err != nil {
+        return nil, err
+    }
+    req.Apply(banner)
+
+    if err := validateBanner(banner); err != nil {
+        return nil, err
+    }
+
+    if err := uc.repo.Update(ctx, banner); err != nil {
+        return nil, err
+    }
+
+    return uc.repo.GetByID(ctx, banner.ID)
+}
+
+func (uc *bannerUseCase) GetBanner(ctx context.Context, bannerID int64) (*entity.Banner, error) {
+    return uc.repo.GetByID(ctx, bannerID)
+}
+
+func (uc *bannerUseCase) DeleteBanner(ctx context.Context, bannerID int64) error {
+    return uc.repo.Delete(ctx, bannerID)
+}
+
+// GetBannerHistory also works for deleted banners. Every banner has at least
+// its create revision, so an empty history means the banner does not exist.
+func (uc *bannerUseCase) GetBannerHistory(ctx context.Context, bannerID int64) ([]*entity.BannerRevision, error) {
+    revisions, err := uc.revisions.ListByBanner(ctx, bannerID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list banner revisions: %w", err)
+    }
+    if len(revisions) == 0 {
+        return nil, repository.ErrBannerNotFound
+    }
+
+    return revisions, nil
+}
+
+func (uc *bannerUseCase) SetLabels(ctx context.Context, bannerID int64, labels map[string]string) (*entity.Banner, error) {
+    if err := validateLabels(labels); err != nil {
+        return nil, err
+    }
+
+    if err := uc.repo.SetLabels(ctx, bannerID, labels); err != nil {
+        return nil, err
+    }
+
+    return uc.repo.GetByID(ctx, bannerID)
+}
+
+func (uc *bannerUseCase) CreateVariant(ctx context.Context, variant *entity.Variant) (*entity.Variant, error) {
+    if variant.Weight == 0 {
+        variant.Weight = 1
+    }
+    if err := validateVariant(variant); err != nil {
+        return nil, err
+    }
+
+    if err := uc.variants.Create(ctx, variant); err != nil {
+        return nil, err
+    }
+
+    return variant, nil
+}
+
+// UpdateVariant changes only the fields set in req.
+func (uc *bannerUseCase) UpdateVariant(ctx context.Context, req *dto.UpdateVariantRequest) (*entity.Variant, error) {
+    variant, err := uc.variants.GetByID(ctx, req.VariantID)
+    if err != nil {
+        return nil, err
+    }
+    if variant.BannerID != req.BannerID {
+        return nil, repository.ErrVariantNotFound
+    }
+    req.Apply(variant)
+
+    if err := validateVariant(variant); err != nil {
+        return nil, err
+    }
+
+    if err := uc.variants.Update(ctx, variant); err != nil {
+        return nil, err
+    }
+
+    return variant, nil
+}
+
+func (uc *bannerUseCase) ListVariants(ctx context.Context, bannerID int64) ([]*entity.Variant, error) {
+    if _, err := uc.repo.GetByID(ctx, bannerID); err != nil {
+        return nil, err
+    }
+
+    return uc.variants.ListByBanner(ctx, bannerID)
+}
+
+func (uc *bannerUseCase) checkBannerQuota(ctx context.Context) error {
+    t, err := tenant.FromContext(ctx)
+    if err != nil {
+        return err
+    }
+    if t.MaxBanners == 0 {
+        return nil
+    }
+
+    count, err := uc.tenants.CountBanners(ctx, t.ID)
+    if err != nil {
+        return fmt.Errorf("failed to count banners: %w", err)
+    }
+    if count >= t.MaxBanners {
+        return fmt.Errorf("%w: tenant %d may have at most %d banners", ErrQuotaExceeded, t.ID, t.MaxBanners)
+    }
+
+    return nil
+}
+
+func validateBanner(banner *entity.Banner) error {
+    if banner.Name == "" {
+        return fmt.Errorf("%w: banner name is required", ErrInvalidArgument)
+    }
+    if banner.Status == "" {
+        banner.Status = entity.BannerStatusActive
+    }
+    if banner.Status != entity.BannerStatusActive && banner.Status != entity.BannerStatusPaused {
+        return fmt.Errorf("%w: unknown banner status %q", ErrInvalidArgument, banner.Status)
+    }
+    // Вес 0 никогда не выпадет при выборе, для этого есть пауза.
+    if banner.Weight <= 0 {
+        return fmt.Errorf("%w: banner weight must be positive", ErrInvalidArgument)
+    }
+    if banner.DailyImpressionCap < 0 {
+        return fmt.Errorf("%w: daily impression cap must not be negative", ErrInvalidArgument)
+    }
+    return nil
+}
+
+func validateVariant(variant *entity.Variant) error {
+    if variant.Name == "" {
+        return fmt.Errorf("%w: variant name is required", ErrInvalidArgument)
+    }
+    if variant.Weight <= 0 {
+        return fmt.Errorf("%w: variant weight must be positive", ErrInvalidArgument)
+    }
+    return nil
+}
+
+func validateLabels(labels map[string]string) error {
+    for key, value := range labels {
+        if !labelKeyPattern.MatchString(key) {
+            return fmt.Errorf("%w: invalid label key %q", ErrInvalidArgument, key)
+        }
+        if len(value) > maxLabelValueLength {
+            return fmt.Errorf("%w: label %q value is longer than %d", ErrInvalidArgument, key, maxLabelValueLength)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+)
+
+const (
+    // rebuildWindow is the recent range served from Redis. Together with
+    // the current hour it covers every hour key still alive.
+    rebuildWindow = 24 * time.Hour
+    // rebuildProgressEvery is how many banner hours are rebuilt between
+    // progress updates.
+    rebuildProgressEvery = 100
+)
+
+type CacheRebuildUseCase interface {
+    // Start rebuilds the recent clicks in Redis from Postgres in the
+    // background and returns the progress right away. If a rebuild is
+    // running already, its progress is returned instead.
+    Start(ctx context.Context) (*entity.CacheRebuild, error)
+    // Rebuild is Start that waits for the rebuild to finish. It returns nil
+    // when another replica is rebuilding at the moment.
+    Rebuild(ctx context.Context) (*entity.CacheRebuild, error)
+    // Progress never returns nil, the state is empty when there has been
+    // no rebuild since Redis was emptied.
+    Progress(ctx context.Context) (*entity.CacheRebuild, error)
+    // Run starts a rebuild whenever the latest one is not done, which is
+    // the case on first start, after a Redis flush or a failover to an
+    // empty replica. It checks right away and then every interval, until
+    // ctx is done.
+    Run(ctx context.Context, interval time.Duration)
+}
+
+type cacheRebuildUseCase struct {
+    source     repository.ClickHourSource
+    cache      repository.ClickHourCache
+    coverage   repository.ClickCoverage
+    statsCache repository.StatsCache
+}
+
+func NewCacheRebuildUseCase(source repository.ClickHourSource, cache repository.ClickHourCache,
+    coverage repository.ClickCoverage, statsCache repository.StatsCache) CacheRebuildUseCase {
+    return &cacheRebuildUseCase{
+        source:     source,
+        cache:      cache,
+        coverage:   coverage,
+        statsCache: statsCache,
+    }
+}
+
+func (uc *cacheRebuildUseCase) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        uc.warmUp(ctx)
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (uc *cacheRebuildUseCase) warmUp(ctx context.Context) {
+    rebuild, err := uc.Progress(ctx)
+    if err != nil {
+        log.Printf("Failed to check Redis cache rebuild: %v", err)
+        return
+    }
+    if rebuild.State == entity.CacheRebuildDone {
+        return
+    }
+
+    // Если пересборка уже идёт, Start просто вернёт её прогресс.
+    if rebuild.State != entity.CacheRebuildRunning {
+        log.Printf("Redis cache is not rebuilt (state %q), warming it up", rebuild.State)
+    }
+    if _, err := uc.Start(ctx); err != nil {
+        log.Printf("Failed to start Redis cache rebuild: %v", err)
+    }
+}
+
+func (uc *cacheRebuildUseCase) Start(ctx context.Context) (*entity.CacheRebuild, error) {
+    // Пересборка переживает запрос, который её запустил.
+    ctx = context.WithoutCancel(ctx)
+
+    rebuild, token, unlock, err := uc.begin(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if rebuild == nil {
+        return uc.Progress(ctx)
+    }
+
+    go func() {
+        defer unlock()
+        if err := uc.run(ctx, rebuild, token); err != nil {
+            log.Printf("Failed to rebuild Redis cache: %v", err)
+        }
+    }()
+    return rebuild, nil
+}
+
+func (uc *cacheRebuildUseCase) Rebuild(ctx context.Context) (*entity.CacheRebuild, error) {
+    rebuild, token, unlock, err := uc.begin(ctx)
+    if err != nil || rebuild == nil {
+        return nil, err
+    }
+    defer unlock()
+
+    return rebuild, uc.run(ctx, rebuild, token)
+}
+
+func (uc *cacheRebuildUseCase) Progress(ctx context.Context) (*entity.CacheRebuild, error) {
+    rebuild, err := uc.cache.Rebuild(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get cache rebuild: %w", err)
+    }
+    if rebuild == nil {
+        rebuild = &entity.CacheRebuild{}
+    }
+    return rebuild, nil
+}
+
+// begin takes the reconciliation lock, so a rebuild and a reconciliation
+// never rewrite the same hours at once, and claims the Redis coverage. It
+// returns a nil rebuild when the lock is taken.
+func (uc *cacheRebuildUseCase) begin(ctx context.Context) (*entity.CacheRebuild, int64, func(), error) {
+    unlock, ok, err := uc.source.TryLock(ctx)
+    if err != nil {
+        return nil, 0, nil, fmt.Errorf("failed to lock cache rebuild: %w", err)
+    }
+    if !ok {
+        log.Printf("Redis cache is being rebuilt or reconciled by another replica")
+        return nil, 0, nil, nil
+    }
+
+    token, err := uc.coverage.Claim(ctx)
+    if err != nil {
+        unlock()
+        return nil, 0, nil, fmt.Errorf("failed to claim cache coverage: %w", err)
+    }
+
+    now := time.Now().UTC()
+    rebuild := &entity.CacheRebuild{
+        State:     entity.CacheRebuildRunning,
+        StartedAt: now,
+        From:      now.Add(-rebuildWindow).Truncate(time.Hour),
+        To:        now.Truncate(time.Hour).Add(time.Hour),
+    }
+    if err := uc.cache.SaveRebuild(ctx, rebuild); err != nil {
+        unlock()
+        return nil, 0, nil, fmt.Errorf("failed to save cache rebuild: %w", err)
+    }
+    return rebuild, token, unlock, nil
+}
+
+func (uc *cacheRebuildUseCase) run(ctx context.Context, rebuild *entity.CacheRebuild, token int64) error {
+    err := uc.rebuild(ctx, rebuild)
+    if err == nil {
+        uc.restoreCoverage(ctx, rebuild, token)
+    }
+    rebuild.FinishedAt = time.Now().UTC()
+    rebuild.State = entity.CacheRebuildDone
+    if err != nil {
+        rebuild.State = entity.CacheRebuildFailed
+        rebuild.Error = err.Error()
+    }
+
+    if saveErr := uc.cache.SaveRebuild(context.WithoutCancel(ctx), rebuild); saveErr != nil {
+        log.Printf("Failed to save cache rebuild: %v", saveErr)
+    }
+    if err != nil {
+        return err
+    }
+
+    log.Printf("Rebuilt Redis cache from %v to %v: %d banner hours", rebuild.From, rebuild.To, rebuild.HoursDone)
+    return nil
+}
+
+// restoreCoverage lets reads use the rebuilt hours, unless Redis missed
+// clicks or was emptied while they were written.
+func (uc *cacheRebuildUseCase) restoreCoverage(ctx context.Context, rebuild *entity.CacheRebuild, token int64) {
+    restored, err := uc.coverage.Restore(ctx, token, rebuild.From, rebuild.To)
+    if err != nil {
+        log.Printf("Failed to restore Redis coverage: %v", err)
+        return
+    }
+    if !restored {
+        log.Printf("Redis missed clicks during the rebuild, its coverage stays as it is")
+    }
+}
+
+// rebuild merges every banner hour with clicks into Redis, newest first,
+// because recent hours are what counters and leaderboards read most. Hours
+// are merged rather than replaced, so clicks and clickers Redis counts
+// meanwhile, the current hour's above all, are kept. Each second takes the
+// larger of the two counts, so a second with both older clicks from
+// Postgres and clicks Redis counted after the read comes out short; the
+// next reconciliation repairs that after the hour ends.
+func (uc *cacheRebuildUseCase) rebuild(ctx context.Context, rebuild *entity.CacheRebuild) error {
+    hours, err := uc.source.HourCounts(ctx, rebuild.From, rebuild.To)
+    if err != nil {
+        return fmt.Errorf("failed to count clicks in postgres: %w", err)
+    }
+    sort.SliceStable(hours, func(i, j int) bool {
+        return hours[i].Hour.After(hours[j].Hour)
+    })
+    rebuild.Hours = int64(len(hours))
+
+    var updates []*entity.ClickUpdate
+    rebuilt := make(map[[2]int64]bool)
+    for _, h := range hours {
+        clicks, err := uc.source.SecondCounts(ctx, h.TenantID, h.BannerID, h.Hour)
+        if err != nil {
+            return fmt.Errorf("failed to read clicks of banner %d: %w", h.BannerID, err)
+        }
+        if err := uc.cache.MergeHour(ctx, h.TenantID, h.BannerID, h.Hour, clicks); err != nil {
+            return fmt.Errorf("failed to rebuild clicks of banner %d: %w", h.BannerID, err)
+        }
+        sketch, err := uc.source.HourSketch(ctx, h.TenantID, h.BannerID, h.Hour)
+        if err != nil {
+            return fmt.Errorf("failed to read unique clickers of banner %d: %w", h.BannerID, err)
+        }
+        if sketch != nil {
+            if err := uc.cache.MergeSketch(ctx, h.TenantID, h.BannerID, h.Hour, sketch); err != nil {
+                return fmt.Errorf("failed to rebuild unique clickers of banner %d: %w", h.BannerID, err)
+            }
+        }
+        rebuild.HoursDone++
+
+        banner := [2]int64{h.TenantID, h.BannerID}
+        if !rebuilt[banner] {
+            rebuilt[banner] = true
+            updates = append(updates, &entity.ClickUpdate{
+                TenantID:  h.TenantID,
+                BannerID:  h.BannerID,
+                Timestamp: h.Hour,
+            })
+        }
+
+        if rebuild.HoursDone%rebuildProgressEvery == 0 {
+            if err := uc.cache.SaveRebuild(ctx, rebuild); err != nil {
+                log.Printf("Failed to save cache rebuild progress: %v", err)
+            }
+        }
+    }
+
+    if err := uc.statsCache.Advance(ctx, updates); err != nil {
+        log.Printf("Failed to invalidate stats cache after rebuild: %v", err)
+    }
+    return nil
+}
@@ -2,74 +2,375 @@ package usecase
 
 import (
     "context"
+    "errors"
     "fmt"
     "time"
     "log"
 
+    "clicker/internal/application/dto"
     "clicker/internal/domain/entity"
     "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+    "github.com/hashicorp/golang-lru/v2/expirable"
 )
 
 type ClickUseCase interface {
-    Counter(ctx context.Context, bannerID int64) (int64, error)
+    Counter(ctx context.Context, req *dto.CounterRequest) (int64, error)
+    Impression(ctx context.Context, req *dto.ImpressionRequest) error
+    // Follow counts the click of a tracking link and returns the landing
+    // page to send the browser to.
+    Follow(ctx context.Context, req *dto.FollowRequest) (string, error)
     Stats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error)
 }
 
+// Counter windows.
+const (
+    CounterWindowHour    = "1h"
+    CounterWindowDay     = "24h"
+    CounterWindowToday   = "today"
+    CounterWindowWeek    = "7d"
+    CounterWindowMonth   = "30d"
+    CounterWindowAllTime = "all"
+)
+
+type bannerOwner struct {
+    tenantID int64
+    bannerID int64
+}
+
+// variantOwner keys the cached banner of a variant per tenant.
+type variantOwner struct {
+    tenantID  int64
+    variantID int64
+}
+
 type clickUseCase struct {
-    repo         repository.ClickRepository
-    clickChan    chan *entity.Click
-    batchSize    int
-    batchTimeout time.Duration
+    repo           repository.ClickRepository
+    impressions    repository.ImpressionRepository
+    banners        repository.BannerRepository
+    variants       repository.VariantRepository
+    tenants        repository.TenantRepository
+    quotas         repository.QuotaRepository
+    totals         repository.BannerTotalsRepository
+    feed           repository.ClickFeed
+    uniques        repository.UniqueClickRepository
+    cache          repository.StatsCache
+    links          *ClickLinks
+    owned          *expirable.LRU[bannerOwner, bool]
+    variantBanners *expirable.LRU[variantOwner, int64]
+    tenantsByID    *expirable.LRU[int64, *entity.Tenant]
+    clickChan      chan *entity.Click
+    impressionChan chan *entity.Impression
+    batchSize      int
+    batchTimeout   time.Duration
 }
 
-func NewClickUseCase(repo repository.ClickRepository) ClickUseCase {
+func NewClickUseCase(repo repository.ClickRepository, impressions repository.ImpressionRepository,
+    banners repository.BannerRepository, variants repository.VariantRepository, tenants repository.TenantRepository,
+    quotas repository.QuotaRepository, totals repository.BannerTotalsRepository, feed repository.ClickFeed,
+    uniques repository.UniqueClickRepository, cache repository.StatsCache, links *ClickLinks) ClickUseCase {
     uc := &clickUseCase{
-        repo:         repo,
-        clickChan:    make(chan *entity.Click, 5000),
-        batchSize:    500,
-        batchTimeout: 500 * time.Millisecond,
+        repo:           repo,
+        impressions:    impressions,
+        banners:        banners,
+        variants:       variants,
+        tenants:        tenants,
+        quotas:         quotas,
+        totals:         totals,
+        feed:           feed,
+        uniques:        uniques,
+        cache:          cache,
+        links:          links,
+        owned:          expirable.NewLRU[bannerOwner, bool](10000, nil, time.Minute),
+        variantBanners: expirable.NewLRU[variantOwner, int64](10000, nil, time.Minute),
+        tenantsByID:    expirable.NewLRU[int64, *entity.Tenant](10000, nil, time.Minute),
+        clickChan:      make(chan *entity.Click, 5000),
+        impressionChan: make(chan *entity.Impression, 5000),
+        batchSize:      500,
+        batchTimeout:   500 * time.Millisecond,
     }
     go uc.processBatch()
     return uc
 }
 
-func (uc *clickUseCase) Counter(ctx context.Context, bannerID int64) (int64, error) {
+func (uc *clickUseCase) Counter(ctx context.Context, req *dto.CounterRequest) (int64, error) {
+    t, err := tenant.FromContext(ctx)
+    if err != nil {
+        return 0, err
+    }
+    if err := uc.checkBanner(ctx, t.ID, req.BannerID); err != nil {
+        return 0, err
+    }
+    if err := uc.checkVariant(ctx, t.ID, req.BannerID, req.VariantID); err != nil {
+        return 0, err
+    }
+
     now := time.Now()
-    from := now.Add(-24 * time.Hour)
-    clicks, err := uc.repo.GetStats(ctx, bannerID, from, now)
+    from, err := counterWindowStart(req.Window, req.Timezone, now)
+    if err != nil {
+        return 0, err
+    }
+    if err := uc.checkClickQuota(ctx, t); err != nil {
+        return 0, err
+    }
+
+    total, err := uc.windowTotal(ctx, req.BannerID, from, now)
     if err != nil {
         log.Printf("Failed to get stats: %v", err)
         return 0, err
     }
-    
+
+    err = uc.enqueueClick(ctx, &entity.Click{
+        TenantID:  t.ID,
+        BannerID:  req.BannerID,
+        VariantID: req.VariantID,
+        ClickerID: req.ClickerID,
+        Timestamp: now,
+        Count:     1,
+    })
+    if err != nil {
+        return total, err
+    }
+    return total + 1, nil
+}
+
+// Follow always returns the landing page of a banner it could resolve: the
+// visitor is sent on even if the click could not be counted. Expired links
+// still lead to the banner but are not counted.
+func (uc *clickUseCase) Follow(ctx context.Context, req *dto.FollowRequest) (string, error) {
+    now := time.Now()
+    link, err := uc.links.Parse(req.Token, now)
+    expired := errors.Is(err, ErrClickLinkExpired)
+    if err != nil && !expired {
+        return "", err
+    }
+
+    t, err := uc.tenantByID(ctx, link.TenantID)
+    if err != nil {
+        return "", err
+    }
+    ctx = tenant.NewContext(ctx, t)
+
+    banner, err := uc.banners.GetByID(ctx, link.BannerID)
+    if err != nil {
+        return "", err
+    }
+    if expired {
+        return banner.URL, nil
+    }
+
+    if err := uc.followClick(ctx, t, link, req.ClickerID, now); err != nil {
+        log.Printf("Failed to count click on banner %d: %v", link.BannerID, err)
+    }
+    return banner.URL, nil
+}
+
+func (uc *clickUseCase) followClick(ctx context.Context, t *entity.Tenant, link *ClickLink, clickerID string,
+    now time.Time) error {
+    if err := uc.checkVariant(ctx, t.ID, link.BannerID, link.VariantID); err != nil {
+        return err
+    }
+    if err := uc.checkClickQuota(ctx, t); err != nil {
+        return err
+    }
+
+    return uc.enqueueClick(ctx, &entity.Click{
+        TenantID:  t.ID,
+        BannerID:  link.BannerID,
+        VariantID: link.VariantID,
+        ClickerID: clickerID,
+        Timestamp: now,
+        Count:     1,
+    })
+}
+
+// tenantByID resolves the tenant a tracking link was issued for. Lookups are
+// cached the same way API keys are.
+func (uc *clickUseCase) tenantByID(ctx context.Context, tenantID int64) (*entity.Tenant, error) {
+    if t, ok := uc.tenantsByID.Get(tenantID); ok {
+        return t, nil
+    }
+
+    t, err := uc.tenants.GetByID(ctx, tenantID)
+    if errors.Is(err, repository.ErrTenantNotFound) {
+        return nil, fmt.Errorf("%w: unknown tenant", ErrInvalidClickLink)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to resolve tenant %d: %w", tenantID, err)
+    }
+
+    uc.tenantsByID.Add(tenantID, t)
+    return t, nil
+}
+
+func (uc *clickUseCase) enqueueClick(ctx context.Context, click *entity.Click) error {
+    select {
+    case uc.clickChan <- click:
+        return nil
+
+    case <-ctx.Done():
+        return ctx.Err()
+
+    default:
+        log.Printf("Click channel is full")
+        return fmt.Errorf("service is busy")
+    }
+}
+
+// counterWindowStart returns where the window of a counter starts, or the
+// zero time for the all-time total.
+func counterWindowStart(window, timezone string, now time.Time) (time.Time, error) {
+    switch window {
+    case CounterWindowHour:
+        return now.Add(-time.Hour), nil
+    case "", CounterWindowDay:
+        return now.Add(-24 * time.Hour), nil
+    case CounterWindowToday:
+        loc, err := loadLocation(timezone)
+        if err != nil {
+            return time.Time{}, err
+        }
+        return timeseries.Truncate(now, timeseries.Day, loc), nil
+    case CounterWindowWeek:
+        return now.AddDate(0, 0, -7), nil
+    case CounterWindowMonth:
+        return now.AddDate(0, 0, -30), nil
+    case CounterWindowAllTime:
+        return time.Time{}, nil
+    }
+    return time.Time{}, fmt.Errorf("%w: unknown counter window %q", ErrInvalidArgument, window)
+}
+
+// windowTotal reads the all-time total from banner_totals instead of
+// summing the whole history.
+func (uc *clickUseCase) windowTotal(ctx context.Context, bannerID int64, from, now time.Time) (int64, error) {
+    if from.IsZero() {
+        return uc.totals.GetTotal(ctx, bannerID)
+    }
+
+    clicks, err := uc.repo.GetStats(ctx, bannerID, from, now)
+    if err != nil {
+        return 0, err
+    }
+
     var total int64
     for _, click := range clicks {
         total += int64(click.Count)
     }
+    return total, nil
+}
+
+func (uc *clickUseCase) Impression(ctx context.Context, req *dto.ImpressionRequest) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+    if err := uc.checkBanner(ctx, tenantID, req.BannerID); err != nil {
+        return err
+    }
+    if err := uc.checkVariant(ctx, tenantID, req.BannerID, req.VariantID); err != nil {
+        return err
+    }
 
     select {
-    case uc.clickChan <- &entity.Click{
-        BannerID:  bannerID,
-        Timestamp: now,
+    case uc.impressionChan <- &entity.Impression{
+        TenantID:  tenantID,
+        BannerID:  req.BannerID,
+        VariantID: req.VariantID,
+        Timestamp: time.Now(),
         Count:     1,
     }:
-        return total + 1, nil
-        
+        return nil
+
     case <-ctx.Done():
-        return total, ctx.Err()
-        
+        return ctx.Err()
+
     default:
-        log.Printf("Click channel is full")
-        return total, fmt.Errorf("service is busy")
+        log.Printf("Impression channel is full")
+        return fmt.Errorf("service is busy")
     }
 }
 
+// checkBanner makes sure the banner belongs to the tenant, so clicks and
+// impressions can't be written into someone else's banner. Lookups are
+// cached because this sits on the hot path of every click, so a deleted
+// banner may still count clicks until its entry expires.
+func (uc *clickUseCase) checkBanner(ctx context.Context, tenantID, bannerID int64) error {
+    key := bannerOwner{tenantID: tenantID, bannerID: bannerID}
+    if owned, ok := uc.owned.Get(key); ok {
+        if !owned {
+            return repository.ErrBannerNotFound
+        }
+        return nil
+    }
+
+    _, err := uc.banners.GetByID(ctx, bannerID)
+    if errors.Is(err, repository.ErrBannerNotFound) {
+        uc.owned.Add(key, false)
+        return err
+    }
+    if err != nil {
+        return fmt.Errorf("failed to check banner %d: %w", bannerID, err)
+    }
+
+    uc.owned.Add(key, true)
+    return nil
+}
+
+// checkVariant makes sure a variant, if any, is one of the banner's, so an
+// arbitrary id can't skew per-variant stats. Lookups are cached like in
+// checkBanner; variants never move between banners.
+func (uc *clickUseCase) checkVariant(ctx context.Context, tenantID, bannerID, variantID int64) error {
+    if variantID == 0 {
+        return nil
+    }
+
+    key := variantOwner{tenantID: tenantID, variantID: variantID}
+    owner, ok := uc.variantBanners.Get(key)
+    if !ok {
+        variant, err := uc.variants.GetByID(ctx, variantID)
+        switch {
+        case errors.Is(err, repository.ErrVariantNotFound):
+            owner = 0
+        case err != nil:
+            return fmt.Errorf("failed to check variant %d: %w", variantID, err)
+        default:
+            owner = variant.BannerID
+        }
+        uc.variantBanners.Add(key, owner)
+    }
+
+    if owner != bannerID {
+        return fmt.Errorf("%w: variant %d does not belong to banner %d", ErrInvalidArgument, variantID, bannerID)
+    }
+    return nil
+}
+
+func (uc *clickUseCase) checkClickQuota(ctx context.Context, t *entity.Tenant) error {
+    if t.MaxDailyClicks == 0 {
+        return nil
+    }
+
+    used, err := uc.quotas.IncrDailyClicks(ctx, t.ID, time.Now(), 1)
+    if err != nil {
+        log.Printf("Failed to check click quota of tenant %d: %v", t.ID, err)
+        return nil
+    }
+    if used > t.MaxDailyClicks {
+        return fmt.Errorf("%w: tenant %d reached %d clicks per day", ErrQuotaExceeded, t.ID, t.MaxDailyClicks)
+    }
+
+    return nil
+}
+
 func (uc *clickUseCase) Stats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
     return uc.repo.GetStats(ctx, bannerID, from, to)
 }
 
 func (uc *clickUseCase) processBatch() {
     batch := make([]*entity.Click, 0, uc.batchSize)
+    impressions := make([]*entity.Impression, 0, uc.batchSize)
     ticker := time.NewTicker(uc.batchTimeout)
     defer ticker.Stop()
 
@@ -83,6 +384,14 @@ func (uc *clickUseCase) processBatch() {
                 }
                 batch = make([]*entity.Click, 0, uc.batchSize)
             }
+        case impression := <-uc.impressionChan:
+            impressions = append(impressions, impression)
+            if len(impressions) >= uc.batchSize {
+                if err := uc.saveImpressions(impressions); err != nil {
+                    log.Printf("Failed to save impressions: %v", err)
+                }
+                impressions = make([]*entity.Impression, 0, uc.batchSize)
+            }
         case <-ticker.C:
             if len(batch) > 0 {
                 if err := uc.saveBatch(batch); err != nil {
@@ -90,6 +399,12 @@ func (uc *clickUseCase) processBatch() {
                 }
                 batch = make([]*entity.Click, 0, uc.batchSize)
             }
+            if len(impressions) > 0 {
+                if err := uc.saveImpressions(impressions); err != nil {
+                    log.Printf("Failed to save impressions: %v", err)
+                }
+                impressions = make([]*entity.Impression, 0, uc.batchSize)
+            }
         }
     }
 }
@@ -98,5 +413,53 @@ func (uc *clickUseCase) saveBatch(batch []*entity.Click) error {
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
     
-    return uc.repo.SaveBatch(ctx, batch)
+    if err := uc.repo.SaveBatch(ctx, batch); err != nil {
+        return err
+    }
+
+    // Уникальные клики приблизительные, их потеря не должна ронять сохранение кликов.
+    if err := uc.uniques.SaveBatch(ctx, batch); err != nil {
+        log.Printf("Failed to save unique clickers: %v", err)
+    }
+
+    updates := clickUpdates(batch)
+    if err := uc.cache.Advance(ctx, updates); err != nil {
+        log.Printf("Failed to advance stats watermarks: %v", err)
+    }
+
+    // Живая статистика не должна ронять сохранение кликов.
+    if err := uc.feed.Publish(ctx, updates); err != nil {
+        log.Printf("Failed to publish click updates: %v", err)
+    }
+    return nil
+}
+
+// clickUpdates sums a flushed batch per banner.
+func clickUpdates(batch []*entity.Click) []*entity.ClickUpdate {
+    now := time.Now()
+    byBanner := make(map[bannerOwner]*entity.ClickUpdate)
+    updates := make([]*entity.ClickUpdate, 0)
+    for _, click := range batch {
+        key := bannerOwner{tenantID: click.TenantID, bannerID: click.BannerID}
+        if u, ok := byBanner[key]; ok {
+            u.Clicks += int64(click.Count)
+            continue
+        }
+        u := &entity.ClickUpdate{
+            TenantID:  click.TenantID,
+            BannerID:  click.BannerID,
+            Clicks:    int64(click.Count),
+            Timestamp: now,
+        }
+        byBanner[key] = u
+        updates = append(updates, u)
+    }
+    return updates
+}
+
+func (uc *clickUseCase) saveImpressions(batch []*entity.Impression) error {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+
+    return uc.impressions.SaveBatch(ctx, batch)
 }
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "clicker/pkg/signed"
+)
+
+// clickLinkPurpose keeps click link tokens from being accepted anywhere
+// else the signing key is used.
+const clickLinkPurpose = "click"
+
+var (
+    ErrInvalidClickLink = errors.New("invalid click link")
+    ErrClickLinkExpired = errors.New("click link has expired")
+)
+
+// ClickLink is what a tracking link handed out by ServeBanner counts.
+type ClickLink struct {
+    TenantID  int64
+    BannerID  int64
+    VariantID int64
+}
+
+// ClickLinks signs tracking links, so a browser can follow one without the
+// tenant's API key and still can't count clicks for banners it wasn't
+// served.
+type ClickLinks struct {
+    signer *signed.Signer
+    ttl    time.Duration
+}
+
+func NewClickLinks(signer *signed.Signer, ttl time.Duration) *ClickLinks {
+    return &ClickLinks{
+        signer: signer,
+        ttl:    ttl,
+    }
+}
+
+// Token returns the token of a link issued at now.
+func (l *ClickLinks) Token(link ClickLink, now time.Time) string {
+    return l.signer.Sign(clickLinkPurpose, now.Add(l.ttl), link.TenantID, link.BannerID, link.VariantID)
+}
+
+// Parse reads a link back. An expired link is returned together with
+// ErrClickLinkExpired.
+func (l *ClickLinks) Parse(token string, now time.Time) (*ClickLink, error) {
+    values, err := l.signer.Verify(clickLinkPurpose, token, now)
+    if err != nil && !errors.Is(err, signed.ErrExpiredToken) {
+        return nil, fmt.Errorf("%w: %v", ErrInvalidClickLink, err)
+    }
+    if len(values) != 3 {
+        return nil, ErrInvalidClickLink
+    }
+
+    link := &ClickLink{TenantID: values[0], BannerID: values[1], VariantID: values[2]}
+    if err != nil {
+        return link, ErrClickLinkExpired
+    }
+    return link, nil
+}
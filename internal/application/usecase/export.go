@@ -0,0 +1,67 @@
+package usecase
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "clicker/internal/application/dto"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/timeseries"
+)
+
+// Export sources.
+const (
+    ExportStored   = "stored"
+    ExportRestored = "restored"
+)
+
+// ExportClicks hands rows to send as they are read from the database.
+// Nothing is buffered here; encoding and batching the output is up to the
+// caller.
+func (uc *statsUseCase) ExportClicks(ctx context.Context, req *dto.ExportClicksRequest, send func(*dto.ExportRow) error) error {
+    from := time.Unix(req.TsFrom, 0)
+    to := time.Unix(req.TsTo, 0)
+
+    if from.After(to) {
+        return fmt.Errorf("%w: from is after to", ErrInvalidArgument)
+    }
+    if req.Granularity != "" && !timeseries.Valid(req.Granularity) {
+        return fmt.Errorf("%w: unknown granularity %q", ErrInvalidArgument, req.Granularity)
+    }
+    loc, err := loadLocation(req.Timezone)
+    if err != nil {
+        return err
+    }
+    if req.Source != "" && req.Source != ExportStored && req.Source != ExportRestored {
+        return fmt.Errorf("%w: source must be %s or %s, got %q", ErrInvalidArgument, ExportStored, ExportRestored, req.Source)
+    }
+    restored := req.Source == ExportRestored
+    bannerIDs, err := uniqueBannerIDs(req.BannerIDs)
+    if err != nil {
+        return err
+    }
+
+    log.Printf("Exporting clicks of %d banners from %v to %v", len(bannerIDs), from, to)
+
+    toRow := func(click *entity.Click) error {
+        return send(&dto.ExportRow{
+            ID:        click.ID,
+            BannerID:  click.BannerID,
+            VariantID: click.VariantID,
+            Timestamp: click.Timestamp.UTC(),
+            Clicks:    int64(click.Count),
+        })
+    }
+
+    if req.Granularity == "" {
+        err = uc.export.ExportClicks(ctx, bannerIDs, from, to, restored, toRow)
+    } else {
+        err = uc.export.ExportSeries(ctx, bannerIDs, from, to, req.Granularity, loc, restored, toRow)
+    }
+    if err != nil {
+        return fmt.Errorf("failed to export clicks: %w", err)
+    }
+    return nil
+}
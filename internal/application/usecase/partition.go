@@ -0,0 +1,140 @@
+package usecase
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/timeseries"
+)
+
+// PartitionPolicy says how clicks are partitioned. Expired partitions are
+// removed by the retention job, see RetentionPolicy.
+type PartitionPolicy struct {
+    // Interval is timeseries.Day or timeseries.Month.
+    Interval string
+    // Premake is how many partitions are kept ready after the current one.
+    Premake int
+}
+
+type PartitionUseCase interface {
+    // Maintain creates upcoming partitions and moves clicks that fell into
+    // the default partition into partitions of their own. Replicas
+    // may call it at the same time, only one of them does the work.
+    Maintain(ctx context.Context) error
+    // Run maintains partitions right away and then every interval, until
+    // ctx is done.
+    Run(ctx context.Context, interval time.Duration)
+}
+
+type partitionUseCase struct {
+    partitions repository.ClickPartitionRepository
+    policy     PartitionPolicy
+}
+
+func NewPartitionUseCase(partitions repository.ClickPartitionRepository, policy PartitionPolicy) PartitionUseCase {
+    return &partitionUseCase{
+        partitions: partitions,
+        policy:     policy,
+    }
+}
+
+func (uc *partitionUseCase) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if err := uc.Maintain(ctx); err != nil {
+            log.Printf("Failed to maintain click partitions: %v", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (uc *partitionUseCase) Maintain(ctx context.Context) error {
+    unlock, ok, err := uc.partitions.TryLock(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to lock partitions: %w", err)
+    }
+    if !ok {
+        log.Printf("Click partitions are being maintained by another replica")
+        return nil
+    }
+    defer unlock()
+
+    existing, err := uc.partitions.List(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to list partitions: %w", err)
+    }
+
+    now := time.Now().UTC()
+    if err := uc.createUpcoming(ctx, existing, now); err != nil {
+        return err
+    }
+    return uc.moveStray(ctx, existing)
+}
+
+// createUpcoming makes sure the current period and the next Premake ones
+// have a partition. Periods already covered, for example by a month
+// partition made before switching to days, are skipped.
+func (uc *partitionUseCase) createUpcoming(ctx context.Context, existing []*entity.ClickPartition, now time.Time) error {
+    from := timeseries.Truncate(now, uc.policy.Interval, time.UTC)
+    for i := 0; i <= uc.policy.Premake; i++ {
+        to := timeseries.Next(from, uc.policy.Interval, time.UTC)
+        if !partitionsOverlap(existing, from, to) {
+            if err := uc.partitions.Create(ctx, from, to); err != nil {
+                return fmt.Errorf("failed to create partition for %v: %w", from, err)
+            }
+            log.Printf("Created clicks partition for %v - %v", from, to)
+        }
+        from = to
+    }
+    return nil
+}
+
+// moveStray creates partitions for the periods of clicks that landed in the
+// default partition, which moves them there. Retention removes them later
+// like any other partition if they are already past it.
+func (uc *partitionUseCase) moveStray(ctx context.Context, existing []*entity.ClickPartition) error {
+    days, err := uc.partitions.StrayDays(ctx)
+    if err != nil {
+        return fmt.Errorf("failed to list clicks outside partitions: %w", err)
+    }
+
+    for _, day := range days {
+        from := timeseries.Truncate(day, uc.policy.Interval, time.UTC)
+        to := timeseries.Next(from, uc.policy.Interval, time.UTC)
+        if partitionsOverlap(existing, from, to) {
+            // Месяц частично занят дневными секциями (или уже создан на
+            // прошлом дне из списка), тогда хватит секции на сам день.
+            from, to = day, day.AddDate(0, 0, 1)
+            if partitionsOverlap(existing, from, to) {
+                continue
+            }
+        }
+
+        if err := uc.partitions.Create(ctx, from, to); err != nil {
+            return fmt.Errorf("failed to create partition for %v: %w", from, err)
+        }
+        log.Printf("Created clicks partition for stray clicks in %v - %v", from, to)
+        existing = append(existing, &entity.ClickPartition{From: from, To: to})
+    }
+    return nil
+}
+
+func partitionsOverlap(partitions []*entity.ClickPartition, from, to time.Time) bool {
+    for _, p := range partitions {
+        if p.From.Before(to) && from.Before(p.To) {
+            return true
+        }
+    }
+    return false
+}
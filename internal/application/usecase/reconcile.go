@@ -0,0 +1,209 @@
+package usecase
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+)
+
+const (
+    // reconcileWindow is how far back Redis is checked. It stays an hour
+    // short of the Redis retention, so no hour expires during a pass.
+    reconcileWindow = 23 * time.Hour
+    // reconcileLag keeps the pass off the current hour and the batches
+    // still being flushed into the previous one.
+    reconcileLag = 5 * time.Minute
+    // reconcileChunk is how many hours are read from Redis per pipeline.
+    reconcileChunk = 1000
+)
+
+type ReconcileUseCase interface {
+    // Reconcile compares the hourly click counters in Redis with Postgres
+    // for every banner clicked in the window, and rewrites the hours that
+    // drifted unless dryRun is set. When every drifted hour is repaired,
+    // Redis coverage moves back to the start of the window. It returns nil
+    // when another replica is reconciling at the moment.
+    Reconcile(ctx context.Context, dryRun bool) (*entity.ReconcileReport, error)
+    // Run reconciles every interval until ctx is done.
+    Run(ctx context.Context, interval time.Duration)
+}
+
+type reconcileUseCase struct {
+    source     repository.ClickHourSource
+    cache      repository.ClickHourCache
+    coverage   repository.ClickCoverage
+    statsCache repository.StatsCache
+}
+
+func NewReconcileUseCase(source repository.ClickHourSource, cache repository.ClickHourCache,
+    coverage repository.ClickCoverage, statsCache repository.StatsCache) ReconcileUseCase {
+    return &reconcileUseCase{
+        source:     source,
+        cache:      cache,
+        coverage:   coverage,
+        statsCache: statsCache,
+    }
+}
+
+func (uc *reconcileUseCase) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+
+        if _, err := uc.Reconcile(ctx, false); err != nil {
+            log.Printf("Failed to reconcile Redis clicks: %v", err)
+        }
+    }
+}
+
+func (uc *reconcileUseCase) Reconcile(ctx context.Context, dryRun bool) (*entity.ReconcileReport, error) {
+    unlock, ok, err := uc.source.TryLock(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock reconciliation: %w", err)
+    }
+    if !ok {
+        log.Printf("Redis clicks are being reconciled by another replica")
+        return nil, nil
+    }
+    defer unlock()
+
+    token, err := uc.coverage.Claim(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to claim cache coverage: %w", err)
+    }
+
+    to := time.Now().UTC().Add(-reconcileLag).Truncate(time.Hour)
+    report := &entity.ReconcileReport{
+        From: to.Add(-reconcileWindow),
+        To:   to,
+    }
+
+    counts, err := uc.source.HourCounts(ctx, report.From, report.To)
+    if err != nil {
+        return nil, fmt.Errorf("failed to count clicks in postgres: %w", err)
+    }
+
+    hours := reconcileHours(counts, report.From, report.To)
+    report.Checked = len(hours)
+    for start := 0; start < len(hours); start += reconcileChunk {
+        chunk := hours[start:min(start+reconcileChunk, len(hours))]
+        cached, err := uc.cache.HourCounts(ctx, chunk)
+        if err != nil {
+            return nil, fmt.Errorf("failed to count clicks in redis: %w", err)
+        }
+
+        for i, h := range chunk {
+            if cached[i] == h.Clicks {
+                continue
+            }
+            report.Drifted = append(report.Drifted, &entity.HourDrift{
+                TenantID: h.TenantID,
+                BannerID: h.BannerID,
+                Hour:     h.Hour,
+                Postgres: h.Clicks,
+                Redis:    cached[i],
+            })
+        }
+    }
+
+    for _, d := range report.Drifted {
+        log.Printf("Redis clicks drifted: tenant %d, banner %d, hour %v: postgres %d, redis %d",
+            d.TenantID, d.BannerID, d.Hour, d.Postgres, d.Redis)
+    }
+    if !dryRun {
+        err = uc.repair(ctx, report)
+    }
+    if err == nil && report.Repaired == len(report.Drifted) {
+        uc.restoreCoverage(ctx, token, report)
+    }
+
+    log.Printf("Reconciled Redis clicks from %v to %v: %d hours checked, %d drifted, %d repaired",
+        report.From, report.To, report.Checked, len(report.Drifted), report.Repaired)
+    return report, err
+}
+
+// repair rewrites the drifted hours from Postgres and moves the stats cache
+// watermarks of their banners, so no response is served from the old counts.
+func (uc *reconcileUseCase) repair(ctx context.Context, report *entity.ReconcileReport) error {
+    var updates []*entity.ClickUpdate
+    repaired := make(map[[2]int64]bool)
+
+    for _, d := range report.Drifted {
+        clicks, err := uc.source.SecondCounts(ctx, d.TenantID, d.BannerID, d.Hour)
+        if err != nil {
+            return fmt.Errorf("failed to read clicks of banner %d: %w", d.BannerID, err)
+        }
+        if err := uc.cache.ReplaceHour(ctx, d.TenantID, d.BannerID, d.Hour, clicks); err != nil {
+            return fmt.Errorf("failed to repair clicks of banner %d: %w", d.BannerID, err)
+        }
+        report.Repaired++
+
+        banner := [2]int64{d.TenantID, d.BannerID}
+        if !repaired[banner] {
+            repaired[banner] = true
+            updates = append(updates, &entity.ClickUpdate{
+                TenantID:  d.TenantID,
+                BannerID:  d.BannerID,
+                Timestamp: d.Hour,
+            })
+        }
+    }
+
+    if err := uc.statsCache.Advance(ctx, updates); err != nil {
+        log.Printf("Failed to invalidate stats cache after reconciliation: %v", err)
+    }
+    return nil
+}
+
+// restoreCoverage lets reads use Redis again for the checked window, if
+// the gap that moved its coverage lies inside it.
+func (uc *reconcileUseCase) restoreCoverage(ctx context.Context, token int64, report *entity.ReconcileReport) {
+    restored, err := uc.coverage.Restore(ctx, token, report.From, report.To)
+    if err != nil {
+        log.Printf("Failed to restore Redis coverage: %v", err)
+        return
+    }
+    if restored {
+        log.Printf("Redis covers clicks from %v again", report.From)
+    }
+}
+
+// reconcileHours lists every hour of the window for each banner with clicks
+// in it, so an hour Redis counted but Postgres did not is checked too.
+func reconcileHours(counts []*entity.HourCount, from, to time.Time) []*entity.HourCount {
+    type banner struct{ tenantID, bannerID int64 }
+
+    var banners []banner
+    clicks := make(map[banner]map[int64]int64)
+    for _, c := range counts {
+        b := banner{c.TenantID, c.BannerID}
+        if clicks[b] == nil {
+            clicks[b] = make(map[int64]int64)
+            banners = append(banners, b)
+        }
+        clicks[b][c.Hour.Unix()] += c.Clicks
+    }
+
+    var hours []*entity.HourCount
+    for _, b := range banners {
+        for hour := from; hour.Before(to); hour = hour.Add(time.Hour) {
+            hours = append(hours, &entity.HourCount{
+                TenantID: b.tenantID,
+                BannerID: b.bannerID,
+                Hour:     hour,
+                Clicks:   clicks[b][hour.Unix()],
+            })
+        }
+    }
+    return hours
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/timeseries"
+)
+
+const (
+    defaultRetentionRunsLimit = 20
+    maxRetentionRunsLimit     = 100
+)
+
+// RetentionPolicy says how long each resolution of clicks is kept. Daily
+// aggregates are kept forever. Zero keeps a tier forever.
+type RetentionPolicy struct {
+    Raw          time.Duration
+    HourlyMonths int
+    // DetachPartitions keeps raw partitions past the horizon as standalone
+    // tables instead of dropping them.
+    DetachPartitions bool
+}
+
+type RetentionUseCase interface {
+    // Enforce deletes click data past the horizons and records the run.
+    // It returns nil when the policy keeps everything or another replica
+    // is enforcing at the moment.
+    Enforce(ctx context.Context) (*entity.RetentionRun, error)
+    // Run enforces retention right away and then every interval, until ctx
+    // is done.
+    Run(ctx context.Context, interval time.Duration)
+    ListRuns(ctx context.Context, limit int) ([]*entity.RetentionRun, error)
+}
+
+type retentionUseCase struct {
+    retention repository.RetentionRepository
+    policy    RetentionPolicy
+}
+
+func NewRetentionUseCase(retention repository.RetentionRepository, policy RetentionPolicy) RetentionUseCase {
+    return &retentionUseCase{
+        retention: retention,
+        policy:    policy,
+    }
+}
+
+func (uc *retentionUseCase) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if _, err := uc.Enforce(ctx); err != nil {
+            log.Printf("Failed to enforce click retention: %v", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (uc *retentionUseCase) Enforce(ctx context.Context) (*entity.RetentionRun, error) {
+    if uc.policy.Raw <= 0 && uc.policy.HourlyMonths <= 0 {
+        return nil, nil
+    }
+
+    unlock, ok, err := uc.retention.TryLock(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock retention: %w", err)
+    }
+    if !ok {
+        log.Printf("Click retention is being enforced by another replica")
+        return nil, nil
+    }
+    defer unlock()
+
+    now := time.Now().UTC()
+    run := &entity.RetentionRun{StartedAt: now}
+    err = uc.expire(ctx, run, now)
+    if err != nil {
+        run.Error = err.Error()
+    }
+    run.FinishedAt = time.Now().UTC()
+
+    // Запуск записываем и при ошибке, она видна через API.
+    if saveErr := uc.retention.SaveRun(context.WithoutCancel(ctx), run); saveErr != nil {
+        log.Printf("Failed to save retention run: %v", saveErr)
+    }
+    if err != nil {
+        return run, err
+    }
+
+    log.Printf("Enforced click retention: raw %s, hourly %s", describeExpiry(run.Raw), describeExpiry(run.Hourly))
+    return run, nil
+}
+
+// expire goes from the finest tier up. Each tier is capped by the rollup
+// above it, so data is always downsampled before it is deleted.
+func (uc *retentionUseCase) expire(ctx context.Context, run *entity.RetentionRun, now time.Time) error {
+    var err error
+    if uc.policy.Raw > 0 {
+        horizon := timeseries.Truncate(now.Add(-uc.policy.Raw), timeseries.Hour, time.UTC)
+        run.Raw, err = uc.retention.ExpireRaw(ctx, horizon, uc.policy.DetachPartitions)
+        if err != nil {
+            return fmt.Errorf("failed to expire raw clicks: %w", err)
+        }
+    }
+
+    if uc.policy.HourlyMonths > 0 {
+        horizon := timeseries.Truncate(now.AddDate(0, -uc.policy.HourlyMonths, 0), timeseries.Day, time.UTC)
+        run.Hourly, err = uc.retention.ExpireHourly(ctx, horizon)
+        if err != nil {
+            return fmt.Errorf("failed to expire hourly clicks: %w", err)
+        }
+    }
+    return nil
+}
+
+func (uc *retentionUseCase) ListRuns(ctx context.Context, limit int) ([]*entity.RetentionRun, error) {
+    if limit == 0 {
+        limit = defaultRetentionRunsLimit
+    }
+    if limit < 0 || limit > maxRetentionRunsLimit {
+        return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidArgument, maxRetentionRunsLimit)
+    }
+
+    runs, err := uc.retention.ListRuns(ctx, limit)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list retention runs: %w", err)
+    }
+    return runs, nil
+}
+
+func describeExpiry(expiry *entity.TierExpiry) string {
+    if expiry == nil {
+        return "kept"
+    }
+    return fmt.Sprintf("from %v, %d rows and %d partitions deleted",
+        expiry.Horizon, expiry.Deleted, expiry.PartitionsDropped)
+}
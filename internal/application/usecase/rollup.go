@@ -0,0 +1,101 @@
+package usecase
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/timeseries"
+)
+
+// rollupLag keeps the hourly rollup behind the clock, so clicks still
+// sitting in a batch when their hour ends are not left out.
+const rollupLag = 5 * time.Minute
+
+// rollupRevisit is how far behind the hourly watermark every run
+// aggregates again. A click is saved with the time it was made, so one that
+// waited in a batch while its hour was rolled up lands below the watermark.
+const rollupRevisit = 2 * time.Hour
+
+// A single rollup step covers at most this much, so catching up after a
+// long pause is done in short transactions.
+const (
+    hourlyRollupSpan = 24 * time.Hour
+    dailyRollupSpan  = 30 * 24 * time.Hour
+)
+
+type RollupUseCase interface {
+    // RollUp brings the hourly and then the daily aggregates up to date,
+    // revisiting the last rollupRevisit of them for late clicks.
+    RollUp(ctx context.Context) error
+    // Run rolls up right away and then every interval, until ctx is done.
+    Run(ctx context.Context, interval time.Duration)
+}
+
+type rollupUseCase struct {
+    rollups repository.RollupRepository
+}
+
+func NewRollupUseCase(rollups repository.RollupRepository) RollupUseCase {
+    return &rollupUseCase{
+        rollups: rollups,
+    }
+}
+
+func (uc *rollupUseCase) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if err := uc.RollUp(ctx); err != nil {
+            log.Printf("Failed to roll up clicks: %v", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (uc *rollupUseCase) RollUp(ctx context.Context) error {
+    if err := uc.rollups.Revisit(ctx, rollupRevisit); err != nil {
+        return fmt.Errorf("failed to revisit rolled up hours: %w", err)
+    }
+
+    hourly, err := catchUp(ctx, uc.rollups.RollUpHourly, time.Now().Add(-rollupLag), hourlyRollupSpan)
+    if err != nil {
+        return fmt.Errorf("failed to roll up hours: %w", err)
+    }
+
+    // Сутки собираются из часов, поэтому только до полных суток в часовых агрегатах.
+    _, err = catchUp(ctx, uc.rollups.RollUpDaily, timeseries.Truncate(hourly, timeseries.Day, time.UTC), dailyRollupSpan)
+    if err != nil {
+        return fmt.Errorf("failed to roll up days: %w", err)
+    }
+    return nil
+}
+
+// catchUp repeats a rollup step until its watermark stops moving and
+// returns the final watermark.
+func catchUp(ctx context.Context, step func(context.Context, time.Time, time.Duration) (time.Time, error),
+    until time.Time, span time.Duration) (time.Time, error) {
+    var watermark time.Time
+    for {
+        next, err := step(ctx, until, span)
+        if err != nil {
+            return time.Time{}, err
+        }
+        if next.Equal(watermark) {
+            return watermark, nil
+        }
+        watermark = next
+
+        if err := ctx.Err(); err != nil {
+            return time.Time{}, err
+        }
+    }
+}
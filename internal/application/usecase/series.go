@@ -0,0 +1,48 @@
+package usecase
+
+import (
+    "fmt"
+    "time"
+
+    "clicker/internal/application/dto"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/timeseries"
+)
+
+// maxSeriesBuckets keeps a minute series over a year from being built in
+// memory by accident.
+const maxSeriesBuckets = 10000
+
+// buildSeries sums clicks into consecutive buckets covering [from, to),
+// aligned in loc. Buckets without clicks are present with zero, so the
+// series can be charted as is. In cumulative mode each point holds the
+// running total.
+func buildSeries(clicks []*entity.Click, from, to time.Time, granularity string, loc *time.Location,
+    cumulative bool) ([]*dto.StatsPoint, error) {
+    var series []*dto.StatsPoint
+    index := make(map[int64]*dto.StatsPoint)
+    for start := timeseries.Truncate(from, granularity, loc); start.Before(to); start = timeseries.Next(start, granularity, loc) {
+        if len(series) == maxSeriesBuckets {
+            return nil, fmt.Errorf("%w: range has more than %d %s buckets", ErrInvalidArgument, maxSeriesBuckets, granularity)
+        }
+        point := &dto.StatsPoint{BucketStart: start.Unix()}
+        series = append(series, point)
+        index[point.BucketStart] = point
+    }
+
+    for _, click := range clicks {
+        if point, ok := index[timeseries.Truncate(click.Timestamp, granularity, loc).Unix()]; ok {
+            point.Clicks += int64(click.Count)
+        }
+    }
+
+    if cumulative {
+        var total int64
+        for _, point := range series {
+            total += point.Clicks
+            point.Clicks = total
+        }
+    }
+
+    return series, nil
+}
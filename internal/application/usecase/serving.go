@@ -0,0 +1,132 @@
+package usecase
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "math/rand"
+    "strings"
+    "time"
+
+    "clicker/internal/application/dto"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+var ErrNoBannerAvailable = errors.New("no banner available for placement")
+
+type ServingUseCase interface {
+    ServeBanner(ctx context.Context, placement string) (*dto.ServedBanner, error)
+}
+
+type ImpressionRecorder interface {
+    Impression(ctx context.Context, req *dto.ImpressionRequest) error
+}
+
+type servingUseCase struct {
+    banners     repository.BannerRepository
+    variants    repository.VariantRepository
+    impressions ImpressionRecorder
+    links       *ClickLinks
+    publicURL   string
+}
+
+func NewServingUseCase(banners repository.BannerRepository, variants repository.VariantRepository,
+    impressions ImpressionRecorder, links *ClickLinks, publicURL string) ServingUseCase {
+    return &servingUseCase{
+        banners:     banners,
+        variants:    variants,
+        impressions: impressions,
+        links:       links,
+        publicURL:   strings.TrimRight(publicURL, "/"),
+    }
+}
+
+func (uc *servingUseCase) ServeBanner(ctx context.Context, placement string) (*dto.ServedBanner, error) {
+    if placement == "" {
+        return nil, fmt.Errorf("%w: placement is required", ErrInvalidArgument)
+    }
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    candidates, err := uc.banners.FindServable(ctx, placement)
+    if err != nil {
+        return nil, fmt.Errorf("failed to find banners for placement %q: %w", placement, err)
+    }
+
+    banner, ok := pickWeighted(candidates, func(b *entity.Banner) int { return b.Weight })
+    if !ok {
+        return nil, fmt.Errorf("%w %q", ErrNoBannerAvailable, placement)
+    }
+
+    served := &dto.ServedBanner{
+        BannerID:    banner.ID,
+        Name:        banner.Name,
+        CreativeURL: banner.CreativeURL,
+        URL:         banner.URL,
+    }
+
+    variants, err := uc.variants.ListByBanner(ctx, banner.ID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list variants of banner %d: %w", banner.ID, err)
+    }
+    if variant, ok := pickWeighted(variants, func(v *entity.Variant) int { return v.Weight }); ok {
+        served.VariantID = variant.ID
+        if variant.CreativeURL != "" {
+            served.CreativeURL = variant.CreativeURL
+        }
+    }
+
+    // Ссылку открывает браузер без API-ключа, поэтому баннер и вариант
+    // зашиты в подписанный токен.
+    token := uc.links.Token(ClickLink{
+        TenantID:  tenantID,
+        BannerID:  served.BannerID,
+        VariantID: served.VariantID,
+    }, time.Now())
+    served.ClickURL = fmt.Sprintf("%s/click/%s", uc.publicURL, token)
+
+    err = uc.impressions.Impression(ctx, &dto.ImpressionRequest{
+        BannerID:  served.BannerID,
+        VariantID: served.VariantID,
+    })
+    if err != nil {
+        log.Printf("Failed to record impression for banner %d: %v", served.BannerID, err)
+    }
+
+    return served, nil
+}
+
+// pickWeighted chooses an item at random with probability proportional to
+// its weight. Items with a non-positive weight are never picked.
+func pickWeighted[T any](items []T, weight func(T) int) (T, bool) {
+    var zero T
+
+    total := 0
+    for _, item := range items {
+        if w := weight(item); w > 0 {
+            total += w
+        }
+    }
+    if total == 0 {
+        return zero, false
+    }
+
+    n := rand.Intn(total)
+    for _, item := range items {
+        w := weight(item)
+        if w <= 0 {
+            continue
+        }
+        if n < w {
+            return item, true
+        }
+        n -= w
+    }
+
+    return zero, false
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import "math"
+
+const defaultConfidenceLevel = 0.95
+
+// zForConfidence returns the two-sided critical value of the standard normal
+// distribution for the given confidence level, e.g. 1.96 for 0.95.
+func zForConfidence(level float64) float64 {
+    return math.Sqrt2 * math.Erfinv(level)
+}
+
+func proportion(successes, trials int64) float64 {
+    if trials <= 0 {
+        return 0
+    }
+    return math.Min(float64(successes)/float64(trials), 1)
+}
+
+// wilsonInterval is the Wilson score interval for a binomial proportion. Unlike
+// the normal approximation it stays inside [0, 1] for small samples and CTRs
+// close to zero, which is the usual case for banners.
+func wilsonInterval(successes, trials int64, z float64) (float64, float64) {
+    if trials <= 0 {
+        return 0, 0
+    }
+
+    n := float64(trials)
+    p := proportion(successes, trials)
+    z2 := z * z
+
+    center := (p + z2/(2*n)) / (1 + z2/n)
+    margin := z * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+
+    return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// twoProportionZTest compares the variant proportion against the control one
+// using the pooled two-proportion z-test and returns z and the two-sided p-value.
+func twoProportionZTest(controlSuccesses, controlTrials, successes, trials int64) (float64, float64) {
+    if controlTrials <= 0 || trials <= 0 {
+        return 0, 1
+    }
+
+    p1 := proportion(controlSuccesses, controlTrials)
+    p2 := proportion(successes, trials)
+    pooled := math.Min(float64(controlSuccesses+successes)/float64(controlTrials+trials), 1)
+
+    se := math.Sqrt(pooled * (1 - pooled) * (1/float64(controlTrials) + 1/float64(trials)))
+    if se == 0 {
+        return 0, 1
+    }
+
+    z := (p2 - p1) / se
+    return z, math.Erfc(math.Abs(z) / math.Sqrt2)
+}
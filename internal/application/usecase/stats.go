@@ -7,20 +7,66 @@ import (
     "time"
     
     "clicker/internal/application/dto"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
     "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+    "golang.org/x/sync/singleflight"
 )
 
 type StatsUseCase interface {
     GetStats(ctx context.Context, req *dto.StatsRequest) (*dto.StatsResponse, error)
+    GetStatsByLabels(ctx context.Context, req *dto.LabelStatsRequest) (*dto.LabelStatsResponse, error)
+    CompareVariants(ctx context.Context, req *dto.CompareVariantsRequest) (*dto.CompareVariantsResponse, error)
+    BatchStats(ctx context.Context, req *dto.BatchStatsRequest) (*dto.BatchStatsResponse, error)
+    TopBanners(ctx context.Context, req *dto.TopBannersRequest) (*dto.TopBannersResponse, error)
+    WatchStats(ctx context.Context, req *dto.WatchStatsRequest, send func(*dto.StatsUpdate) error) error
+    CreateWatchToken(ctx context.Context, req *dto.CreateWatchTokenRequest) (*dto.WatchToken, error)
+    ExportClicks(ctx context.Context, req *dto.ExportClicksRequest, send func(*dto.ExportRow) error) error
 }
 
+const maxBatchBanners = 1000
+
+// Comparison modes of GetStats.
+const (
+    ComparePreviousPeriod = "previous_period"
+    CompareLastWeek       = "last_week"
+    CompareLastYear       = "last_year"
+)
+
+const (
+    defaultTopWindow = time.Hour
+    defaultTopLimit  = 10
+    maxTopLimit      = 100
+)
+
 type statsUseCase struct {
-    repo repository.StatsRepository
+    repo     repository.StatsRepository
+    banners  repository.BannerRepository
+    variants repository.VariantStatsRepository
+    top      repository.TopBannersRepository
+    feed     repository.ClickFeed
+    uniques  repository.UniqueClickRepository
+    export   repository.ClickExportRepository
+    cache    repository.StatsCache
+    watch    *WatchTokens
+    flights  singleflight.Group
 }
 
-func NewStatsUseCase(repo repository.StatsRepository) StatsUseCase {
+func NewStatsUseCase(repo repository.StatsRepository, banners repository.BannerRepository, variants repository.VariantStatsRepository,
+    top repository.TopBannersRepository, feed repository.ClickFeed, uniques repository.UniqueClickRepository,
+    export repository.ClickExportRepository, cache repository.StatsCache, watch *WatchTokens) StatsUseCase {
     return &statsUseCase{
-        repo: repo,
+        repo:     repo,
+        banners:  banners,
+        variants: variants,
+        top:      top,
+        feed:     feed,
+        uniques:  uniques,
+        export:   export,
+        cache:    cache,
+        watch:    watch,
     }
 }
 
@@ -29,12 +75,80 @@ func (uc *statsUseCase) GetStats(ctx context.Context, req *dto.StatsRequest) (*d
     to := time.Unix(req.TsTo, 0)
     
     if from.After(to) {
-        return nil, fmt.Errorf("invalid time range: from is after to")
+        return nil, fmt.Errorf("%w: from is after to", ErrInvalidArgument)
+    }
+    if req.Granularity != "" && !timeseries.Valid(req.Granularity) {
+        return nil, fmt.Errorf("%w: unknown granularity %q", ErrInvalidArgument, req.Granularity)
+    }
+    loc, err := loadLocation(req.Timezone)
+    if err != nil {
+        return nil, err
+    }
+    
+    var prevFrom, prevTo time.Time
+    if req.Compare != "" {
+        prevFrom, prevTo, err = comparisonRange(req.Compare, from, to, loc)
+        if err != nil {
+            return nil, err
+        }
     }
     
+    key := statsKey(req, from, to, loc)
+    return uc.cachedStats(ctx, req.BannerID, key, func(ctx context.Context) (*dto.StatsResponse, error) {
+        return uc.computeStats(ctx, req, from, to, prevFrom, prevTo, loc)
+    })
+}
+
+func (uc *statsUseCase) computeStats(ctx context.Context, req *dto.StatsRequest, from, to, prevFrom, prevTo time.Time,
+    loc *time.Location) (*dto.StatsResponse, error) {
     log.Printf("Getting stats for banner %d from %v to %v", req.BannerID, from, to)
+
+    ctx, report := freshness.NewContext(ctx)
+    resp, err := uc.periodStats(ctx, req, from, to, loc)
+    if err != nil {
+        return nil, err
+    }
+    if req.Compare == "" {
+        resp.Freshness = dto.FreshnessFromEntity(report.Freshness())
+        return resp, nil
+    }
+
+    log.Printf("Comparing with %s: %v to %v", req.Compare, prevFrom, prevTo)
+
+    previous, err := uc.periodStats(ctx, req, prevFrom, prevTo, loc)
+    if err != nil {
+        return nil, err
+    }
+
+    resp.Comparison = &dto.StatsComparison{
+        Mode:        req.Compare,
+        TsFrom:      prevFrom.Unix(),
+        TsTo:        prevTo.Unix(),
+        TotalClicks: previous.TotalClicks,
+        Series:      previous.Series,
+        Delta:        resp.TotalClicks - previous.TotalClicks,
+        UniqueClicks: previous.UniqueClicks,
+    }
+    if previous.TotalClicks != 0 {
+        resp.Comparison.DeltaPercent = float64(resp.Comparison.Delta) / float64(previous.TotalClicks) * 100
+    }
+    resp.Freshness = dto.FreshnessFromEntity(report.Freshness())
     
-    clicks, err := uc.repo.GetStats(ctx, req.BannerID, from, to)
+    return resp, nil
+}
+
+// periodStats is the total and, with a granularity, the series of one range.
+func (uc *statsUseCase) periodStats(ctx context.Context, req *dto.StatsRequest, from, to time.Time,
+    loc *time.Location) (*dto.StatsResponse, error) {
+    var (
+        clicks []*entity.Click
+        err    error
+    )
+    if req.Granularity != "" {
+        clicks, err = uc.repo.GetSeries(ctx, req.BannerID, from, to, req.Granularity, loc)
+    } else {
+        clicks, err = uc.repo.GetStats(ctx, req.BannerID, from, to)
+    }
     if err != nil {
         log.Printf("Error getting stats: %v", err)
         return nil, err
@@ -44,8 +158,390 @@ func (uc *statsUseCase) GetStats(ctx context.Context, req *dto.StatsRequest) (*d
     for _, click := range clicks {
         totalClicks += int64(click.Count)
     }
+
+    sketch, err := uc.uniques.GetSketch(ctx, req.BannerID, from, to)
+    if err != nil {
+        log.Printf("Error getting unique clickers: %v", err)
+        return nil, err
+    }
+
+    resp := &dto.StatsResponse{
+        TotalClicks:  totalClicks,
+        UniqueClicks: int64(sketch.Count()),
+    }
+    if req.Granularity != "" {
+        resp.Series, err = buildSeries(clicks, from, to, req.Granularity, loc, req.Cumulative)
+        if err != nil {
+            return nil, err
+        }
+    }
     
-    return &dto.StatsResponse{
-        TotalClicks: totalClicks,
+    return resp, nil
+}
+
+// comparisonRange returns the range to compare [from, to) with. Weeks and
+// years are stepped back in loc, so "last week" keeps local midnights across
+// a DST switch.
+func comparisonRange(mode string, from, to time.Time, loc *time.Location) (time.Time, time.Time, error) {
+    switch mode {
+    case CompareLastWeek:
+        return from.In(loc).AddDate(0, 0, -7), to.In(loc).AddDate(0, 0, -7), nil
+    case CompareLastYear:
+        return from.In(loc).AddDate(-1, 0, 0), to.In(loc).AddDate(-1, 0, 0), nil
+    case ComparePreviousPeriod:
+        length := to.Sub(from)
+        return from.Add(-length), from, nil
+    }
+    return time.Time{}, time.Time{}, fmt.Errorf("%w: unknown comparison %q", ErrInvalidArgument, mode)
+}
+
+// loadLocation resolves an IANA time zone name, UTC when empty.
+func loadLocation(name string) (*time.Location, error) {
+    if name == "" {
+        return time.UTC, nil
+    }
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidArgument, name)
+    }
+    return loc, nil
+}
+
+func (uc *statsUseCase) GetStatsByLabels(ctx context.Context, req *dto.LabelStatsRequest) (*dto.LabelStatsResponse, error) {
+    from := time.Unix(req.TsFrom, 0)
+    to := time.Unix(req.TsTo, 0)
+
+    if from.After(to) {
+        return nil, fmt.Errorf("%w: from is after to", ErrInvalidArgument)
+    }
+    if len(req.Selector) == 0 {
+        return nil, fmt.Errorf("%w: label selector is empty", ErrInvalidArgument)
+    }
+
+    banners, err := uc.banners.FindByLabels(ctx, req.Selector)
+    if err != nil {
+        return nil, fmt.Errorf("failed to find banners by labels: %w", err)
+    }
+
+    log.Printf("Getting stats for %d banners matching %v from %v to %v", len(banners), req.Selector, from, to)
+
+    ctx, report := freshness.NewContext(ctx)
+    resp := &dto.LabelStatsResponse{
+        Banners: make([]*dto.BannerClicks, 0, len(banners)),
+    }
+    for _, banner := range banners {
+        clicks, err := uc.repo.GetStats(ctx, banner.ID, from, to)
+        if err != nil {
+            log.Printf("Error getting stats for banner %d: %v", banner.ID, err)
+            return nil, err
+        }
+
+        var totalClicks int64
+        for _, click := range clicks {
+            totalClicks += int64(click.Count)
+        }
+
+        resp.TotalClicks += totalClicks
+        resp.Banners = append(resp.Banners, &dto.BannerClicks{
+            BannerID:    banner.ID,
+            TotalClicks: totalClicks,
+        })
+    }
+    resp.Freshness = dto.FreshnessFromEntity(report.Freshness())
+
+    return resp, nil
+}
+
+func (uc *statsUseCase) BatchStats(ctx context.Context, req *dto.BatchStatsRequest) (*dto.BatchStatsResponse, error) {
+    from := time.Unix(req.TsFrom, 0)
+    to := time.Unix(req.TsTo, 0)
+
+    if from.After(to) {
+        return nil, fmt.Errorf("%w: from is after to", ErrInvalidArgument)
+    }
+    bannerIDs, err := uniqueBannerIDs(req.BannerIDs)
+    if err != nil {
+        return nil, err
+    }
+
+    log.Printf("Getting stats for %d banners from %v to %v", len(bannerIDs), from, to)
+
+    ctx, report := freshness.NewContext(ctx)
+    totals, err := uc.repo.GetTotals(ctx, bannerIDs, from, to)
+    if err != nil {
+        log.Printf("Error getting batch stats: %v", err)
+        return nil, err
+    }
+
+    resp := &dto.BatchStatsResponse{
+        Banners:   make([]*dto.BannerClicks, 0, len(bannerIDs)),
+        Freshness: dto.FreshnessFromEntity(report.Freshness()),
+    }
+    for _, id := range bannerIDs {
+        resp.TotalClicks += totals[id]
+        resp.Banners = append(resp.Banners, &dto.BannerClicks{
+            BannerID:    id,
+            TotalClicks: totals[id],
+        })
+    }
+
+    return resp, nil
+}
+
+// uniqueBannerIDs drops repeated IDs, which would otherwise be counted
+// twice in the grand total, and enforces the per-request limit.
+func uniqueBannerIDs(ids []int64) ([]int64, error) {
+    if len(ids) == 0 {
+        return nil, fmt.Errorf("%w: no banner ids", ErrInvalidArgument)
+    }
+
+    seen := make(map[int64]bool, len(ids))
+    unique := make([]int64, 0, len(ids))
+    for _, id := range ids {
+        if !seen[id] {
+            seen[id] = true
+            unique = append(unique, id)
+        }
+    }
+    if len(unique) > maxBatchBanners {
+        return nil, fmt.Errorf("%w: at most %d banners per request", ErrInvalidArgument, maxBatchBanners)
+    }
+    return unique, nil
+}
+
+// CreateWatchToken issues a token that opens the stats stream of the given
+// banners without the API key.
+func (uc *statsUseCase) CreateWatchToken(ctx context.Context, req *dto.CreateWatchTokenRequest) (*dto.WatchToken, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+    bannerIDs, err := uniqueBannerIDs(req.BannerIDs)
+    if err != nil {
+        return nil, err
+    }
+
+    token, expires := uc.watch.Issue(tenantID, bannerIDs, time.Now())
+    return &dto.WatchToken{
+        Token:     token,
+        ExpiresAt: expires.Unix(),
     }, nil
 }
+
+// WatchStats sends the banners' totals since TsFrom (start of the UTC day
+// by default) and then the new totals after every flush that touched them,
+// until ctx is done. The feed is subscribed before the snapshot is read, so
+// no flush is missed, but one flushed during the read may be counted twice.
+func (uc *statsUseCase) WatchStats(ctx context.Context, req *dto.WatchStatsRequest, send func(*dto.StatsUpdate) error) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+    bannerIDs, err := uniqueBannerIDs(req.BannerIDs)
+    if err != nil {
+        return err
+    }
+
+    now := time.Now()
+    from := time.Unix(req.TsFrom, 0)
+    if req.TsFrom == 0 {
+        from = timeseries.Truncate(now, timeseries.Day, time.UTC)
+    }
+    if from.After(now) {
+        return fmt.Errorf("%w: from is in the future", ErrInvalidArgument)
+    }
+
+    updates, err := uc.feed.Subscribe(ctx, tenantID)
+    if err != nil {
+        return fmt.Errorf("failed to subscribe to click updates: %w", err)
+    }
+
+    totals, err := uc.repo.GetTotals(ctx, bannerIDs, from, now)
+    if err != nil {
+        return fmt.Errorf("failed to get totals: %w", err)
+    }
+
+    watched := make(map[int64]bool, len(bannerIDs))
+    snapshot := &dto.StatsUpdate{
+        Snapshot: true,
+        Ts:       now.Unix(),
+        Banners:  make([]*dto.BannerClicks, 0, len(bannerIDs)),
+    }
+    for _, id := range bannerIDs {
+        watched[id] = true
+        snapshot.Banners = append(snapshot.Banners, &dto.BannerClicks{BannerID: id, TotalClicks: totals[id]})
+    }
+    if err := send(snapshot); err != nil {
+        return err
+    }
+
+    for {
+        select {
+        case <-ctx.Done():
+            return nil
+        case batch, ok := <-updates:
+            if !ok {
+                return nil
+            }
+
+            update := &dto.StatsUpdate{Ts: time.Now().Unix()}
+            for _, u := range batch {
+                if !watched[u.BannerID] {
+                    continue
+                }
+                totals[u.BannerID] += u.Clicks
+                update.Banners = append(update.Banners, &dto.BannerClicks{
+                    BannerID:    u.BannerID,
+                    TotalClicks: totals[u.BannerID],
+                })
+            }
+            if len(update.Banners) == 0 {
+                continue
+            }
+            if err := send(update); err != nil {
+                return err
+            }
+        }
+    }
+}
+
+func (uc *statsUseCase) TopBanners(ctx context.Context, req *dto.TopBannersRequest) (*dto.TopBannersResponse, error) {
+    from, to, err := topBannersRange(req)
+    if err != nil {
+        return nil, err
+    }
+
+    limit := req.Limit
+    if limit == 0 {
+        limit = defaultTopLimit
+    }
+    if limit < 0 || limit > maxTopLimit {
+        return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidArgument, maxTopLimit)
+    }
+
+    log.Printf("Getting top %d banners from %v to %v", limit, from, to)
+
+    ctx, report := freshness.NewContext(ctx)
+    top, err := uc.top.TopBanners(ctx, from, to, limit)
+    if err != nil {
+        log.Printf("Error getting top banners: %v", err)
+        return nil, err
+    }
+
+    resp := &dto.TopBannersResponse{
+        TsFrom:    from.Unix(),
+        TsTo:      to.Unix(),
+        Banners:   make([]*dto.BannerClicks, 0, len(top)),
+        Freshness: dto.FreshnessFromEntity(report.Freshness()),
+    }
+    for _, t := range top {
+        resp.Banners = append(resp.Banners, &dto.BannerClicks{
+            BannerID:    t.BannerID,
+            TotalClicks: t.Clicks,
+        })
+    }
+
+    return resp, nil
+}
+
+// topBannersRange resolves the leaderboard window: a duration ending now,
+// an explicit range, or the last hour when neither is given.
+func topBannersRange(req *dto.TopBannersRequest) (time.Time, time.Time, error) {
+    now := time.Now()
+
+    if req.Window != "" {
+        window, err := time.ParseDuration(req.Window)
+        if err != nil || window <= 0 {
+            return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid window %q", ErrInvalidArgument, req.Window)
+        }
+        return now.Add(-window), now, nil
+    }
+
+    if req.TsFrom == 0 && req.TsTo == 0 {
+        return now.Add(-defaultTopWindow), now, nil
+    }
+
+    from := time.Unix(req.TsFrom, 0)
+    to := time.Unix(req.TsTo, 0)
+    if !from.Before(to) {
+        return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be before to", ErrInvalidArgument)
+    }
+    return from, to, nil
+}
+
+func (uc *statsUseCase) CompareVariants(ctx context.Context, req *dto.CompareVariantsRequest) (*dto.CompareVariantsResponse, error) {
+    from := time.Unix(req.TsFrom, 0)
+    to := time.Unix(req.TsTo, 0)
+
+    if from.After(to) {
+        return nil, fmt.Errorf("%w: from is after to", ErrInvalidArgument)
+    }
+
+    level := req.ConfidenceLevel
+    if level == 0 {
+        level = defaultConfidenceLevel
+    }
+    if level <= 0 || level >= 1 {
+        return nil, fmt.Errorf("%w: confidence level must be between 0 and 1", ErrInvalidArgument)
+    }
+
+    variantStats, err := uc.variants.GetVariantStats(ctx, req.BannerID, from, to)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get variant stats: %w", err)
+    }
+
+    resp := &dto.CompareVariantsResponse{
+        ConfidenceLevel: level,
+        Variants:        make([]*dto.VariantComparison, 0, len(variantStats)),
+    }
+    if len(variantStats) == 0 {
+        return resp, nil
+    }
+
+    control := variantStats[0]
+    if req.ControlVariantID != 0 {
+        control = nil
+        for _, s := range variantStats {
+            if s.VariantID == req.ControlVariantID {
+                control = s
+                break
+            }
+        }
+        if control == nil {
+            return nil, fmt.Errorf("%w: control variant %d does not belong to banner %d",
+                ErrInvalidArgument, req.ControlVariantID, req.BannerID)
+        }
+    }
+    resp.ControlVariantID = control.VariantID
+
+    z := zForConfidence(level)
+    controlCTR := proportion(control.Clicks, control.Impressions)
+
+    for _, s := range variantStats {
+        lower, upper := wilsonInterval(s.Clicks, s.Impressions, z)
+        comparison := &dto.VariantComparison{
+            VariantID:   s.VariantID,
+            Name:        s.Name,
+            Weight:      s.Weight,
+            Impressions: s.Impressions,
+            Clicks:      s.Clicks,
+            CTR:         proportion(s.Clicks, s.Impressions),
+            CTRLower:    lower,
+            CTRUpper:    upper,
+            PValue:      1,
+        }
+
+        if s.VariantID != control.VariantID {
+            if controlCTR > 0 {
+                comparison.Lift = (comparison.CTR - controlCTR) / controlCTR
+            }
+            comparison.ZScore, comparison.PValue = twoProportionZTest(
+                control.Clicks, control.Impressions, s.Clicks, s.Impressions)
+            comparison.Significant = comparison.PValue < 1-level
+        }
+
+        resp.Variants = append(resp.Variants, comparison)
+    }
+
+    return resp, nil
+}
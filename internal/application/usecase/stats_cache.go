@@ -0,0 +1,84 @@
+package usecase
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    "clicker/internal/application/dto"
+    "clicker/internal/domain/tenant"
+)
+
+const (
+    // statsCacheTTL bounds how long a result of a quiet banner is kept.
+    // Busy banners move their watermark long before that.
+    statsCacheTTL = 10 * time.Minute
+    // statsComputeTimeout limits a query shared by coalesced callers, since
+    // it no longer follows the deadline of any one of them.
+    statsComputeTimeout = 30 * time.Second
+)
+
+// statsKey normalizes a stats request, so requests asking the same thing
+// share a cache entry however they spelled it.
+func statsKey(req *dto.StatsRequest, from, to time.Time, loc *time.Location) string {
+    return fmt.Sprintf("%d:%d:%s:%t:%s:%s",
+        from.Unix(), to.Unix(), req.Granularity, req.Cumulative, loc.String(), req.Compare)
+}
+
+// cachedStats serves stats from the cache, or computes them once for all
+// concurrent callers with the same request. A failing cache only costs the
+// caching, the stats are still computed.
+func (uc *statsUseCase) cachedStats(ctx context.Context, bannerID int64, key string,
+    compute func(context.Context) (*dto.StatsResponse, error)) (*dto.StatsResponse, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    watermark, err := uc.cache.Watermark(ctx, bannerID)
+    if err != nil {
+        log.Printf("Failed to get stats watermark of banner %d: %v", bannerID, err)
+        return compute(ctx)
+    }
+
+    flight := fmt.Sprintf("%d:%d:%d:%s", tenantID, bannerID, watermark, key)
+    result, err, _ := uc.flights.Do(flight, func() (any, error) {
+        // Запрос общий для всех ожидающих, поэтому не должен отменяться
+        // вместе с тем, кто пришёл первым.
+        ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statsComputeTimeout)
+        defer cancel()
+
+        data, err := uc.cache.Get(ctx, bannerID, watermark, key)
+        if err != nil {
+            log.Printf("Failed to read cached stats of banner %d: %v", bannerID, err)
+        }
+        if data != nil {
+            resp := &dto.StatsResponse{}
+            if err := json.Unmarshal(data, resp); err == nil {
+                return resp, nil
+            }
+            log.Printf("Failed to decode cached stats of banner %d: %v", bannerID, err)
+        }
+
+        resp, err := compute(ctx)
+        if err != nil {
+            return nil, err
+        }
+
+        data, err = json.Marshal(resp)
+        if err == nil {
+            err = uc.cache.Set(ctx, bannerID, watermark, key, data, statsCacheTTL)
+        }
+        if err != nil {
+            log.Printf("Failed to cache stats of banner %d: %v", bannerID, err)
+        }
+        return resp, nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return result.(*dto.StatsResponse), nil
+}
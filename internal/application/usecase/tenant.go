@@ -0,0 +1,155 @@
+package usecase
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+var (
+    ErrUnauthenticated = errors.New("unauthenticated")
+    ErrQuotaExceeded   = errors.New("quota exceeded")
+)
+
+const apiKeyPrefix = "ck_"
+
+type TenantUseCase interface {
+    Authenticate(ctx context.Context, apiKey string) (*entity.Tenant, error)
+    GetTenant(ctx context.Context, tenantID int64) (*entity.Tenant, error)
+    CreateTenant(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, string, error)
+    ListTenants(ctx context.Context) ([]*entity.Tenant, error)
+    UpdateQuota(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, error)
+    RotateKey(ctx context.Context, tenantID int64) (*entity.Tenant, string, error)
+}
+
+type tenantUseCase struct {
+    repo  repository.TenantRepository
+    cache *expirable.LRU[string, *entity.Tenant]
+}
+
+func NewTenantUseCase(repo repository.TenantRepository) TenantUseCase {
+    return &tenantUseCase{
+        repo:  repo,
+        cache: expirable.NewLRU[string, *entity.Tenant](10000, nil, time.Minute),
+    }
+}
+
+func (uc *tenantUseCase) Authenticate(ctx context.Context, apiKey string) (*entity.Tenant, error) {
+    if apiKey == "" {
+        return nil, fmt.Errorf("%w: api key is required", ErrUnauthenticated)
+    }
+
+    hash := hashAPIKey(apiKey)
+    if tenant, ok := uc.cache.Get(hash); ok {
+        return tenant, nil
+    }
+
+    tenant, err := uc.repo.GetByAPIKeyHash(ctx, hash)
+    if errors.Is(err, repository.ErrTenantNotFound) {
+        return nil, fmt.Errorf("%w: unknown api key", ErrUnauthenticated)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to resolve tenant: %w", err)
+    }
+
+    uc.cache.Add(hash, tenant)
+    return tenant, nil
+}
+
+// GetTenant resolves the tenant a signed token was issued for.
+func (uc *tenantUseCase) GetTenant(ctx context.Context, tenantID int64) (*entity.Tenant, error) {
+    tenant, err := uc.repo.GetByID(ctx, tenantID)
+    if errors.Is(err, repository.ErrTenantNotFound) {
+        return nil, fmt.Errorf("%w: unknown tenant", ErrUnauthenticated)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to resolve tenant: %w", err)
+    }
+    return tenant, nil
+}
+
+func (uc *tenantUseCase) CreateTenant(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, string, error) {
+    if tenant.Name == "" {
+        return nil, "", fmt.Errorf("%w: tenant name is required", ErrInvalidArgument)
+    }
+    if err := validateQuota(tenant); err != nil {
+        return nil, "", err
+    }
+
+    apiKey, err := generateAPIKey()
+    if err != nil {
+        return nil, "", err
+    }
+
+    if err := uc.repo.Create(ctx, tenant, hashAPIKey(apiKey)); err != nil {
+        return nil, "", fmt.Errorf("failed to create tenant: %w", err)
+    }
+
+    return tenant, apiKey, nil
+}
+
+func (uc *tenantUseCase) ListTenants(ctx context.Context) ([]*entity.Tenant, error) {
+    return uc.repo.List(ctx)
+}
+
+func (uc *tenantUseCase) UpdateQuota(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+    if err := validateQuota(tenant); err != nil {
+        return nil, err
+    }
+
+    if err := uc.repo.UpdateQuota(ctx, tenant); err != nil {
+        return nil, err
+    }
+
+    uc.cache.Purge()
+    return tenant, nil
+}
+
+// RotateKey issues a new API key and invalidates the old one. Other replicas
+// may keep accepting the old key until their tenant cache entry expires.
+func (uc *tenantUseCase) RotateKey(ctx context.Context, tenantID int64) (*entity.Tenant, string, error) {
+    apiKey, err := generateAPIKey()
+    if err != nil {
+        return nil, "", err
+    }
+
+    if err := uc.repo.SetAPIKeyHash(ctx, tenantID, hashAPIKey(apiKey)); err != nil {
+        return nil, "", err
+    }
+    uc.cache.Purge()
+
+    tenant, err := uc.repo.GetByID(ctx, tenantID)
+    if err != nil {
+        return nil, "", err
+    }
+
+    return tenant, apiKey, nil
+}
+
+func validateQuota(tenant *entity.Tenant) error {
+    if tenant.MaxBanners < 0 || tenant.MaxDailyClicks < 0 {
+        return fmt.Errorf("%w: quotas must not be negative", ErrInvalidArgument)
+    }
+    return nil
+}
+
+func generateAPIKey() (string, error) {
+    buf := make([]byte, 32)
+    if _, err := rand.Read(buf); err != nil {
+        return "", fmt.Errorf("failed to generate api key: %w", err)
+    }
+    return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashAPIKey(apiKey string) string {
+    sum := sha256.Sum256([]byte(apiKey))
+    return hex.EncodeToString(sum[:])
+}
@@ -29,11 +29,90 @@ type ServerConfig struct {
     Port string
 }
 
+type ServingConfig struct {
+    PublicURL string
+    // ClickLinkTTLHours is how long a tracking link handed out by
+    // ServeBanner counts clicks.
+    ClickLinkTTLHours int
+}
+
+type AuthConfig struct {
+    AdminToken string
+    // SigningKey signs tracking links and stats watch tokens. Every
+    // instance must share it, or tokens issued by one are rejected by
+    // another. When empty a random key is used and tokens stop working on
+    // restart.
+    SigningKey string
+}
+
+// PartitionConfig controls how the clicks table is partitioned.
+type PartitionConfig struct {
+    // Interval is the range of one partition: day or month, in UTC.
+    Interval string
+    // Premake is how many partitions to keep ahead of the current one.
+    Premake int
+    // Expire is what happens to partitions past the raw retention of
+    // RetentionConfig: detach leaves them as standalone tables, drop
+    // deletes them.
+    Expire string
+}
+
+// RetentionConfig says how long each resolution of clicks is kept. Daily
+// aggregates are kept forever.
+type RetentionConfig struct {
+    // RawDays is how long raw clicks are kept, 0 keeps them forever.
+    RawDays int
+    // HourlyMonths is how long hourly aggregates are kept, 0 keeps them
+    // forever.
+    HourlyMonths int
+}
+
+// ArchiveConfig controls moving old clicks partitions to compressed files.
+// New rejects a RetentionConfig.RawDays that would delete raw clicks before
+// their partition is archived.
+type ArchiveConfig struct {
+    // AfterDays is how long a partition stays in the database after its
+    // range ends, 0 turns the archival job off.
+    AfterDays int
+    // Dir holds the archives and their manifest.
+    Dir string
+}
+
+// Storage backends for clicks.
+const (
+    // StoragePostgres keeps clicks in Postgres, with the last day also in
+    // Redis.
+    StoragePostgres = "postgres"
+    // StorageMemory keeps everything in process memory, for development:
+    // neither Postgres nor Redis is used, and all data is lost on restart.
+    // There is no retention, archiving or click cache to manage.
+    StorageMemory = "memory"
+    // StorageEmbedded keeps clicks, their stats and the tenants, banners
+    // and variants they belong to in a bbolt file at StorageConfig.Path,
+    // with the retention of RetentionConfig. Neither Postgres nor Redis is
+    // used; daily click quotas start over on restart. Only one process
+    // may use the file.
+    StorageEmbedded = "embedded"
+)
+
+// StorageConfig selects where clicks are stored.
+type StorageConfig struct {
+    Backend string
+    // Path is the file of the embedded backend.
+    Path string
+}
+
 type Config struct {
-    Postgres PostgresConfig
-    Redis    RedisConfig
-    Rest     ServerConfig
-    Grpc     ServerConfig
+    Postgres  PostgresConfig
+    Redis     RedisConfig
+    Rest      ServerConfig
+    Grpc      ServerConfig
+    Serving   ServingConfig
+    Auth      AuthConfig
+    Partition PartitionConfig
+    Retention RetentionConfig
+    Archive   ArchiveConfig
+    Storage   StorageConfig
 }
 
 func New() (*Config, error) {
@@ -41,6 +120,79 @@ func New() (*Config, error) {
         return nil, fmt.Errorf("error loading .env file: %w", err)
     }
 
+    partition := PartitionConfig{
+        Interval: getEnv("CLICKS_PARTITION_INTERVAL", "month"),
+        Premake:  getEnvAsInt("CLICKS_PARTITION_PREMAKE", 2),
+        Expire:   getEnv("CLICKS_PARTITION_EXPIRE", "detach"),
+    }
+    if partition.Interval != "day" && partition.Interval != "month" {
+        return nil, fmt.Errorf("CLICKS_PARTITION_INTERVAL must be day or month, got %q", partition.Interval)
+    }
+    if partition.Expire != "detach" && partition.Expire != "drop" {
+        return nil, fmt.Errorf("CLICKS_PARTITION_EXPIRE must be detach or drop, got %q", partition.Expire)
+    }
+
+    retention := RetentionConfig{
+        RawDays:      getEnvAsInt("CLICKS_RETENTION_RAW_DAYS", 0),
+        HourlyMonths: getEnvAsInt("CLICKS_RETENTION_HOURLY_MONTHS", 0),
+    }
+    if retention.RawDays < 0 || retention.HourlyMonths < 0 {
+        return nil, fmt.Errorf("CLICKS_RETENTION_RAW_DAYS and CLICKS_RETENTION_HOURLY_MONTHS must not be negative")
+    }
+    // Раньше секции удалялись по своему сроку, мимо горизонтов retention.
+    // Старая настройка теперь лишь задаёт срок сырых кликов.
+    if days := getEnvAsInt("CLICKS_PARTITION_RETENTION_DAYS", 0); days != 0 {
+        if days < 0 {
+            return nil, fmt.Errorf("CLICKS_PARTITION_RETENTION_DAYS must not be negative, got %d", days)
+        }
+        if retention.RawDays != 0 && retention.RawDays != days {
+            return nil, fmt.Errorf("CLICKS_PARTITION_RETENTION_DAYS=%d conflicts with CLICKS_RETENTION_RAW_DAYS=%d, set only the latter",
+                days, retention.RawDays)
+        }
+        retention.RawDays = days
+    }
+
+    archive := ArchiveConfig{
+        AfterDays: getEnvAsInt("CLICKS_ARCHIVE_AFTER_DAYS", 0),
+        Dir:       getEnv("CLICKS_ARCHIVE_DIR", "archive"),
+    }
+    if archive.AfterDays < 0 {
+        return nil, fmt.Errorf("CLICKS_ARCHIVE_AFTER_DAYS must not be negative, got %d", archive.AfterDays)
+    }
+
+    serving := ServingConfig{
+        PublicURL:         getEnv("PUBLIC_URL", "http://localhost:8080"),
+        ClickLinkTTLHours: getEnvAsInt("CLICK_LINK_TTL_HOURS", 24),
+    }
+    if serving.ClickLinkTTLHours <= 0 {
+        return nil, fmt.Errorf("CLICK_LINK_TTL_HOURS must be positive, got %d", serving.ClickLinkTTLHours)
+    }
+
+    storage := StorageConfig{
+        Backend: getEnv("STORAGE_BACKEND", StoragePostgres),
+        Path:    getEnv("STORAGE_PATH", "clicker.db"),
+    }
+    switch storage.Backend {
+    case StoragePostgres, StorageMemory, StorageEmbedded:
+    default:
+        return nil, fmt.Errorf("STORAGE_BACKEND must be %s, %s or %s, got %q",
+            StoragePostgres, StorageMemory, StorageEmbedded, storage.Backend)
+    }
+    // Архивируются секции Postgres, с другими хранилищами это бессмысленно.
+    if archive.AfterDays > 0 && storage.Backend != StoragePostgres {
+        return nil, fmt.Errorf("CLICKS_ARCHIVE_AFTER_DAYS needs STORAGE_BACKEND=%s", StoragePostgres)
+    }
+    // Секция архивируется целиком, когда закончилась AfterDays назад, а
+    // retention удаляет строки и из секции на горизонте. Сырые клики должны
+    // жить дольше, чем архивация плюс длина секции и сутки на запуски задач.
+    if archive.AfterDays > 0 && retention.RawDays > 0 {
+        minRawDays := archive.AfterDays + partitionDays(partition.Interval) + 1
+        if retention.RawDays < minRawDays {
+            return nil, fmt.Errorf("CLICKS_RETENTION_RAW_DAYS must be at least %d with CLICKS_ARCHIVE_AFTER_DAYS=%d and %s partitions, got %d",
+                minRawDays, archive.AfterDays, partition.Interval, retention.RawDays)
+        }
+    }
+
     return &Config{
         Postgres: PostgresConfig{
             Host:     getEnv("POSTGRES_HOST", "localhost"),
@@ -64,9 +216,26 @@ func New() (*Config, error) {
             Host: getEnv("GRPC_HOST", "0.0.0.0"),
             Port: getEnv("GRPC_PORT", "50051"),
         },
+        Serving: serving,
+        Auth: AuthConfig{
+            AdminToken: getEnv("ADMIN_TOKEN", ""),
+            SigningKey: getEnv("SIGNING_KEY", ""),
+        },
+        Partition: partition,
+        Retention: retention,
+        Archive:   archive,
+        Storage:   storage,
     }, nil
 }
 
+// partitionDays is the longest a partition of the interval can be.
+func partitionDays(interval string) int {
+    if interval == "day" {
+        return 1
+    }
+    return 31
+}
+
 func (c *Config) GetPostgresDSN() string {
     if c.Postgres.DSN != "" {
         return c.Postgres.DSN
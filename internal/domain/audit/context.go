@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+// UnknownActor is recorded when a change is made outside of an
+// authenticated request, e.g. by a maintenance command.
+const UnknownActor = "system"
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, actor string) context.Context {
+    return context.WithValue(ctx, contextKey{}, actor)
+}
+
+// Actor returns who the request is made by, for the change history.
+func Actor(ctx context.Context) string {
+    actor, ok := ctx.Value(contextKey{}).(string)
+    if !ok || actor == "" {
+        return UnknownActor
+    }
+    return actor
+}
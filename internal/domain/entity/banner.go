@@ -1,6 +1,19 @@
 package entity
 
+const (
+    BannerStatusActive = "active"
+    BannerStatusPaused = "paused"
+)
+
 type Banner struct {
-    ID   int64  `json:"id"`
-    Name string `json:"name"`
+    ID                 int64             `json:"id"`
+    TenantID           int64             `json:"tenant_id"`
+    Name               string            `json:"name"`
+    URL                string            `json:"url"`
+    CreativeURL        string            `json:"creative_url"`
+    Placement          string            `json:"placement"`
+    Status             string            `json:"status"`
+    Weight             int               `json:"weight"`
+    DailyImpressionCap int               `json:"daily_impression_cap"`
+    Labels             map[string]string `json:"labels,omitempty"`
 }
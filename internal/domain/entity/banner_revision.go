@@ -0,0 +1,74 @@
+package entity
+
+import (
+    "strconv"
+    "time"
+)
+
+const (
+    RevisionActionCreate        = "create"
+    RevisionActionUpdate        = "update"
+    RevisionActionDelete        = "delete"
+    RevisionActionVariantCreate = "variant_create"
+    RevisionActionVariantUpdate = "variant_update"
+)
+
+type FieldChange struct {
+    Field string `json:"field"`
+    Old   string `json:"old"`
+    New   string `json:"new"`
+}
+
+// BannerRevision is one entry of the banner change history. VariantID is
+// set when the change was made to one of the banner's variants.
+type BannerRevision struct {
+    ID        int64         `json:"id"`
+    TenantID  int64         `json:"tenant_id"`
+    BannerID  int64         `json:"banner_id"`
+    VariantID int64         `json:"variant_id"`
+    Action    string        `json:"action"`
+    Actor     string        `json:"actor"`
+    Changes   []FieldChange `json:"changes"`
+    CreatedAt time.Time     `json:"created_at"`
+}
+
+// DiffBanner lists the audited fields that differ between two versions of
+// a banner. A nil before is treated as an empty banner.
+func DiffBanner(before, after *Banner) []FieldChange {
+    var oldName, oldURL, oldStatus, oldWeight string
+    if before != nil {
+        oldName = before.Name
+        oldURL = before.URL
+        oldStatus = before.Status
+        oldWeight = strconv.Itoa(before.Weight)
+    }
+
+    var changes []FieldChange
+    changes = appendChange(changes, "name", oldName, after.Name)
+    changes = appendChange(changes, "url", oldURL, after.URL)
+    changes = appendChange(changes, "status", oldStatus, after.Status)
+    changes = appendChange(changes, "weight", oldWeight, strconv.Itoa(after.Weight))
+    return changes
+}
+
+// DiffVariant lists the audited fields that differ between two versions of
+// a variant. A nil before is treated as an empty variant.
+func DiffVariant(before, after *Variant) []FieldChange {
+    var oldName, oldWeight string
+    if before != nil {
+        oldName = before.Name
+        oldWeight = strconv.Itoa(before.Weight)
+    }
+
+    var changes []FieldChange
+    changes = appendChange(changes, "name", oldName, after.Name)
+    changes = appendChange(changes, "weight", oldWeight, strconv.Itoa(after.Weight))
+    return changes
+}
+
+func appendChange(changes []FieldChange, field, old, new string) []FieldChange {
+    if old == new {
+        return changes
+    }
+    return append(changes, FieldChange{Field: field, Old: old, New: new})
+}
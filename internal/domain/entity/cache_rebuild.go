@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+const (
+    CacheRebuildRunning = "running"
+    CacheRebuildDone    = "done"
+    CacheRebuildFailed  = "failed"
+)
+
+// CacheRebuild is the progress of rebuilding the recent clicks in Redis
+// from Postgres. It lives in Redis itself, so it disappears together with
+// the data when Redis is emptied.
+type CacheRebuild struct {
+    // State is empty when Redis has not been rebuilt since it was emptied.
+    State      string    `json:"state"`
+    StartedAt  time.Time `json:"started_at"`
+    FinishedAt time.Time `json:"finished_at,omitempty"`
+    From       time.Time `json:"from"`
+    To         time.Time `json:"to"`
+    // Hours is the number of banner hours with clicks in [From, To).
+    Hours     int64  `json:"hours"`
+    HoursDone int64  `json:"hours_done"`
+    Error     string `json:"error,omitempty"`
+}
@@ -4,7 +4,26 @@ import "time"
 
 type Click struct {
     ID        int64     `json:"id"`
+    TenantID  int64     `json:"tenant_id"`
     BannerID  int64     `json:"banner_id"`
+    VariantID int64     `json:"variant_id,omitempty"`
+    // ClickerID identifies who clicked, for unique clicker counts. It is
+    // not stored with the click itself.
+    ClickerID string    `json:"-"`
     Timestamp time.Time `json:"timestamp"`
     Count     int       `json:"count"`
 }
+
+// BannerTotal is the number of clicks a banner got over some period.
+type BannerTotal struct {
+    BannerID int64 `json:"banner_id"`
+    Clicks   int64 `json:"clicks"`
+}
+
+// ClickUpdate is the number of clicks a banner got in one flushed batch.
+type ClickUpdate struct {
+    TenantID  int64     `json:"tenant_id"`
+    BannerID  int64     `json:"banner_id"`
+    Clicks    int64     `json:"clicks"`
+    Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// ClickArchive is a clicks partition moved out of the database into a
+// compressed file.
+type ClickArchive struct {
+    Partition  string    `json:"partition"`
+    From       time.Time `json:"from"`
+    To         time.Time `json:"to"`
+    File       string    `json:"file"`
+    Rows       int64     `json:"rows"`
+    Bytes      int64     `json:"bytes"`
+    SHA256     string    `json:"sha256"`
+    ArchivedAt time.Time `json:"archived_at"`
+}
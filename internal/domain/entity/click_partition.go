@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+// ClickPartition is a partition of the clicks table holding [From, To).
+type ClickPartition struct {
+    Name string    `json:"name"`
+    From time.Time `json:"from"`
+    To   time.Time `json:"to"`
+}
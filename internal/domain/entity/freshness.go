@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// Tiers of click data, finest first.
+const (
+    TierCache  = "cache"
+    TierRaw    = "raw"
+    TierHourly = "hourly"
+    TierDaily  = "daily"
+)
+
+// DataSource is a part of a queried range answered by one tier.
+type DataSource struct {
+    Tier string    `json:"tier"`
+    From time.Time `json:"from"`
+    To   time.Time `json:"to"`
+}
+
+// Freshness says when an answer was read and which tiers it came from.
+// A cached answer keeps the AsOf of the moment it was computed.
+type Freshness struct {
+    AsOf    time.Time     `json:"as_of"`
+    Sources []*DataSource `json:"sources,omitempty"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+type Impression struct {
+    ID        int64     `json:"id"`
+    TenantID  int64     `json:"tenant_id"`
+    BannerID  int64     `json:"banner_id"`
+    VariantID int64     `json:"variant_id,omitempty"`
+    Timestamp time.Time `json:"timestamp"`
+    Count     int       `json:"count"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// HourCount is the number of clicks of a banner in one hour.
+type HourCount struct {
+    TenantID int64     `json:"tenant_id"`
+    BannerID int64     `json:"banner_id"`
+    Hour     time.Time `json:"hour"`
+    Clicks   int64     `json:"clicks"`
+}
+
+// HourDrift is an hour where the Redis counter disagrees with Postgres.
+type HourDrift struct {
+    TenantID int64     `json:"tenant_id"`
+    BannerID int64     `json:"banner_id"`
+    Hour     time.Time `json:"hour"`
+    Postgres int64     `json:"postgres"`
+    Redis    int64     `json:"redis"`
+}
+
+// ReconcileReport is one pass of Redis/Postgres reconciliation over the
+// hours in [From, To).
+type ReconcileReport struct {
+    From    time.Time    `json:"from"`
+    To      time.Time    `json:"to"`
+    Checked int          `json:"checked"`
+    Drifted []*HourDrift `json:"drifted,omitempty"`
+    // Repaired is how many of the drifted hours were rewritten from
+    // Postgres. It is zero on a dry run.
+    Repaired int `json:"repaired"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// RetentionRun is one pass of the retention job.
+type RetentionRun struct {
+    ID         int64     `json:"id"`
+    StartedAt  time.Time `json:"started_at"`
+    FinishedAt time.Time `json:"finished_at"`
+    // Raw and Hourly are nil when the tier is kept forever or the run
+    // failed before reaching it.
+    Raw    *TierExpiry `json:"raw,omitempty"`
+    Hourly *TierExpiry `json:"hourly,omitempty"`
+    Error  string      `json:"error,omitempty"`
+}
+
+// TierExpiry is what a retention run removed from one tier.
+type TierExpiry struct {
+    // Horizon is where the tier starts now. It never moves past the
+    // watermark of the tier above, so nothing is deleted before it is
+    // rolled up.
+    Horizon time.Time `json:"horizon"`
+    Deleted int64     `json:"deleted"`
+    // PartitionsDropped counts whole raw partitions dropped, or detached
+    // with CLICKS_PARTITION_EXPIRE=detach. Their rows are not in Deleted.
+    PartitionsDropped int `json:"partitions_dropped,omitempty"`
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type Tenant struct {
+    ID             int64     `json:"id"`
+    Name           string    `json:"name"`
+    MaxBanners     int       `json:"max_banners"`
+    MaxDailyClicks int64     `json:"max_daily_clicks"`
+    CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+type Variant struct {
+    ID          int64  `json:"id"`
+    BannerID    int64  `json:"banner_id"`
+    Name        string `json:"name"`
+    CreativeURL string `json:"creative_url"`
+    Weight      int    `json:"weight"`
+}
+
+type VariantStats struct {
+    VariantID   int64  `json:"variant_id"`
+    Name        string `json:"name"`
+    Weight      int    `json:"weight"`
+    Impressions int64  `json:"impressions"`
+    Clicks      int64  `json:"clicks"`
+}
@@ -0,0 +1,73 @@
+// Package freshness collects which tiers answered the click queries made
+// with a context, so a response can tell where its numbers came from.
+package freshness
+
+import (
+    "context"
+    "sort"
+    "sync"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+type contextKey struct{}
+
+// Report is safe for the concurrent reads of one request.
+type Report struct {
+    mu      sync.Mutex
+    asOf    time.Time
+    sources []*entity.DataSource
+}
+
+// NewContext starts a report. Queries made with the returned context record
+// into it, queries made without one record nowhere.
+func NewContext(ctx context.Context) (context.Context, *Report) {
+    r := &Report{asOf: time.Now()}
+    return context.WithValue(ctx, contextKey{}, r), r
+}
+
+// Record notes that tier answered [from, to). Repositories call it for every
+// part of a range they read, empty parts are skipped.
+func Record(ctx context.Context, tier string, from, to time.Time) {
+    r, ok := ctx.Value(contextKey{}).(*Report)
+    if !ok || !from.Before(to) {
+        return
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.sources = append(r.sources, &entity.DataSource{Tier: tier, From: from, To: to})
+}
+
+// Freshness returns the sources by start, with touching or overlapping
+// parts of the same tier joined.
+func (r *Report) Freshness() *entity.Freshness {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    sources := make([]*entity.DataSource, len(r.sources))
+    for i, s := range r.sources {
+        copied := *s
+        sources[i] = &copied
+    }
+    sort.SliceStable(sources, func(i, j int) bool {
+        return sources[i].From.Before(sources[j].From)
+    })
+
+    var joined []*entity.DataSource
+    for _, s := range sources {
+        if n := len(joined); n > 0 {
+            last := joined[n-1]
+            if last.Tier == s.Tier && !s.From.After(last.To) {
+                if s.To.After(last.To) {
+                    last.To = s.To
+                }
+                continue
+            }
+        }
+        joined = append(joined, s)
+    }
+
+    return &entity.Freshness{AsOf: r.asOf, Sources: joined}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+    "context"
+    "errors"
+
+    "clicker/internal/domain/entity"
+)
+
+var ErrBannerNotFound = errors.New("banner not found")
+
+// BannerRepository records a revision for every create, update and delete.
+// Deleted banners are kept for their stats but are not found by ID.
+type BannerRepository interface {
+    Create(ctx context.Context, banner *entity.Banner) error
+    Update(ctx context.Context, banner *entity.Banner) error
+    // Delete soft-deletes the banner. Its clicks and impressions are kept.
+    Delete(ctx context.Context, bannerID int64) error
+    GetByID(ctx context.Context, bannerID int64) (*entity.Banner, error)
+    SetLabels(ctx context.Context, bannerID int64, labels map[string]string) error
+    // FindByLabels also returns deleted banners, so label stats over past
+    // periods still include them.
+    FindByLabels(ctx context.Context, selector map[string]string) ([]*entity.Banner, error)
+    // FindServable returns active banners of the placement that have not
+    // reached their daily impression cap yet.
+    FindServable(ctx context.Context, placement string) ([]*entity.Banner, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+    "context"
+
+    "clicker/internal/domain/entity"
+)
+
+type BannerRevisionRepository interface {
+    // ListByBanner returns the history of the banner, deleted or not,
+    // oldest revision first.
+    ListByBanner(ctx context.Context, bannerID int64) ([]*entity.BannerRevision, error)
+}
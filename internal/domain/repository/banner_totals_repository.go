@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// BannerTotalsRepository reads lifetime click counts, which are kept up to
+// date as clicks are saved instead of being summed over the whole history.
+type BannerTotalsRepository interface {
+    // GetTotal returns 0 for a banner that never got a click.
+    GetTotal(ctx context.Context, bannerID int64) (int64, error)
+}
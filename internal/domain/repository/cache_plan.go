@@ -0,0 +1,26 @@
+package repository
+
+import (
+    "context"
+    "log"
+    "time"
+)
+
+// cacheSplit plans a read of [from, to) by where the cache really is
+// complete: the store answers [from, split) and the cache [split, to).
+// When the coverage is unknown, everything is read from the store.
+func cacheSplit(ctx context.Context, coverage ClickCoverage, from, to time.Time) time.Time {
+    covered, err := coverage.CoveredFrom(ctx)
+    if err != nil {
+        log.Printf("Failed to get click cache coverage, reading Postgres only: %v", err)
+        return to
+    }
+
+    switch {
+    case covered.Before(from):
+        return from
+    case covered.After(to):
+        return to
+    }
+    return covered
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+)
+
+// fixedCoverage answers CoveredFrom with a fixed time or error.
+type fixedCoverage struct {
+    ClickCoverage
+    from time.Time
+    err  error
+}
+
+func (c *fixedCoverage) CoveredFrom(ctx context.Context) (time.Time, error) {
+    return c.from, c.err
+}
+
+func TestCacheSplit(t *testing.T) {
+    from := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+    to := from.Add(6 * time.Hour)
+
+    tests := []struct {
+        name     string
+        coverage *fixedCoverage
+        want     time.Time
+    }{
+        {"covered before the range", &fixedCoverage{from: from.Add(-time.Hour)}, from},
+        {"covered from the start", &fixedCoverage{from: from}, from},
+        {"covered from inside", &fixedCoverage{from: from.Add(2*time.Hour + 30*time.Second)}, from.Add(2*time.Hour + 30*time.Second)},
+        {"covered from the end", &fixedCoverage{from: to}, to},
+        {"covered after the range", &fixedCoverage{from: to.Add(time.Hour)}, to},
+        // Неизвестное покрытие: всё читается из хранилища.
+        {"coverage unknown", &fixedCoverage{err: errors.New("redis is down")}, to},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := cacheSplit(context.Background(), tt.coverage, from, to); !got.Equal(tt.want) {
+                t.Errorf("cacheSplit = %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
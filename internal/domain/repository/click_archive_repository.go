@@ -0,0 +1,34 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+// ClickArchiveRepository reads clicks partitions for archiving and loads
+// archived clicks back for re-analysis. Restored clicks go to a separate
+// table, so they never count in stats again.
+type ClickArchiveRepository interface {
+    // ReadPartition calls fn for every click of the partition, across all
+    // tenants.
+    ReadPartition(ctx context.Context, name string, fn func(*entity.Click) error) error
+    // ClearRestored deletes restored clicks in [from, to), so restoring a
+    // range twice does not duplicate it.
+    ClearRestored(ctx context.Context, from, to time.Time) error
+    SaveRestored(ctx context.Context, clicks []*entity.Click) error
+}
+
+// ClickArchiveStore keeps archived partitions with a manifest of them.
+type ClickArchiveStore interface {
+    // List returns the manifest ordered by range.
+    List(ctx context.Context) ([]*entity.ClickArchive, error)
+    // Write archives the clicks read passes to its callback and records the
+    // partition in the manifest, replacing an earlier archive of it.
+    Write(ctx context.Context, partition *entity.ClickPartition,
+        read func(fn func(*entity.Click) error) error) (*entity.ClickArchive, error)
+    // Read checks the archive against the manifest and calls fn for each of
+    // its clicks.
+    Read(ctx context.Context, archive *entity.ClickArchive, fn func(*entity.Click) error) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+    "context"
+    "time"
+)
+
+// ClickCoverage is the data-availability watermark of the click cache: from
+// when on the cache holds every click the store has. It moves forward past
+// clicks the cache missed, and back when the cache is rewritten from the
+// store.
+type ClickCoverage interface {
+    // CoveredFrom is never before the oldest data the cache still keeps.
+    // It is now when the cache holds no complete range at all.
+    CoveredFrom(ctx context.Context) (time.Time, error)
+    // MarkGap moves the coverage up to until, after the cache missed
+    // clicks before it.
+    MarkGap(ctx context.Context, until time.Time) error
+    // Claim is called before the cache is rewritten from the store, and
+    // Restore after: if no gap was marked and the cache was not emptied
+    // in between, and the coverage starts no later than to, it moves back
+    // to from. Restore reports whether it did.
+    Claim(ctx context.Context) (token int64, err error)
+    Restore(ctx context.Context, token int64, from, to time.Time) (bool, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+// ClickExportRepository streams clicks for bulk export. Rows are handed to
+// fn one at a time as they are read, so memory does not grow with the range.
+// Returning an error from fn stops the export. With restored set both read
+// the clicks restored from the archive instead of the live ones.
+type ClickExportRepository interface {
+    // ExportClicks yields stored click rows ordered by time.
+    ExportClicks(ctx context.Context, bannerIDs []int64, from, to time.Time, restored bool,
+        fn func(*entity.Click) error) error
+    // ExportSeries yields one row per banner and non-empty bucket, ordered
+    // by banner and bucket, with Timestamp set to the bucket start.
+    ExportSeries(ctx context.Context, bannerIDs []int64, from, to time.Time, granularity string,
+        loc *time.Location, restored bool, fn func(*entity.Click) error) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+    "context"
+
+    "clicker/internal/domain/entity"
+)
+
+// ClickFeed broadcasts flushed clicks to every replica, so live stats see
+// clicks no matter which replica counted them.
+type ClickFeed interface {
+    Publish(ctx context.Context, updates []*entity.ClickUpdate) error
+    // Subscribe delivers the tenant's updates until ctx is done, then
+    // closes the channel. A subscriber that falls behind loses updates.
+    Subscribe(ctx context.Context, tenantID int64) (<-chan []*entity.ClickUpdate, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+// ClickPartitionRepository manages the time range partitions of clicks.
+// Partitions are shared by all tenants.
+type ClickPartitionRepository interface {
+    // List returns the attached partitions ordered by range.
+    List(ctx context.Context) ([]*entity.ClickPartition, error)
+    // Create adds a partition for [from, to), which must be a whole UTC day
+    // or month, and moves the clicks of that range out of the default
+    // partition into it. It does nothing if the partition already exists.
+    Create(ctx context.Context, from, to time.Time) error
+    // StrayDays returns the UTC days of the clicks that fell into the
+    // default partition because no partition covered them.
+    StrayDays(ctx context.Context) ([]time.Time, error)
+    // Detach keeps the partition as a standalone table outside clicks.
+    Detach(ctx context.Context, name string) error
+    Drop(ctx context.Context, name string) error
+    // TryLock makes sure only one replica maintains partitions at a time.
+    // It returns false when another replica holds the lock.
+    TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+}
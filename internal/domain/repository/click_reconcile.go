@@ -0,0 +1,48 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/pkg/hll"
+)
+
+// ClickHourSource reads the clicks of all tenants from the source of truth
+// in the shape the Redis counters keep them.
+type ClickHourSource interface {
+    // HourCounts returns the hours in [from, to) with at least one click,
+    // per banner.
+    HourCounts(ctx context.Context, from, to time.Time) ([]*entity.HourCount, error)
+    // SecondCounts returns the per-second clicks of a banner in the hour
+    // starting at hour.
+    SecondCounts(ctx context.Context, tenantID, bannerID int64, hour time.Time) ([]*entity.Click, error)
+    // HourSketch returns the unique clickers of a banner in the hour
+    // starting at hour, nil when the hour has none.
+    HourSketch(ctx context.Context, tenantID, bannerID int64, hour time.Time) (*hll.Sketch, error)
+    // TryLock makes sure only one replica reconciles at a time.
+    TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+}
+
+// ClickHourCache is the Redis side of reconciliation. Tenants are passed
+// explicitly because a pass covers all of them.
+type ClickHourCache interface {
+    // HourCounts returns the cached number of clicks for each of the hours,
+    // in the same order.
+    HourCounts(ctx context.Context, hours []*entity.HourCount) ([]int64, error)
+    // ReplaceHour overwrites the counters and leaderboard scores of a banner
+    // for one hour with the given per-second clicks. Clicks counted while
+    // it runs are lost, so it is meant for hours that have ended.
+    ReplaceHour(ctx context.Context, tenantID, bannerID int64, hour time.Time, clicks []*entity.Click) error
+    // MergeHour raises every second of a banner hour to at least the given
+    // count and recomputes its leaderboard scores. Clicks counted while it
+    // runs are kept, so it is safe for the current hour.
+    MergeHour(ctx context.Context, tenantID, bannerID int64, hour time.Time, clicks []*entity.Click) error
+    // MergeSketch adds the unique clickers of a banner hour to the cached
+    // ones.
+    MergeSketch(ctx context.Context, tenantID, bannerID int64, hour time.Time, sketch *hll.Sketch) error
+    // Rebuild returns the progress of the latest rebuild, or nil when
+    // there has been none since the cache was emptied.
+    Rebuild(ctx context.Context) (*entity.CacheRebuild, error)
+    SaveRebuild(ctx context.Context, rebuild *entity.CacheRebuild) error
+}
@@ -3,7 +3,6 @@ package repository
 import (
     "context"
     "log"
-    "sync"
     "time"
     
     "clicker/internal/domain/entity"
@@ -12,12 +11,16 @@ import (
 type compositeClickRepository struct {
     postgres ClickRepository
     redis    ClickRepository
+    coverage ClickCoverage
 }
 
-func NewCompositeClickRepository(postgres, redis ClickRepository) ClickRepository {
+// NewCompositeClickRepository expects a coverage from NewPendingGapCoverage,
+// so a gap that fails to be recorded keeps reads off Redis until it is.
+func NewCompositeClickRepository(postgres, redis ClickRepository, coverage ClickCoverage) ClickRepository {
     return &compositeClickRepository{
         postgres: postgres,
         redis:    redis,
+        coverage: coverage,
     }
 }
 
@@ -28,43 +31,57 @@ func (r *compositeClickRepository) SaveBatch(ctx context.Context, clicks []*enti
 
     if err := r.redis.SaveBatch(ctx, clicks); err != nil {
         log.Printf("Failed to update Redis cache: %v", err)
+        markGap(ctx, r.coverage, clicks)
     }
 
     return nil
 }
 
-func (r *compositeClickRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
-    var (
-        redisClicks, pgClicks []*entity.Click
-        redisErr, pgErr       error
-        wg sync.WaitGroup
-    )
+// markGap moves the coverage past clicks Redis missed.
+func markGap(ctx context.Context, coverage ClickCoverage, clicks []*entity.Click) {
+    if len(clicks) == 0 {
+        return
+    }
+    if err := coverage.MarkGap(ctx, batchEnd(clicks)); err != nil {
+        log.Printf("Failed to record cache gap, reading Postgres only until it is: %v", err)
+    }
+}
+
+// batchEnd is the second right after the latest click of the batch.
+func batchEnd(clicks []*entity.Click) time.Time {
+    var latest time.Time
+    for _, click := range clicks {
+        if click.Timestamp.After(latest) {
+            latest = click.Timestamp
+        }
+    }
+    return latest.Truncate(time.Second).Add(time.Second)
+}
 
-    log.Printf("Fetching stats from Redis and Postgres for banner %d", bannerID)
+// GetStats reads the part of the range Redis fully covers from Redis and
+// the rest from Postgres. The Postgres rows come first, both are ordered.
+func (r *compositeClickRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
+    split := cacheSplit(ctx, r.coverage, from, to)
 
-    if to.After(time.Now().Add(-24 * time.Hour)) {
-        wg.Add(1)
-        go func() {
-            defer wg.Done()
-            redisClicks, redisErr = r.redis.GetStats(ctx, bannerID, from, to)
-            log.Printf("Redis returned %d clicks, error: %v", len(redisClicks), redisErr)
-        }()
+    var recent []*entity.Click
+    if split.Before(to) {
+        var err error
+        recent, err = r.redis.GetStats(ctx, bannerID, split, to)
+        if err != nil {
+            log.Printf("Failed to get clicks from Redis, using Postgres: %v", err)
+            recent, split = nil, to
+        }
     }
 
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        pgClicks, pgErr = r.postgres.GetStats(ctx, bannerID, from, to)
-        log.Printf("Postgres returned %d clicks, error: %v", len(pgClicks), pgErr)
-    }()
+    if !from.Before(split) {
+        return recent, nil
+    }
 
-    wg.Wait()
-    
-    if redisErr == nil && len(redisClicks) > 0 {
-        log.Printf("Using Redis data: %d clicks", len(redisClicks))
-        return redisClicks, nil
+    historical, err := r.postgres.GetStats(ctx, bannerID, from, split)
+    if err != nil {
+        return nil, err
     }
+    log.Printf("Got %d clicks from Postgres before %v and %d from Redis", len(historical), split, len(recent))
 
-    log.Printf("Using Postgres data: %d clicks", len(pgClicks))
-    return pgClicks, pgErr
+    return append(historical, recent...), nil
 }
@@ -7,47 +7,46 @@ import (
     "time"
     
     "clicker/internal/domain/entity"
+    "clicker/internal/domain/timeseries"
 )
 
 type compositeStatsRepository struct {
     postgres StatsRepository
     redis    StatsRepository
+    coverage ClickCoverage
 }
 
-func NewCompositeStatsRepository(postgres, redis StatsRepository) StatsRepository {
+func NewCompositeStatsRepository(postgres, redis StatsRepository, coverage ClickCoverage) StatsRepository {
     return &compositeStatsRepository{
         postgres: postgres,
         redis:    redis,
+        coverage: coverage,
     }
 }
 
+// Every read is split where Redis coverage starts. When Redis fails, its
+// part is read from Postgres as well, so the numbers are never short.
+
 func (r *compositeStatsRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
-    boundaryTime := time.Now().Add(-24 * time.Hour)
-    log.Printf("Getting stats with boundary time: %v", boundaryTime)
+    split := cacheSplit(ctx, r.coverage, from, to)
+    log.Printf("Getting stats with Redis covering from %v", split)
 
     var recentClicks, historicalClicks []*entity.Click
     var err error
 
-    if to.After(boundaryTime) {
-        recentFrom := from
-        if recentFrom.Before(boundaryTime) {
-            recentFrom = boundaryTime
-        }
-        log.Printf("Getting recent clicks from Redis for period: %v to %v", recentFrom, to)
-        recentClicks, err = r.redis.GetStats(ctx, bannerID, recentFrom, to)
+    if split.Before(to) {
+        log.Printf("Getting recent clicks from Redis for period: %v to %v", split, to)
+        recentClicks, err = r.redis.GetStats(ctx, bannerID, split, to)
         if err != nil {
-            log.Printf("Failed to get recent stats from Redis: %v", err)
+            log.Printf("Failed to get recent stats from Redis, using Postgres: %v", err)
+            recentClicks, split = nil, to
         }
         log.Printf("Got %d recent clicks from Redis", len(recentClicks))
     }
 
-    if from.Before(boundaryTime) {
-        historicalTo := to
-        if historicalTo.After(boundaryTime) {
-            historicalTo = boundaryTime
-        }
-        log.Printf("Getting historical clicks from Postgres for period: %v to %v", from, historicalTo)
-        historicalClicks, err = r.postgres.GetStats(ctx, bannerID, from, historicalTo)
+    if from.Before(split) {
+        log.Printf("Getting historical clicks from Postgres for period: %v to %v", from, split)
+        historicalClicks, err = r.postgres.GetStats(ctx, bannerID, from, split)
         if err != nil {
             log.Printf("Failed to get historical stats from Postgres: %v", err)
             return nil, err
@@ -60,6 +59,91 @@ func (r *compositeStatsRepository) GetStats(ctx context.Context, bannerID int64,
     return merged, nil
 }
 
+func (r *compositeStatsRepository) GetTotals(ctx context.Context, bannerIDs []int64, from, to time.Time) (map[int64]int64, error) {
+    split := cacheSplit(ctx, r.coverage, from, to)
+
+    totals := make(map[int64]int64, len(bannerIDs))
+
+    if split.Before(to) {
+        recent, err := r.redis.GetTotals(ctx, bannerIDs, split, to)
+        if err != nil {
+            log.Printf("Failed to get recent totals from Redis, using Postgres: %v", err)
+            recent, split = nil, to
+        }
+        for bannerID, count := range recent {
+            totals[bannerID] += count
+        }
+    }
+
+    if from.Before(split) {
+        historical, err := r.postgres.GetTotals(ctx, bannerIDs, from, split)
+        if err != nil {
+            log.Printf("Failed to get historical totals from Postgres: %v", err)
+            return nil, err
+        }
+        for bannerID, count := range historical {
+            totals[bannerID] += count
+        }
+    }
+
+    log.Printf("Got totals of %d banners out of %d requested", len(totals), len(bannerIDs))
+    return totals, nil
+}
+
+func (r *compositeStatsRepository) GetSeries(ctx context.Context, bannerID int64, from, to time.Time,
+    granularity string, loc *time.Location) ([]*entity.Click, error) {
+    split := cacheSplit(ctx, r.coverage, from, to)
+
+    var recent, historical []*entity.Click
+    var err error
+
+    if split.Before(to) {
+        recent, err = r.redis.GetSeries(ctx, bannerID, split, to, granularity, loc)
+        if err != nil {
+            log.Printf("Failed to get recent series from Redis, using Postgres: %v", err)
+            recent, split = nil, to
+        }
+    }
+
+    if from.Before(split) {
+        historical, err = r.postgres.GetSeries(ctx, bannerID, from, split, granularity, loc)
+        if err != nil {
+            log.Printf("Failed to get historical series from Postgres: %v", err)
+            return nil, err
+        }
+    }
+
+    return mergeBuckets(append(historical, recent...), granularity, loc), nil
+}
+
+// mergeBuckets sums rows falling into the same bucket. The bucket that
+// contains the Redis/Postgres split comes from both stores, and it is
+// only the same bucket when both are cut in the same location.
+func mergeBuckets(clicks []*entity.Click, granularity string, loc *time.Location) []*entity.Click {
+    merged := make(map[int64]*entity.Click)
+    for _, click := range clicks {
+        start := timeseries.Truncate(click.Timestamp, granularity, loc)
+        if existing, ok := merged[start.Unix()]; ok {
+            existing.Count += click.Count
+            continue
+        }
+        merged[start.Unix()] = &entity.Click{
+            BannerID:  click.BannerID,
+            Timestamp: start,
+            Count:     click.Count,
+        }
+    }
+
+    result := make([]*entity.Click, 0, len(merged))
+    for _, click := range merged {
+        result = append(result, click)
+    }
+    sort.Slice(result, func(i, j int) bool {
+        return result[i].Timestamp.Before(result[j].Timestamp)
+    })
+    return result
+}
+
 func mergeClickStats(historical, recent []*entity.Click) []*entity.Click {
     log.Printf("Merging %d historical and %d recent clicks", len(historical), len(recent))
     
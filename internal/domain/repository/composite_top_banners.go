@@ -0,0 +1,40 @@
+package repository
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+type compositeTopBannersRepository struct {
+    postgres TopBannersRepository
+    redis    TopBannersRepository
+    coverage ClickCoverage
+}
+
+func NewCompositeTopBannersRepository(postgres, redis TopBannersRepository, coverage ClickCoverage) TopBannersRepository {
+    return &compositeTopBannersRepository{
+        postgres: postgres,
+        redis:    redis,
+        coverage: coverage,
+    }
+}
+
+// TopBanners answers from the Redis leaderboards when Redis covers the
+// whole range, and aggregates Postgres otherwise: leaderboards cut to the
+// limit can't be merged with a part from another store.
+func (r *compositeTopBannersRepository) TopBanners(ctx context.Context, from, to time.Time, limit int) ([]*entity.BannerTotal, error) {
+    if !cacheSplit(ctx, r.coverage, from, to).After(from) {
+        top, err := r.redis.TopBanners(ctx, from, to, limit)
+        if err == nil {
+            log.Printf("Using Redis leaderboard: %d banners", len(top))
+            return top, nil
+        }
+        log.Printf("Failed to get top banners from Redis: %v", err)
+    }
+
+    log.Printf("Aggregating top banners in Postgres from %v to %v", from, to)
+    return r.postgres.TopBanners(ctx, from, to, limit)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/pkg/hll"
+)
+
+type compositeUniqueClickRepository struct {
+    postgres UniqueClickRepository
+    redis    UniqueClickRepository
+    coverage ClickCoverage
+}
+
+func NewCompositeUniqueClickRepository(postgres, redis UniqueClickRepository, coverage ClickCoverage) UniqueClickRepository {
+    return &compositeUniqueClickRepository{
+        postgres: postgres,
+        redis:    redis,
+        coverage: coverage,
+    }
+}
+
+// SaveBatch shares the coverage with the click counters: sketches Redis
+// missed are a gap in it as well.
+func (r *compositeUniqueClickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
+    if err := r.postgres.SaveBatch(ctx, clicks); err != nil {
+        return err
+    }
+
+    if err := r.redis.SaveBatch(ctx, clicks); err != nil {
+        log.Printf("Failed to update Redis sketches: %v", err)
+        markGap(ctx, r.coverage, clicks)
+    }
+
+    return nil
+}
+
+// GetSketch reads the part of the range Redis covers from Redis and the
+// rest from Postgres. Merging is idempotent, so the hour at the split may
+// safely come from both stores.
+func (r *compositeUniqueClickRepository) GetSketch(ctx context.Context, bannerID int64, from, to time.Time) (*hll.Sketch, error) {
+    split := cacheSplit(ctx, r.coverage, from, to)
+    sketch := hll.New()
+
+    if split.Before(to) {
+        recent, err := r.redis.GetSketch(ctx, bannerID, split, to)
+        if err != nil {
+            log.Printf("Failed to get recent sketches from Redis, using Postgres: %v", err)
+            split = to
+        } else {
+            sketch.Merge(recent)
+        }
+    }
+
+    if from.Before(split) {
+        historical, err := r.postgres.GetSketch(ctx, bannerID, from, split)
+        if err != nil {
+            return nil, err
+        }
+        sketch.Merge(historical)
+    }
+
+    return sketch, nil
+}
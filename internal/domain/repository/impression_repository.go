@@ -0,0 +1,11 @@
+package repository
+
+import (
+    "context"
+
+    "clicker/internal/domain/entity"
+)
+
+type ImpressionRepository interface {
+    SaveBatch(ctx context.Context, impressions []*entity.Impression) error
+}
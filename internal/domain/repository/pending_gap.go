@@ -0,0 +1,68 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+)
+
+// pendingGapCoverage remembers a gap the cache could not record and retries
+// it before the coverage is used again. Until it is recorded, reads through
+// this replica go to the store only; other replicas see the gap once it is
+// recorded. A pending gap does not survive a restart, reconciliation
+// repairs the hours it covers once they end.
+type pendingGapCoverage struct {
+    ClickCoverage
+
+    mu sync.Mutex
+    // gap is the end of the clicks the cache missed, zero when there is no
+    // pending gap.
+    gap time.Time
+}
+
+func NewPendingGapCoverage(coverage ClickCoverage) ClickCoverage {
+    return &pendingGapCoverage{
+        ClickCoverage: coverage,
+    }
+}
+
+func (c *pendingGapCoverage) CoveredFrom(ctx context.Context) (time.Time, error) {
+    if err := c.flush(ctx); err != nil {
+        return time.Time{}, err
+    }
+    return c.ClickCoverage.CoveredFrom(ctx)
+}
+
+func (c *pendingGapCoverage) MarkGap(ctx context.Context, until time.Time) error {
+    c.mu.Lock()
+    if until.After(c.gap) {
+        c.gap = until
+    }
+    c.mu.Unlock()
+
+    return c.flush(ctx)
+}
+
+// Restore gives up while a gap is pending, the rewritten range may be
+// missing its clicks.
+func (c *pendingGapCoverage) Restore(ctx context.Context, token int64, from, to time.Time) (bool, error) {
+    if err := c.flush(ctx); err != nil {
+        return false, err
+    }
+    return c.ClickCoverage.Restore(ctx, token, from, to)
+}
+
+func (c *pendingGapCoverage) flush(ctx context.Context) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.gap.IsZero() {
+        return nil
+    }
+    if err := c.ClickCoverage.MarkGap(ctx, c.gap); err != nil {
+        return fmt.Errorf("failed to record cache gap until %v: %w", c.gap, err)
+    }
+    c.gap = time.Time{}
+    return nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+// RetentionRepository removes expired click data and keeps the history of
+// retention runs. Horizons are shared by all tenants and only move forward.
+type RetentionRepository interface {
+    // ExpireRaw deletes raw clicks before the horizon, or before the hourly
+    // watermark if that is earlier. Whole partitions before it are detached
+    // instead of dropped when detach is set.
+    ExpireRaw(ctx context.Context, before time.Time, detach bool) (*entity.TierExpiry, error)
+    // ExpireHourly deletes hourly aggregates before the horizon, or before
+    // the daily watermark if that is earlier.
+    ExpireHourly(ctx context.Context, before time.Time) (*entity.TierExpiry, error)
+    SaveRun(ctx context.Context, run *entity.RetentionRun) error
+    // ListRuns returns the latest runs first.
+    ListRuns(ctx context.Context, limit int) ([]*entity.RetentionRun, error)
+    // TryLock makes sure only one replica enforces retention at a time.
+    TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+}
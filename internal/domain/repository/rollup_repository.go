@@ -0,0 +1,24 @@
+package repository
+
+import (
+    "context"
+    "time"
+)
+
+// RollupRepository maintains the hourly and daily click aggregates. Each
+// tier has a watermark: everything before it is aggregated, everything
+// after it is read from the tier below. Aggregates are shared by all
+// tenants.
+type RollupRepository interface {
+    // RollUpHourly aggregates raw clicks from the hourly watermark on, at
+    // most span ahead and no further than until. It returns the new
+    // watermark, which stays put when there is no whole hour to add.
+    RollUpHourly(ctx context.Context, until time.Time, span time.Duration) (time.Time, error)
+    // RollUpDaily does the same for UTC days, from the hourly aggregates.
+    RollUpDaily(ctx context.Context, until time.Time, span time.Duration) (time.Time, error)
+    // Revisit aggregates the last span before the hourly watermark again,
+    // and the UTC days it touches if they are rolled up already, so clicks
+    // saved after their hour was aggregated are counted. Watermarks stay
+    // put.
+    Revisit(ctx context.Context, span time.Duration) error
+}
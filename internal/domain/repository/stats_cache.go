@@ -0,0 +1,21 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+// StatsCache keeps computed stats for reuse. Entries are tied to the
+// watermark of their banner, which moves every time clicks of the banner
+// are flushed, so an entry is never read once the banner got new clicks.
+type StatsCache interface {
+    Watermark(ctx context.Context, bannerID int64) (int64, error)
+    // Advance moves the watermarks of the updated banners. It must be
+    // called after the clicks are saved.
+    Advance(ctx context.Context, updates []*entity.ClickUpdate) error
+    // Get returns nil when there is no entry.
+    Get(ctx context.Context, bannerID, watermark int64, key string) ([]byte, error)
+    Set(ctx context.Context, bannerID, watermark int64, key string, value []byte, ttl time.Duration) error
+}
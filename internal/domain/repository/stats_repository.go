@@ -9,6 +9,13 @@ import (
 
 type StatsRepository interface {
 	GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error)
+	// GetTotals sums the clicks of many banners at once, without a round
+	// trip per banner. Banners without clicks are missing from the result.
+	GetTotals(ctx context.Context, bannerIDs []int64, from, to time.Time) (map[int64]int64, error)
+	// GetSeries returns one row per non-empty bucket of the granularity
+	// (see package timeseries), aligned in loc, with Timestamp set to the
+	// bucket start.
+	GetSeries(ctx context.Context, bannerID int64, from, to time.Time, granularity string, loc *time.Location) ([]*entity.Click, error)
 }
 
 type StatsUseCase interface {
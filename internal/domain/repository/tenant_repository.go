@@ -0,0 +1,27 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+var ErrTenantNotFound = errors.New("tenant not found")
+
+type TenantRepository interface {
+    Create(ctx context.Context, tenant *entity.Tenant, apiKeyHash string) error
+    GetByID(ctx context.Context, tenantID int64) (*entity.Tenant, error)
+    GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*entity.Tenant, error)
+    List(ctx context.Context) ([]*entity.Tenant, error)
+    UpdateQuota(ctx context.Context, tenant *entity.Tenant) error
+    SetAPIKeyHash(ctx context.Context, tenantID int64, apiKeyHash string) error
+    CountBanners(ctx context.Context, tenantID int64) (int, error)
+}
+
+type QuotaRepository interface {
+    // IncrDailyClicks adds n to the tenant's click counter for the day and
+    // returns the new value.
+    IncrDailyClicks(ctx context.Context, tenantID int64, day time.Time, n int64) (int64, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+type TopBannersRepository interface {
+    // TopBanners returns up to limit most-clicked banners in [from, to),
+    // most clicks first.
+    TopBanners(ctx context.Context, from, to time.Time, limit int) ([]*entity.BannerTotal, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/pkg/hll"
+)
+
+// UniqueClickRepository keeps a HyperLogLog sketch of clicker IDs per banner
+// and hour.
+type UniqueClickRepository interface {
+    SaveBatch(ctx context.Context, clicks []*entity.Click) error
+    // GetSketch merges the sketches of every hour overlapping [from, to),
+    // so the range is effectively widened to whole hours.
+    GetSketch(ctx context.Context, bannerID int64, from, to time.Time) (*hll.Sketch, error)
+}
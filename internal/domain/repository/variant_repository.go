@@ -0,0 +1,22 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+var ErrVariantNotFound = errors.New("variant not found")
+
+type VariantRepository interface {
+    Create(ctx context.Context, variant *entity.Variant) error
+    Update(ctx context.Context, variant *entity.Variant) error
+    GetByID(ctx context.Context, variantID int64) (*entity.Variant, error)
+    ListByBanner(ctx context.Context, bannerID int64) ([]*entity.Variant, error)
+}
+
+type VariantStatsRepository interface {
+    GetVariantStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.VariantStats, error)
+}
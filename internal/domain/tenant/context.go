@@ -0,0 +1,35 @@
+package tenant
+
+import (
+    "context"
+    "errors"
+
+    "clicker/internal/domain/entity"
+)
+
+var ErrNoTenant = errors.New("tenant is not resolved")
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, t *entity.Tenant) context.Context {
+    return context.WithValue(ctx, contextKey{}, t)
+}
+
+func FromContext(ctx context.Context) (*entity.Tenant, error) {
+    t, ok := ctx.Value(contextKey{}).(*entity.Tenant)
+    if !ok || t == nil {
+        return nil, ErrNoTenant
+    }
+    return t, nil
+}
+
+// ID returns the ID of the tenant the request is made on behalf of.
+// Repositories call it to scope every query, so a request without a
+// resolved tenant fails instead of seeing everyone's data.
+func ID(ctx context.Context) (int64, error) {
+    t, err := FromContext(ctx)
+    if err != nil {
+        return 0, err
+    }
+    return t.ID, nil
+}
@@ -0,0 +1,67 @@
+package timeseries
+
+import "time"
+
+// Granularities match the Postgres date_trunc field names, so they can be
+// passed to it as is.
+const (
+    Minute = "minute"
+    Hour   = "hour"
+    Day    = "day"
+    Week   = "week"
+    Month  = "month"
+)
+
+func Valid(granularity string) bool {
+    switch granularity {
+    case Minute, Hour, Day, Week, Month:
+        return true
+    }
+    return false
+}
+
+// Truncate returns the start of the bucket t falls into. Days, weeks and
+// months start at local midnight in loc, weeks on Monday. Minutes and hours
+// are cut by the offset in effect at t, so half-hour zones and the repeated
+// hour at the end of DST both work.
+func Truncate(t time.Time, granularity string, loc *time.Location) time.Time {
+    t = t.In(loc)
+    switch granularity {
+    case Minute, Hour:
+        unit := time.Minute
+        if granularity == Hour {
+            unit = time.Hour
+        }
+        _, offset := t.Zone()
+        shift := time.Duration(offset) * time.Second
+        return t.Add(shift).Truncate(unit).Add(-shift)
+    case Day:
+        return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+    case Week:
+        offset := (int(t.Weekday()) + 6) % 7
+        return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+    case Month:
+        return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+    }
+    return t
+}
+
+// Next returns the start of the bucket following the one starting at start.
+// Calendar buckets are stepped in local time, so a day across a DST switch
+// is 23 or 25 hours long.
+func Next(start time.Time, granularity string, loc *time.Location) time.Time {
+    start = start.In(loc)
+    switch granularity {
+    case Minute:
+        return start.Add(time.Minute)
+    case Hour:
+        return start.Add(time.Hour)
+    case Day:
+        return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, loc)
+    case Week:
+        return time.Date(start.Year(), start.Month(), start.Day()+7, 0, 0, 0, 0, loc)
+    case Month:
+        return time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, loc)
+    }
+    return start
+}
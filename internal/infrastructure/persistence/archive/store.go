@@ -0,0 +1,295 @@
+package archive
+
+import (
+    "bufio"
+    "compress/gzip"
+    "context"
+    "crypto/sha256"
+    "encoding/csv"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+)
+
+// manifestFile lists the archives of a directory. It is rewritten through
+// a temporary file and a rename, so a crash never leaves it half written.
+const manifestFile = "manifest.json"
+
+// csvHeader is the first line of every archive. Timestamps are RFC 3339 in
+// UTC, variant 0 means no variant.
+var csvHeader = []string{"id", "tenant_id", "banner_id", "variant_id", "timestamp", "count"}
+
+type manifest struct {
+    Archives []*entity.ClickArchive `json:"archives"`
+}
+
+// localStore keeps each partition as a gzip CSV file in one directory.
+type localStore struct {
+    dir string
+    mu  sync.Mutex
+}
+
+func NewLocalStore(dir string) repository.ClickArchiveStore {
+    return &localStore{
+        dir: dir,
+    }
+}
+
+func (s *localStore) List(ctx context.Context) ([]*entity.ClickArchive, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    m, err := s.loadManifest()
+    if err != nil {
+        return nil, err
+    }
+    return m.Archives, nil
+}
+
+func (s *localStore) Write(ctx context.Context, partition *entity.ClickPartition,
+    read func(fn func(*entity.Click) error) error) (*entity.ClickArchive, error) {
+    if err := os.MkdirAll(s.dir, 0o755); err != nil {
+        return nil, err
+    }
+
+    archive := &entity.ClickArchive{
+        Partition: partition.Name,
+        From:      partition.From,
+        To:        partition.To,
+        File:      partition.Name + ".csv.gz",
+    }
+    if err := s.writeFile(archive, read); err != nil {
+        return nil, fmt.Errorf("failed to write %s: %w", archive.File, err)
+    }
+    archive.ArchivedAt = time.Now().UTC()
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    m, err := s.loadManifest()
+    if err != nil {
+        return nil, err
+    }
+    archives := make([]*entity.ClickArchive, 0, len(m.Archives)+1)
+    for _, a := range m.Archives {
+        if a.Partition != archive.Partition {
+            archives = append(archives, a)
+        }
+    }
+    archives = append(archives, archive)
+    sort.Slice(archives, func(i, j int) bool {
+        return archives[i].From.Before(archives[j].From)
+    })
+    m.Archives = archives
+
+    if err := s.saveManifest(m); err != nil {
+        return nil, err
+    }
+    return archive, nil
+}
+
+// writeFile streams the clicks into a temporary file and renames it into
+// place once it is complete and synced.
+func (s *localStore) writeFile(archive *entity.ClickArchive, read func(fn func(*entity.Click) error) error) error {
+    tmp, err := os.CreateTemp(s.dir, archive.File+".*.tmp")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    defer tmp.Close()
+
+    hash := sha256.New()
+    buf := bufio.NewWriterSize(tmp, 64*1024)
+    counter := &countingWriter{w: io.MultiWriter(buf, hash)}
+    zw := gzip.NewWriter(counter)
+    w := csv.NewWriter(zw)
+
+    if err := w.Write(csvHeader); err != nil {
+        return err
+    }
+    record := make([]string, len(csvHeader))
+    err = read(func(click *entity.Click) error {
+        record[0] = strconv.FormatInt(click.ID, 10)
+        record[1] = strconv.FormatInt(click.TenantID, 10)
+        record[2] = strconv.FormatInt(click.BannerID, 10)
+        record[3] = strconv.FormatInt(click.VariantID, 10)
+        record[4] = click.Timestamp.UTC().Format(time.RFC3339Nano)
+        record[5] = strconv.Itoa(click.Count)
+        archive.Rows++
+        return w.Write(record)
+    })
+    if err != nil {
+        return err
+    }
+
+    w.Flush()
+    if err := w.Error(); err != nil {
+        return err
+    }
+    if err := zw.Close(); err != nil {
+        return err
+    }
+    if err := buf.Flush(); err != nil {
+        return err
+    }
+    if err := tmp.Sync(); err != nil {
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+
+    archive.Bytes = counter.n
+    archive.SHA256 = hex.EncodeToString(hash.Sum(nil))
+    return os.Rename(tmp.Name(), filepath.Join(s.dir, archive.File))
+}
+
+func (s *localStore) Read(ctx context.Context, archive *entity.ClickArchive, fn func(*entity.Click) error) error {
+    path := filepath.Join(s.dir, archive.File)
+    if err := verifyChecksum(path, archive.SHA256); err != nil {
+        return err
+    }
+
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    zr, err := gzip.NewReader(bufio.NewReader(f))
+    if err != nil {
+        return fmt.Errorf("failed to open %s: %w", archive.File, err)
+    }
+    defer zr.Close()
+
+    r := csv.NewReader(zr)
+    r.FieldsPerRecord = len(csvHeader)
+    r.ReuseRecord = true
+    if _, err := r.Read(); err != nil {
+        return fmt.Errorf("failed to read header of %s: %w", archive.File, err)
+    }
+
+    for {
+        record, err := r.Read()
+        if errors.Is(err, io.EOF) {
+            return nil
+        }
+        if err != nil {
+            return fmt.Errorf("failed to read %s: %w", archive.File, err)
+        }
+
+        click, err := parseRecord(record)
+        if err != nil {
+            return fmt.Errorf("bad record in %s: %w", archive.File, err)
+        }
+        if err := fn(click); err != nil {
+            return err
+        }
+    }
+}
+
+func parseRecord(record []string) (*entity.Click, error) {
+    var (
+        click = &entity.Click{}
+        err   error
+    )
+    if click.ID, err = strconv.ParseInt(record[0], 10, 64); err != nil {
+        return nil, err
+    }
+    if click.TenantID, err = strconv.ParseInt(record[1], 10, 64); err != nil {
+        return nil, err
+    }
+    if click.BannerID, err = strconv.ParseInt(record[2], 10, 64); err != nil {
+        return nil, err
+    }
+    if click.VariantID, err = strconv.ParseInt(record[3], 10, 64); err != nil {
+        return nil, err
+    }
+    if click.Timestamp, err = time.Parse(time.RFC3339Nano, record[4]); err != nil {
+        return nil, err
+    }
+    if click.Count, err = strconv.Atoi(record[5]); err != nil {
+        return nil, err
+    }
+    return click, nil
+}
+
+func verifyChecksum(path, want string) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    hash := sha256.New()
+    if _, err := io.Copy(hash, f); err != nil {
+        return err
+    }
+    if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+        return fmt.Errorf("checksum mismatch for %s: manifest has %s, file has %s", filepath.Base(path), want, got)
+    }
+    return nil
+}
+
+func (s *localStore) loadManifest() (*manifest, error) {
+    data, err := os.ReadFile(filepath.Join(s.dir, manifestFile))
+    if errors.Is(err, os.ErrNotExist) {
+        return &manifest{}, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    m := &manifest{}
+    if err := json.Unmarshal(data, m); err != nil {
+        return nil, fmt.Errorf("failed to parse %s: %w", manifestFile, err)
+    }
+    return m, nil
+}
+
+func (s *localStore) saveManifest(m *manifest) error {
+    data, err := json.MarshalIndent(m, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(s.dir, manifestFile+".*.tmp")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    defer tmp.Close()
+
+    if _, err := tmp.Write(data); err != nil {
+        return err
+    }
+    if err := tmp.Sync(); err != nil {
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), filepath.Join(s.dir, manifestFile))
+}
+
+type countingWriter struct {
+    w io.Writer
+    n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+    n, err := c.w.Write(p)
+    c.n += int64(n)
+    return n, err
+}
@@ -0,0 +1,311 @@
+package embedded
+
+import (
+    "context"
+    "encoding/json"
+    "time"
+
+    "clicker/internal/domain/audit"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    bolt "go.etcd.io/bbolt"
+)
+
+// storedBanner is a banner as kept in bannersBucket. Deleted banners stay
+// for their stats.
+type storedBanner struct {
+    Banner  entity.Banner `json:"banner"`
+    Deleted bool          `json:"deleted,omitempty"`
+}
+
+type bannerRepository struct {
+    store *Store
+}
+
+func NewBannerRepository(store *Store) repository.BannerRepository {
+    return &bannerRepository{
+        store: store,
+    }
+}
+
+func (r *bannerRepository) Create(ctx context.Context, banner *entity.Banner) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        id, err := tx.Bucket(bannersBucket).NextSequence()
+        if err != nil {
+            return err
+        }
+        banner.ID = int64(id)
+        banner.TenantID = tenantID
+
+        // Метки задаются отдельно через SetLabels, как и в Postgres.
+        stored := &storedBanner{Banner: *banner}
+        stored.Banner.Labels = nil
+        if err := putBanner(tx, stored); err != nil {
+            return err
+        }
+
+        return putRevision(ctx, tx, &entity.BannerRevision{
+            TenantID: tenantID,
+            BannerID: banner.ID,
+            Action:   entity.RevisionActionCreate,
+            Changes:  entity.DiffBanner(nil, banner),
+        })
+    })
+}
+
+func (r *bannerRepository) Update(ctx context.Context, banner *entity.Banner) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        stored, err := getBanner(tx, tenantID, banner.ID)
+        if err != nil {
+            return err
+        }
+
+        before := stored.Banner
+        stored.Banner.Name = banner.Name
+        stored.Banner.URL = banner.URL
+        stored.Banner.CreativeURL = banner.CreativeURL
+        stored.Banner.Placement = banner.Placement
+        stored.Banner.Status = banner.Status
+        stored.Banner.Weight = banner.Weight
+        stored.Banner.DailyImpressionCap = banner.DailyImpressionCap
+        if err := putBanner(tx, stored); err != nil {
+            return err
+        }
+
+        changes := entity.DiffBanner(&before, banner)
+        if len(changes) == 0 {
+            return nil
+        }
+        return putRevision(ctx, tx, &entity.BannerRevision{
+            TenantID: tenantID,
+            BannerID: banner.ID,
+            Action:   entity.RevisionActionUpdate,
+            Changes:  changes,
+        })
+    })
+}
+
+func (r *bannerRepository) Delete(ctx context.Context, bannerID int64) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        stored, err := getBanner(tx, tenantID, bannerID)
+        if err != nil {
+            return err
+        }
+        stored.Deleted = true
+        if err := putBanner(tx, stored); err != nil {
+            return err
+        }
+
+        return putRevision(ctx, tx, &entity.BannerRevision{
+            TenantID: tenantID,
+            BannerID: bannerID,
+            Action:   entity.RevisionActionDelete,
+        })
+    })
+}
+
+func (r *bannerRepository) GetByID(ctx context.Context, bannerID int64) (*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var banner *entity.Banner
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        stored, err := getBanner(tx, tenantID, bannerID)
+        if err != nil {
+            return err
+        }
+        banner = &stored.Banner
+        return nil
+    })
+    return banner, err
+}
+
+func (r *bannerRepository) SetLabels(ctx context.Context, bannerID int64, labels map[string]string) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        stored, err := getBanner(tx, tenantID, bannerID)
+        if err != nil {
+            return err
+        }
+        stored.Banner.Labels = labels
+        return putBanner(tx, stored)
+    })
+}
+
+func (r *bannerRepository) FindByLabels(ctx context.Context, selector map[string]string) ([]*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+    // Как и запрос в Postgres, пустой селектор не выбирает ничего.
+    if len(selector) == 0 {
+        return nil, nil
+    }
+
+    var banners []*entity.Banner
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        return eachBanner(tx, tenantID, func(stored *storedBanner) error {
+            for key, value := range selector {
+                if got, ok := stored.Banner.Labels[key]; !ok || got != value {
+                    return nil
+                }
+            }
+            banners = append(banners, &stored.Banner)
+            return nil
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+    return banners, nil
+}
+
+func (r *bannerRepository) FindServable(ctx context.Context, placement string) ([]*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now().UTC()
+    today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+    var banners []*entity.Banner
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        return eachBanner(tx, tenantID, func(stored *storedBanner) error {
+            banner := &stored.Banner
+            if stored.Deleted || banner.Placement != placement ||
+                banner.Status != entity.BannerStatusActive || banner.Weight <= 0 {
+                return nil
+            }
+            if banner.DailyImpressionCap > 0 {
+                var shown int64
+                walk(tx.Bucket(impressionsBucket).Bucket(bannerKey(tenantID, banner.ID)), today, now.Add(time.Second),
+                    func(_ []byte, count int64) {
+                        shown += count
+                    })
+                if shown >= int64(banner.DailyImpressionCap) {
+                    return nil
+                }
+            }
+            banners = append(banners, banner)
+            return nil
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+    return banners, nil
+}
+
+// eachBanner calls fn for every banner of the tenant, deleted or not, in
+// ID order.
+func eachBanner(tx *bolt.Tx, tenantID int64, fn func(*storedBanner) error) error {
+    b := tx.Bucket(bannersBucket).Bucket(idKey(tenantID))
+    if b == nil {
+        return nil
+    }
+    return b.ForEach(func(_, value []byte) error {
+        stored, err := decodeBanner(value)
+        if err != nil {
+            return err
+        }
+        return fn(stored)
+    })
+}
+
+// getBanner returns a banner of the tenant that is not deleted.
+func getBanner(tx *bolt.Tx, tenantID, bannerID int64) (*storedBanner, error) {
+    stored, err := findBanner(tx, tenantID, bannerID)
+    if err != nil {
+        return nil, err
+    }
+    if stored == nil || stored.Deleted {
+        return nil, repository.ErrBannerNotFound
+    }
+    return stored, nil
+}
+
+// findBanner returns a banner of the tenant, deleted or not, and nil when
+// there is none.
+func findBanner(tx *bolt.Tx, tenantID, bannerID int64) (*storedBanner, error) {
+    b := tx.Bucket(bannersBucket).Bucket(idKey(tenantID))
+    if b == nil {
+        return nil, nil
+    }
+    value := b.Get(idKey(bannerID))
+    if value == nil {
+        return nil, nil
+    }
+    return decodeBanner(value)
+}
+
+func decodeBanner(value []byte) (*storedBanner, error) {
+    stored := &storedBanner{}
+    if err := json.Unmarshal(value, stored); err != nil {
+        return nil, err
+    }
+    if stored.Banner.Labels == nil {
+        stored.Banner.Labels = make(map[string]string)
+    }
+    return stored, nil
+}
+
+func putBanner(tx *bolt.Tx, stored *storedBanner) error {
+    b, err := tx.Bucket(bannersBucket).CreateBucketIfNotExists(idKey(stored.Banner.TenantID))
+    if err != nil {
+        return err
+    }
+    value, err := json.Marshal(stored)
+    if err != nil {
+        return err
+    }
+    return b.Put(idKey(stored.Banner.ID), value)
+}
+
+// putRevision writes a history entry in the transaction of the change
+// itself, taking the actor from the request context like Postgres does.
+func putRevision(ctx context.Context, tx *bolt.Tx, revision *entity.BannerRevision) error {
+    if revision.Changes == nil {
+        revision.Changes = []entity.FieldChange{}
+    }
+    revision.Actor = audit.Actor(ctx)
+    revision.CreatedAt = time.Now()
+
+    id, err := tx.Bucket(revisionsBucket).NextSequence()
+    if err != nil {
+        return err
+    }
+    revision.ID = int64(id)
+
+    b, err := tx.Bucket(revisionsBucket).CreateBucketIfNotExists(bannerKey(revision.TenantID, revision.BannerID))
+    if err != nil {
+        return err
+    }
+    value, err := json.Marshal(revision)
+    if err != nil {
+        return err
+    }
+    return b.Put(idKey(revision.ID), value)
+}
@@ -0,0 +1,48 @@
+package embedded
+
+import (
+    "context"
+    "encoding/json"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    bolt "go.etcd.io/bbolt"
+)
+
+type bannerRevisionRepository struct {
+    store *Store
+}
+
+func NewBannerRevisionRepository(store *Store) repository.BannerRevisionRepository {
+    return &bannerRevisionRepository{
+        store: store,
+    }
+}
+
+func (r *bannerRevisionRepository) ListByBanner(ctx context.Context, bannerID int64) ([]*entity.BannerRevision, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var revisions []*entity.BannerRevision
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        b := tx.Bucket(revisionsBucket).Bucket(bannerKey(tenantID, bannerID))
+        if b == nil {
+            return nil
+        }
+        return b.ForEach(func(_, value []byte) error {
+            revision := &entity.BannerRevision{}
+            if err := json.Unmarshal(value, revision); err != nil {
+                return err
+            }
+            revisions = append(revisions, revision)
+            return nil
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+    return revisions, nil
+}
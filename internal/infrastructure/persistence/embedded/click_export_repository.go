@@ -0,0 +1,127 @@
+package embedded
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+    bolt "go.etcd.io/bbolt"
+)
+
+type clickExportRepository struct {
+    store *Store
+}
+
+func NewClickExportRepository(store *Store) repository.ClickExportRepository {
+    return &clickExportRepository{
+        store: store,
+    }
+}
+
+// ExportClicks yields a row per second and variant of the raw clicks. The
+// file keeps no archive, so there are never restored clicks.
+func (r *clickExportRepository) ExportClicks(ctx context.Context, bannerIDs []int64, from, to time.Time, restored bool,
+    fn func(*entity.Click) error) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+    if restored {
+        return nil
+    }
+
+    clicks, err := r.read(tenantID, bannerIDs, from, to)
+    if err != nil {
+        return err
+    }
+    sort.SliceStable(clicks, func(i, j int) bool {
+        return clicks[i].Timestamp.Before(clicks[j].Timestamp)
+    })
+    for _, click := range clicks {
+        if err := fn(click); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *clickExportRepository) ExportSeries(ctx context.Context, bannerIDs []int64, from, to time.Time,
+    granularity string, loc *time.Location, restored bool, fn func(*entity.Click) error) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+    if restored {
+        return nil
+    }
+
+    clicks, err := r.read(tenantID, bannerIDs, from, to)
+    if err != nil {
+        return err
+    }
+
+    type bucketKey struct {
+        bannerID int64
+        start    int64
+    }
+    buckets := make(map[bucketKey]*entity.Click)
+    var series []*entity.Click
+    for _, click := range clicks {
+        start := timeseries.Truncate(click.Timestamp, granularity, loc)
+        key := bucketKey{bannerID: click.BannerID, start: start.Unix()}
+        if bucket, ok := buckets[key]; ok {
+            bucket.Count += click.Count
+            continue
+        }
+        bucket := &entity.Click{TenantID: tenantID, BannerID: click.BannerID, Timestamp: start, Count: click.Count}
+        buckets[key] = bucket
+        series = append(series, bucket)
+    }
+
+    sort.Slice(series, func(i, j int) bool {
+        if series[i].BannerID != series[j].BannerID {
+            return series[i].BannerID < series[j].BannerID
+        }
+        return series[i].Timestamp.Before(series[j].Timestamp)
+    })
+    for _, click := range series {
+        if err := fn(click); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// read copies the raw clicks of the banners in a single read transaction:
+// fn writes to the client, and a bolt reader held that long would block
+// the writes that grow the file.
+func (r *clickExportRepository) read(tenantID int64, bannerIDs []int64, from, to time.Time) ([]*entity.Click, error) {
+    var clicks []*entity.Click
+    err := r.store.db.View(func(tx *bolt.Tx) error {
+        seen := make(map[int64]bool, len(bannerIDs))
+        for _, bannerID := range bannerIDs {
+            if seen[bannerID] {
+                continue
+            }
+            seen[bannerID] = true
+            walk(tx.Bucket(secondsBucket).Bucket(bannerKey(tenantID, bannerID)), from, to, func(key []byte, count int64) {
+                clicks = append(clicks, &entity.Click{
+                    TenantID:  tenantID,
+                    BannerID:  bannerID,
+                    VariantID: parseVariant(key),
+                    Timestamp: parseTimeKey(key),
+                    Count:     int(count),
+                })
+            })
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return clicks, nil
+}
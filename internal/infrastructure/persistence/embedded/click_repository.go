@@ -0,0 +1,81 @@
+package embedded
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+    bolt "go.etcd.io/bbolt"
+)
+
+type clickRepository struct {
+    store *Store
+}
+
+func NewClickRepository(store *Store) repository.ClickRepository {
+    return &clickRepository{
+        store: store,
+    }
+}
+
+// SaveBatch writes the batch in one transaction, to the seconds, hours,
+// days and totals of each banner.
+func (r *clickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        for _, click := range clicks {
+            key := bannerKey(click.TenantID, click.BannerID)
+            n := int64(click.Count)
+
+            for _, t := range []tier{secondsTier, hoursTier, daysTier} {
+                b, err := tx.Bucket(t.bucket).CreateBucketIfNotExists(key)
+                if err != nil {
+                    return err
+                }
+                start := click.Timestamp
+                if t.granularity != "" {
+                    start = timeseries.Truncate(click.Timestamp, t.granularity, time.UTC)
+                }
+                if err := increment(b, clickKey(start, click.VariantID), n); err != nil {
+                    return err
+                }
+            }
+
+            if err := increment(tx.Bucket(totalsBucket), key, n); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// GetStats reads whole days and hours from the aggregates, so a row may
+// sum many clicks with Timestamp set to the bucket start.
+func (r *clickRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var clicks []*entity.Click
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        segments := planRange(ctx, tx, from, to, allowedTiers{hours: true, days: true})
+        visit(tx, tenantID, bannerID, segments, func(ts time.Time, count int64) {
+            clicks = append(clicks, &entity.Click{
+                TenantID:  tenantID,
+                BannerID:  bannerID,
+                Timestamp: ts,
+                Count:     int(count),
+            })
+        })
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    // Сегменты идут по порядку и внутри упорядочены, сортировать не нужно.
+    return clicks, nil
+}
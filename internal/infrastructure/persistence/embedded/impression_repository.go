@@ -0,0 +1,35 @@
+package embedded
+
+import (
+    "context"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    bolt "go.etcd.io/bbolt"
+)
+
+type impressionRepository struct {
+    store *Store
+}
+
+func NewImpressionRepository(store *Store) repository.ImpressionRepository {
+    return &impressionRepository{
+        store: store,
+    }
+}
+
+func (r *impressionRepository) SaveBatch(ctx context.Context, impressions []*entity.Impression) error {
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        for _, impression := range impressions {
+            b, err := tx.Bucket(impressionsBucket).CreateBucketIfNotExists(bannerKey(impression.TenantID, impression.BannerID))
+            if err != nil {
+                return err
+            }
+            key := clickKey(impression.Timestamp, impression.VariantID)
+            if err := increment(b, key, int64(impression.Count)); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
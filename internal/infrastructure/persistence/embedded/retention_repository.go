@@ -0,0 +1,112 @@
+package embedded
+
+import (
+    "bytes"
+    "context"
+    "encoding/binary"
+    "encoding/json"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    bolt "go.etcd.io/bbolt"
+)
+
+type retentionRepository struct {
+    store *Store
+}
+
+// NewRetentionRepository enforces retention on the file. The aggregates
+// are written with the clicks, so unlike Postgres nothing has to wait for
+// a rollup before it is deleted.
+func NewRetentionRepository(store *Store) repository.RetentionRepository {
+    return &retentionRepository{
+        store: store,
+    }
+}
+
+// ExpireRaw ignores detach, the file has no partitions to detach.
+func (r *retentionRepository) ExpireRaw(ctx context.Context, before time.Time, detach bool) (*entity.TierExpiry, error) {
+    return r.expire(secondsBucket, rawHorizonKey, before)
+}
+
+func (r *retentionRepository) ExpireHourly(ctx context.Context, before time.Time) (*entity.TierExpiry, error) {
+    return r.expire(hoursBucket, hourlyHorizonKey, before)
+}
+
+// expire moves the horizon and deletes what is before it in one
+// transaction. Like in Postgres the horizon never moves back.
+func (r *retentionRepository) expire(bucket, horizonKey []byte, before time.Time) (*entity.TierExpiry, error) {
+    expiry := &entity.TierExpiry{}
+    err := r.store.db.Update(func(tx *bolt.Tx) error {
+        if current := horizon(tx, horizonKey); current.After(before) {
+            before = current
+        }
+        if err := tx.Bucket(metaBucket).Put(horizonKey, timeKey(before)); err != nil {
+            return err
+        }
+        expiry.Horizon = before
+
+        end := timeKey(before)
+        return tx.Bucket(bucket).ForEachBucket(func(name []byte) error {
+            c := tx.Bucket(bucket).Bucket(name).Cursor()
+            // После Delete курсор сдвигается, поэтому ищем заново.
+            for k, _ := c.First(); k != nil && bytes.Compare(k, end) < 0; k, _ = c.Seek(k) {
+                if err := c.Delete(); err != nil {
+                    return err
+                }
+                expiry.Deleted++
+            }
+            return nil
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+    return expiry, nil
+}
+
+func (r *retentionRepository) SaveRun(ctx context.Context, run *entity.RetentionRun) error {
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        b := tx.Bucket(runsBucket)
+        id, err := b.NextSequence()
+        if err != nil {
+            return err
+        }
+        run.ID = int64(id)
+
+        value, err := json.Marshal(run)
+        if err != nil {
+            return err
+        }
+        key := make([]byte, 8)
+        binary.BigEndian.PutUint64(key, id)
+        return b.Put(key, value)
+    })
+}
+
+func (r *retentionRepository) ListRuns(ctx context.Context, limit int) ([]*entity.RetentionRun, error) {
+    var runs []*entity.RetentionRun
+    err := r.store.db.View(func(tx *bolt.Tx) error {
+        c := tx.Bucket(runsBucket).Cursor()
+        for k, v := c.Last(); k != nil && len(runs) < limit; k, v = c.Prev() {
+            run := &entity.RetentionRun{}
+            if err := json.Unmarshal(v, run); err != nil {
+                return err
+            }
+            runs = append(runs, run)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return runs, nil
+}
+
+func (r *retentionRepository) TryLock(ctx context.Context) (func(), bool, error) {
+    if !r.store.enforcing.TryLock() {
+        return nil, false, nil
+    }
+    return r.store.enforcing.Unlock, true, nil
+}
@@ -0,0 +1,150 @@
+package embedded
+
+import (
+    "bytes"
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+    bolt "go.etcd.io/bbolt"
+)
+
+type statsRepository struct {
+    clickRepository
+}
+
+func NewStatsRepository(store *Store) repository.StatsRepository {
+    return &statsRepository{clickRepository{store: store}}
+}
+
+func NewTopBannersRepository(store *Store) repository.TopBannersRepository {
+    return &statsRepository{clickRepository{store: store}}
+}
+
+func NewBannerTotalsRepository(store *Store) repository.BannerTotalsRepository {
+    return &statsRepository{clickRepository{store: store}}
+}
+
+// GetSeries uses the aggregates only where their buckets fit whole into
+// the requested ones: hours when loc is a whole number of hours off UTC,
+// days only in UTC.
+func (r *statsRepository) GetSeries(ctx context.Context, bannerID int64, from, to time.Time,
+    granularity string, loc *time.Location) ([]*entity.Click, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    allowed := allowedTiers{
+        hours: granularity != timeseries.Minute && wholeHourOffset(from, loc) && wholeHourOffset(to, loc),
+        days:  granularity != timeseries.Minute && granularity != timeseries.Hour && loc.String() == "UTC",
+    }
+
+    buckets := make(map[int64]*entity.Click)
+    var series []*entity.Click
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        visit(tx, tenantID, bannerID, planRange(ctx, tx, from, to, allowed), func(ts time.Time, count int64) {
+            start := timeseries.Truncate(ts, granularity, loc)
+            if bucket, ok := buckets[start.Unix()]; ok {
+                bucket.Count += int(count)
+                return
+            }
+            bucket := &entity.Click{BannerID: bannerID, Timestamp: start, Count: int(count)}
+            buckets[start.Unix()] = bucket
+            series = append(series, bucket)
+        })
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    sort.Slice(series, func(i, j int) bool {
+        return series[i].Timestamp.Before(series[j].Timestamp)
+    })
+    return series, nil
+}
+
+func (r *statsRepository) GetTotals(ctx context.Context, bannerIDs []int64, from, to time.Time) (map[int64]int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    totals := make(map[int64]int64, len(bannerIDs))
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        segments := planRange(ctx, tx, from, to, allowedTiers{hours: true, days: true})
+        for _, bannerID := range bannerIDs {
+            visit(tx, tenantID, bannerID, segments, func(_ time.Time, count int64) {
+                totals[bannerID] += count
+            })
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return totals, nil
+}
+
+// TopBanners sums every banner of the tenant, found through the totals.
+func (r *statsRepository) TopBanners(ctx context.Context, from, to time.Time, limit int) ([]*entity.BannerTotal, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var top []*entity.BannerTotal
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        segments := planRange(ctx, tx, from, to, allowedTiers{hours: true, days: true})
+        prefix := bannerKey(tenantID, 0)[:8]
+        c := tx.Bucket(totalsBucket).Cursor()
+        for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+            _, bannerID := parseBannerKey(k)
+            total := &entity.BannerTotal{BannerID: bannerID}
+            visit(tx, tenantID, bannerID, segments, func(_ time.Time, count int64) {
+                total.Clicks += count
+            })
+            if total.Clicks > 0 {
+                top = append(top, total)
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    sort.SliceStable(top, func(i, j int) bool {
+        return top[i].Clicks > top[j].Clicks
+    })
+    if len(top) > limit {
+        top = top[:limit]
+    }
+    return top, nil
+}
+
+func (r *statsRepository) GetTotal(ctx context.Context, bannerID int64) (int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    var total int64
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        total = decodeCount(tx.Bucket(totalsBucket).Get(bannerKey(tenantID, bannerID)))
+        return nil
+    })
+    return total, err
+}
+
+// wholeHourOffset reports whether loc is a whole number of hours off UTC
+// at t, so its hours and days are made of whole UTC hours.
+func wholeHourOffset(t time.Time, loc *time.Location) bool {
+    _, offset := t.In(loc).Zone()
+    return offset%3600 == 0
+}
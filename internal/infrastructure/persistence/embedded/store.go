@@ -0,0 +1,176 @@
+package embedded
+
+import (
+    "encoding/binary"
+    "fmt"
+    "sync"
+    "time"
+
+    bolt "go.etcd.io/bbolt"
+)
+
+// Top-level buckets. seconds, hours and days hold a nested bucket per
+// banner (see bannerKey) mapping the start of a bucket and the variant
+// (see clickKey) to its clicks, all three are written together so the
+// aggregates are never behind.
+var (
+    secondsBucket = []byte("seconds")
+    hoursBucket   = []byte("hours")
+    daysBucket    = []byte("days")
+    // totalsBucket maps a bannerKey to its all-time clicks.
+    totalsBucket = []byte("totals")
+    // metaBucket holds the retention horizons.
+    metaBucket = []byte("meta")
+    runsBucket = []byte("retention_runs")
+    // impressionsBucket holds a nested bucket per banner mapping a clickKey
+    // to the impressions of that second. Impressions are not rolled up nor
+    // expired, like in Postgres.
+    impressionsBucket = []byte("impressions")
+    // uniquesBucket holds a nested bucket per banner mapping the start of
+    // an hour to the HyperLogLog of its clickers.
+    uniquesBucket = []byte("uniques")
+)
+
+// Buckets of the banners and their owners. Records are JSON, IDs come from
+// the sequence of the top-level bucket.
+var (
+    // tenantsBucket maps a tenant ID to the tenant.
+    tenantsBucket = []byte("tenants")
+    // apiKeysBucket maps an API key hash to the ID of its tenant.
+    apiKeysBucket = []byte("api_keys")
+    // bannersBucket holds a nested bucket per tenant mapping a banner ID to
+    // the banner.
+    bannersBucket = []byte("banners")
+    // variantsBucket and revisionsBucket hold a nested bucket per banner
+    // (see bannerKey) mapping an ID to a variant or a revision.
+    variantsBucket  = []byte("variants")
+    revisionsBucket = []byte("revisions")
+)
+
+// Keys of the retention horizons in metaBucket: where the seconds and the
+// hours start after retention.
+var (
+    rawHorizonKey    = []byte("raw_horizon")
+    hourlyHorizonKey = []byte("hourly_horizon")
+)
+
+// Store is a bbolt file holding clicks and the tenants, banners and
+// variants they belong to, for running clicker without Postgres and Redis.
+// Bolt allows one writer at a time, which the batched click writes are
+// well within.
+type Store struct {
+    db *bolt.DB
+    // enforcing keeps retention runs from overlapping, there is only ever
+    // one process on the file.
+    enforcing sync.Mutex
+}
+
+func Open(path string) (*Store, error) {
+    db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+    if err != nil {
+        return nil, fmt.Errorf("failed to open %s: %w", path, err)
+    }
+
+    err = db.Update(func(tx *bolt.Tx) error {
+        for _, name := range [][]byte{secondsBucket, hoursBucket, daysBucket, totalsBucket, metaBucket, runsBucket,
+            impressionsBucket, uniquesBucket, tenantsBucket, apiKeysBucket, bannersBucket, variantsBucket,
+            revisionsBucket} {
+            if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        db.Close()
+        return nil, fmt.Errorf("failed to create buckets in %s: %w", path, err)
+    }
+
+    return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+    return s.db.Close()
+}
+
+func bannerKey(tenantID, bannerID int64) []byte {
+    key := make([]byte, 16)
+    binary.BigEndian.PutUint64(key, uint64(tenantID))
+    binary.BigEndian.PutUint64(key[8:], uint64(bannerID))
+    return key
+}
+
+func parseBannerKey(key []byte) (tenantID, bannerID int64) {
+    return int64(binary.BigEndian.Uint64(key)), int64(binary.BigEndian.Uint64(key[8:]))
+}
+
+// timeKey sorts by time, so a cursor walks a range in order. Times before
+// 1970 would sort after every other as unsigned, so they are clamped to the
+// epoch: clicks are stamped when they arrive, there is nothing before it.
+func timeKey(t time.Time) []byte {
+    key := make([]byte, 8)
+    if sec := t.Unix(); sec > 0 {
+        binary.BigEndian.PutUint64(key, uint64(sec))
+    }
+    return key
+}
+
+// parseTimeKey reads the time of a timeKey or a clickKey.
+func parseTimeKey(key []byte) time.Time {
+    return time.Unix(int64(binary.BigEndian.Uint64(key)), 0)
+}
+
+// clickKey is a timeKey followed by the variant, so the counts of a second
+// sit next to each other. Clicks without a variant keep the bare timeKey,
+// as in files written before variants were stored.
+func clickKey(t time.Time, variantID int64) []byte {
+    key := timeKey(t)
+    if variantID == 0 {
+        return key
+    }
+    return binary.BigEndian.AppendUint64(key, uint64(variantID))
+}
+
+func parseVariant(key []byte) int64 {
+    if len(key) < 16 {
+        return 0
+    }
+    return int64(binary.BigEndian.Uint64(key[8:]))
+}
+
+func idKey(id int64) []byte {
+    key := make([]byte, 8)
+    binary.BigEndian.PutUint64(key, uint64(id))
+    return key
+}
+
+func parseIDKey(key []byte) int64 {
+    return int64(binary.BigEndian.Uint64(key))
+}
+
+func encodeCount(n int64) []byte {
+    value := make([]byte, 8)
+    binary.BigEndian.PutUint64(value, uint64(n))
+    return value
+}
+
+func decodeCount(value []byte) int64 {
+    if len(value) != 8 {
+        return 0
+    }
+    return int64(binary.BigEndian.Uint64(value))
+}
+
+// increment adds n to the counter under key.
+func increment(b *bolt.Bucket, key []byte, n int64) error {
+    return b.Put(key, encodeCount(decodeCount(b.Get(key))+n))
+}
+
+// horizon reads a retention horizon, zero when the tier is kept whole.
+func horizon(tx *bolt.Tx, key []byte) time.Time {
+    value := tx.Bucket(metaBucket).Get(key)
+    if value == nil {
+        return time.Time{}
+    }
+    return parseTimeKey(value)
+}
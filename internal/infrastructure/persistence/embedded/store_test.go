@@ -0,0 +1,162 @@
+package embedded
+
+import (
+    "context"
+    "errors"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    bolt "go.etcd.io/bbolt"
+)
+
+func openStore(t *testing.T, path string) *Store {
+    t.Helper()
+    store, err := Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { store.Close() })
+    return store
+}
+
+func tenantContext(tenantID int64) context.Context {
+    return tenant.NewContext(context.Background(), &entity.Tenant{ID: tenantID})
+}
+
+func TestTimeKeyClampsBeforeEpoch(t *testing.T) {
+    store := openStore(t, filepath.Join(t.TempDir(), "clicker.db"))
+    ctx := tenantContext(1)
+
+    ts := time.Date(2024, 3, 10, 14, 30, 5, 0, time.UTC)
+    err := NewClickRepository(store).SaveBatch(ctx, []*entity.Click{{TenantID: 1, BannerID: 7, Timestamp: ts, Count: 2}})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    stats := NewStatsRepository(store)
+    for _, from := range []time.Time{time.Unix(0, 0), time.Unix(-3600, 0), time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)} {
+        totals, err := stats.GetTotals(ctx, []int64{7}, from, ts.Add(time.Second))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if totals[7] != 2 {
+            t.Errorf("GetTotals from %v = %d, want 2", from, totals[7])
+        }
+    }
+
+    totals, err := stats.GetTotals(ctx, []int64{7}, time.Unix(-7200, 0), time.Unix(-3600, 0))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if totals[7] != 0 {
+        t.Errorf("GetTotals before the epoch = %d, want 0", totals[7])
+    }
+}
+
+func TestVariantKeys(t *testing.T) {
+    store := openStore(t, filepath.Join(t.TempDir(), "clicker.db"))
+    ctx := tenantContext(1)
+
+    banner := &entity.Banner{Status: entity.BannerStatusActive, Placement: "top", Weight: 1}
+    if err := NewBannerRepository(store).Create(ctx, banner); err != nil {
+        t.Fatal(err)
+    }
+    variants := NewVariantRepository(store)
+    variant := &entity.Variant{BannerID: banner.ID, Name: "b", Weight: 1}
+    if err := variants.Create(ctx, variant); err != nil {
+        t.Fatal(err)
+    }
+
+    ts := time.Date(2024, 3, 10, 14, 30, 5, 0, time.UTC)
+    // Файл, записанный до вариантов, хранит секунду под голым timeKey.
+    err := store.db.Update(func(tx *bolt.Tx) error {
+        b, err := tx.Bucket(secondsBucket).CreateBucketIfNotExists(bannerKey(1, banner.ID))
+        if err != nil {
+            return err
+        }
+        return increment(b, timeKey(ts), 1)
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    err = NewClickRepository(store).SaveBatch(ctx, []*entity.Click{
+        {TenantID: 1, BannerID: banner.ID, Timestamp: ts, Count: 2},
+        {TenantID: 1, BannerID: banner.ID, VariantID: variant.ID, Timestamp: ts, Count: 4},
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    rows, err := NewStatsRepository(store).GetStats(ctx, banner.ID, ts, ts.Add(time.Second))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(rows) != 1 || rows[0].Count != 7 {
+        t.Fatalf("GetStats = %d rows, want one of 7 clicks", len(rows))
+    }
+
+    stats, err := NewVariantStatsRepository(store).GetVariantStats(ctx, banner.ID, ts.Add(-time.Hour), ts.Add(time.Hour))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(stats) != 1 || stats[0].VariantID != variant.ID || stats[0].Clicks != 4 {
+        t.Fatalf("GetVariantStats = %+v, want variant %d with 4 clicks", stats, variant.ID)
+    }
+}
+
+func TestCatalogSurvivesReopen(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "clicker.db")
+    store, err := Open(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    owner := &entity.Tenant{Name: "acme", MaxBanners: 3}
+    if err := NewTenantRepository(store).Create(context.Background(), owner, "hash"); err != nil {
+        t.Fatal(err)
+    }
+    ctx := tenantContext(owner.ID)
+    banner := &entity.Banner{Name: "b", Status: entity.BannerStatusActive, Placement: "top", Weight: 1}
+    if err := NewBannerRepository(store).Create(ctx, banner); err != nil {
+        t.Fatal(err)
+    }
+    if err := NewBannerRepository(store).SetLabels(ctx, banner.ID, map[string]string{"team": "x"}); err != nil {
+        t.Fatal(err)
+    }
+    if err := store.Close(); err != nil {
+        t.Fatal(err)
+    }
+
+    store = openStore(t, path)
+    found, err := NewTenantRepository(store).GetByAPIKeyHash(context.Background(), "hash")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if found.ID != owner.ID || found.Name != "acme" {
+        t.Fatalf("GetByAPIKeyHash = %+v, want tenant %d", found, owner.ID)
+    }
+
+    banners := NewBannerRepository(store)
+    got, err := banners.GetByID(ctx, banner.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got.Name != "b" || got.Labels["team"] != "x" {
+        t.Fatalf("GetByID = %+v", got)
+    }
+    if _, err := banners.GetByID(tenantContext(owner.ID+1), banner.ID); !errors.Is(err, repository.ErrBannerNotFound) {
+        t.Fatalf("GetByID of another tenant's banner: err = %v, want ErrBannerNotFound", err)
+    }
+
+    revisions, err := NewBannerRevisionRepository(store).ListByBanner(ctx, banner.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(revisions) != 1 || revisions[0].Action != entity.RevisionActionCreate {
+        t.Fatalf("ListByBanner = %d revisions, want the create", len(revisions))
+    }
+}
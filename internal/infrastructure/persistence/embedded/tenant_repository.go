@@ -0,0 +1,179 @@
+package embedded
+
+import (
+    "context"
+    "encoding/json"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    bolt "go.etcd.io/bbolt"
+)
+
+// storedTenant is a tenant as kept in tenantsBucket.
+type storedTenant struct {
+    Tenant     entity.Tenant `json:"tenant"`
+    APIKeyHash string        `json:"api_key_hash"`
+}
+
+type tenantRepository struct {
+    store *Store
+}
+
+func NewTenantRepository(store *Store) repository.TenantRepository {
+    return &tenantRepository{
+        store: store,
+    }
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant *entity.Tenant, apiKeyHash string) error {
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        id, err := tx.Bucket(tenantsBucket).NextSequence()
+        if err != nil {
+            return err
+        }
+        tenant.ID = int64(id)
+        tenant.CreatedAt = time.Now()
+
+        if err := putTenant(tx, &storedTenant{Tenant: *tenant, APIKeyHash: apiKeyHash}); err != nil {
+            return err
+        }
+        return putAPIKey(tx, apiKeyHash, tenant.ID)
+    })
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, tenantID int64) (*entity.Tenant, error) {
+    var tenant *entity.Tenant
+    err := r.store.db.View(func(tx *bolt.Tx) error {
+        stored, err := getTenant(tx, tenantID)
+        if err != nil {
+            return err
+        }
+        tenant = &stored.Tenant
+        return nil
+    })
+    return tenant, err
+}
+
+func (r *tenantRepository) GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*entity.Tenant, error) {
+    var tenant *entity.Tenant
+    err := r.store.db.View(func(tx *bolt.Tx) error {
+        if apiKeyHash == "" {
+            return repository.ErrTenantNotFound
+        }
+        id := tx.Bucket(apiKeysBucket).Get([]byte(apiKeyHash))
+        if id == nil {
+            return repository.ErrTenantNotFound
+        }
+        stored, err := getTenant(tx, parseIDKey(id))
+        if err != nil {
+            return err
+        }
+        tenant = &stored.Tenant
+        return nil
+    })
+    return tenant, err
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]*entity.Tenant, error) {
+    var tenants []*entity.Tenant
+    err := r.store.db.View(func(tx *bolt.Tx) error {
+        return tx.Bucket(tenantsBucket).ForEach(func(_, value []byte) error {
+            stored := &storedTenant{}
+            if err := json.Unmarshal(value, stored); err != nil {
+                return err
+            }
+            tenants = append(tenants, &stored.Tenant)
+            return nil
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+    return tenants, nil
+}
+
+func (r *tenantRepository) UpdateQuota(ctx context.Context, tenant *entity.Tenant) error {
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        stored, err := getTenant(tx, tenant.ID)
+        if err != nil {
+            return err
+        }
+        stored.Tenant.MaxBanners = tenant.MaxBanners
+        stored.Tenant.MaxDailyClicks = tenant.MaxDailyClicks
+        tenant.Name = stored.Tenant.Name
+        tenant.CreatedAt = stored.Tenant.CreatedAt
+        return putTenant(tx, stored)
+    })
+}
+
+func (r *tenantRepository) SetAPIKeyHash(ctx context.Context, tenantID int64, apiKeyHash string) error {
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        stored, err := getTenant(tx, tenantID)
+        if err != nil {
+            return err
+        }
+
+        if stored.APIKeyHash != "" {
+            if err := tx.Bucket(apiKeysBucket).Delete([]byte(stored.APIKeyHash)); err != nil {
+                return err
+            }
+        }
+        stored.APIKeyHash = apiKeyHash
+        if err := putAPIKey(tx, apiKeyHash, tenantID); err != nil {
+            return err
+        }
+        return putTenant(tx, stored)
+    })
+}
+
+func (r *tenantRepository) CountBanners(ctx context.Context, tenantID int64) (int, error) {
+    var count int
+    err := r.store.db.View(func(tx *bolt.Tx) error {
+        b := tx.Bucket(bannersBucket).Bucket(idKey(tenantID))
+        if b == nil {
+            return nil
+        }
+        return b.ForEach(func(_, value []byte) error {
+            stored, err := decodeBanner(value)
+            if err != nil {
+                return err
+            }
+            // Удалённые баннеры лимит не занимают, как и в Postgres.
+            if !stored.Deleted {
+                count++
+            }
+            return nil
+        })
+    })
+    return count, err
+}
+
+func getTenant(tx *bolt.Tx, tenantID int64) (*storedTenant, error) {
+    value := tx.Bucket(tenantsBucket).Get(idKey(tenantID))
+    if value == nil {
+        return nil, repository.ErrTenantNotFound
+    }
+    stored := &storedTenant{}
+    if err := json.Unmarshal(value, stored); err != nil {
+        return nil, err
+    }
+    return stored, nil
+}
+
+func putTenant(tx *bolt.Tx, stored *storedTenant) error {
+    value, err := json.Marshal(stored)
+    if err != nil {
+        return err
+    }
+    return tx.Bucket(tenantsBucket).Put(idKey(stored.Tenant.ID), value)
+}
+
+// putAPIKey indexes the tenant by its key. A tenant without a key is not
+// indexed, bolt keys cannot be empty.
+func putAPIKey(tx *bolt.Tx, apiKeyHash string, tenantID int64) error {
+    if apiKeyHash == "" {
+        return nil
+    }
+    return tx.Bucket(apiKeysBucket).Put([]byte(apiKeyHash), idKey(tenantID))
+}
@@ -0,0 +1,156 @@
+package embedded
+
+import (
+    "bytes"
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
+    "clicker/internal/domain/timeseries"
+    bolt "go.etcd.io/bbolt"
+)
+
+// tier is one of the per-banner time buckets. Seconds have no granularity,
+// they are the raw clicks.
+type tier struct {
+    name        string
+    bucket      []byte
+    granularity string
+}
+
+var (
+    secondsTier = tier{name: entity.TierRaw, bucket: secondsBucket}
+    hoursTier   = tier{name: entity.TierHourly, bucket: hoursBucket, granularity: timeseries.Hour}
+    daysTier    = tier{name: entity.TierDaily, bucket: daysBucket, granularity: timeseries.Day}
+)
+
+type segment struct {
+    tier     tier
+    from, to time.Time
+}
+
+// allowedTiers says which aggregates a query may use where finer data
+// still exists. Past a retention horizon the finest tier left is used
+// regardless.
+type allowedTiers struct {
+    hours, days bool
+}
+
+// plan splits [from, to) between the tiers, coarsest first, the way the
+// Postgres backend reads its rollups: each tier takes its whole buckets,
+// the edges go down to a finer tier and finest takes the rest, counting
+// its buckets where they start.
+func plan(from, to time.Time, tiers []tier, finest tier) []segment {
+    if !from.Before(to) {
+        return nil
+    }
+    if len(tiers) == 0 {
+        return []segment{{tier: finest, from: from, to: to}}
+    }
+
+    t, finer := tiers[0], tiers[1:]
+    start := timeseries.Truncate(from, t.granularity, time.UTC)
+    if start.Before(from) {
+        start = timeseries.Next(start, t.granularity, time.UTC)
+    }
+    end := timeseries.Truncate(to, t.granularity, time.UTC)
+    if !start.Before(end) {
+        return plan(from, to, finer, finest)
+    }
+
+    segments := plan(from, start, finer, finest)
+    segments = append(segments, segment{tier: t, from: start, to: end})
+    return append(segments, plan(end, to, finer, finest)...)
+}
+
+// planRange cuts [from, to) at the retention horizons and plans each part
+// over the tiers left there. The plan is recorded as the sources of the
+// answer.
+func planRange(ctx context.Context, tx *bolt.Tx, from, to time.Time, allowed allowedTiers) []segment {
+    rawHorizon := horizon(tx, rawHorizonKey)
+    hourlyHorizon := horizon(tx, hourlyHorizonKey)
+
+    cuts := []time.Time{from}
+    for _, h := range []time.Time{rawHorizon, hourlyHorizon} {
+        if h.After(from) && h.Before(to) {
+            cuts = append(cuts, h)
+        }
+    }
+    sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+    cuts = append(cuts, to)
+
+    var segments []segment
+    for i := 0; i+1 < len(cuts); i++ {
+        hasRaw := !cuts[i].Before(rawHorizon)
+        hasHours := !cuts[i].Before(hourlyHorizon)
+
+        var tiers []tier
+        finest := daysTier
+        switch {
+        case hasRaw:
+            if allowed.days {
+                tiers = append(tiers, daysTier)
+            }
+            if allowed.hours && hasHours {
+                tiers = append(tiers, hoursTier)
+            }
+            finest = secondsTier
+        case hasHours:
+            if allowed.days {
+                tiers = append(tiers, daysTier)
+            }
+            finest = hoursTier
+        }
+        segments = append(segments, plan(cuts[i], cuts[i+1], tiers, finest)...)
+    }
+    for _, s := range segments {
+        freshness.Record(ctx, s.tier.name, s.from, s.to)
+    }
+    return segments
+}
+
+// visit calls fn for every non-empty bucket of the banner the plan reads,
+// summing its variants.
+func visit(tx *bolt.Tx, tenantID, bannerID int64, segments []segment, fn func(ts time.Time, count int64)) {
+    var (
+        pending []byte
+        sum     int64
+    )
+    visitVariants(tx, tenantID, bannerID, segments, func(key []byte, count int64) {
+        // Варианты одной секунды лежат подряд.
+        if pending != nil && !bytes.Equal(key[:8], pending) {
+            fn(parseTimeKey(pending), sum)
+            sum = 0
+        }
+        pending = key[:8]
+        sum += count
+    })
+    if pending != nil {
+        fn(parseTimeKey(pending), sum)
+    }
+}
+
+// visitVariants calls fn with the clickKey of every counter of the banner
+// the plan reads, in key order.
+func visitVariants(tx *bolt.Tx, tenantID, bannerID int64, segments []segment, fn func(key []byte, count int64)) {
+    key := bannerKey(tenantID, bannerID)
+    for _, s := range segments {
+        walk(tx.Bucket(s.tier.bucket).Bucket(key), s.from, s.to, fn)
+    }
+}
+
+// walk calls fn for the keys of b in [from, to). Only the time part of a
+// key is compared, so the variants of a second are never split.
+func walk(b *bolt.Bucket, from, to time.Time, fn func(key []byte, count int64)) {
+    if b == nil {
+        return
+    }
+
+    end := timeKey(to)
+    c := b.Cursor()
+    for k, v := c.Seek(timeKey(from)); k != nil && bytes.Compare(k[:8], end) < 0; k, v = c.Next() {
+        fn(k, decodeCount(v))
+    }
+}
@@ -0,0 +1,170 @@
+package embedded
+
+import (
+    "context"
+    "fmt"
+    "path/filepath"
+    "reflect"
+    "testing"
+    "time"
+
+    "clicker/internal/domain/freshness"
+    bolt "go.etcd.io/bbolt"
+)
+
+// describe renders segments as "tier from-to" in UTC, which is easier to
+// compare and read in a failure than the structs.
+func describe(segments []segment) []string {
+    var out []string
+    for _, s := range segments {
+        out = append(out, fmt.Sprintf("%s %s-%s", s.tier.name,
+            s.from.UTC().Format("01-02 15:04"), s.to.UTC().Format("01-02 15:04")))
+    }
+    return out
+}
+
+func at(day, hour, minute int) time.Time {
+    return time.Date(2024, 3, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestPlan(t *testing.T) {
+    tests := []struct {
+        name     string
+        from, to time.Time
+        tiers    []tier
+        finest   tier
+        want     []string
+    }{
+        {
+            name: "empty range",
+            from: at(10, 12, 0), to: at(10, 11, 0),
+            tiers:  []tier{daysTier, hoursTier},
+            finest: secondsTier,
+            want:   nil,
+        },
+        {
+            name: "seconds only",
+            from: at(10, 10, 20), to: at(10, 13, 0),
+            finest: secondsTier,
+            want:   []string{"raw 03-10 10:20-03-10 13:00"},
+        },
+        {
+            name: "days in the middle, hours and seconds at the edges",
+            from: at(9, 22, 30), to: at(12, 1, 15),
+            tiers:  []tier{daysTier, hoursTier},
+            finest: secondsTier,
+            want: []string{
+                "raw 03-09 22:30-03-09 23:00",
+                "hourly 03-09 23:00-03-10 00:00",
+                "daily 03-10 00:00-03-12 00:00",
+                "hourly 03-12 00:00-03-12 01:00",
+                "raw 03-12 01:00-03-12 01:15",
+            },
+        },
+        {
+            name: "whole hours",
+            from: at(10, 10, 0), to: at(10, 13, 0),
+            tiers:  []tier{daysTier, hoursTier},
+            finest: secondsTier,
+            want:   []string{"hourly 03-10 10:00-03-10 13:00"},
+        },
+        {
+            name: "hours as the finest tier",
+            from: at(9, 22, 30), to: at(11, 1, 15),
+            tiers:  []tier{daysTier},
+            finest: hoursTier,
+            want: []string{
+                "hourly 03-09 22:30-03-10 00:00",
+                "daily 03-10 00:00-03-11 00:00",
+                "hourly 03-11 00:00-03-11 01:15",
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := describe(plan(tt.from, tt.to, tt.tiers, tt.finest))
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("plan =\n%q\nwant\n%q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestPlanRange(t *testing.T) {
+    store := openStore(t, filepath.Join(t.TempDir(), "clicker.db"))
+    err := store.db.Update(func(tx *bolt.Tx) error {
+        if err := tx.Bucket(metaBucket).Put(rawHorizonKey, timeKey(at(10, 0, 0))); err != nil {
+            return err
+        }
+        return tx.Bucket(metaBucket).Put(hourlyHorizonKey, timeKey(at(5, 0, 0)))
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name     string
+        from, to time.Time
+        allowed  allowedTiers
+        want     []string
+    }{
+        {
+            name: "seconds kept",
+            from: at(11, 10, 20), to: at(11, 13, 0),
+            want: []string{"raw 03-11 10:20-03-11 13:00"},
+        },
+        {
+            name: "seconds kept, aggregates allowed",
+            from: at(11, 10, 20), to: at(11, 13, 0),
+            allowed: allowedTiers{hours: true, days: true},
+            want: []string{
+                "raw 03-11 10:20-03-11 11:00",
+                "hourly 03-11 11:00-03-11 13:00",
+            },
+        },
+        {
+            // До горизонта секунд нет, там отвечают часы, даже без разрешения.
+            name: "across the raw horizon",
+            from: at(9, 22, 30), to: at(10, 0, 30),
+            want: []string{
+                "hourly 03-09 22:30-03-10 00:00",
+                "raw 03-10 00:00-03-10 00:30",
+            },
+        },
+        {
+            name: "across both horizons",
+            from: at(4, 12, 0), to: at(10, 0, 30),
+            allowed: allowedTiers{hours: true, days: true},
+            want: []string{
+                "daily 03-04 12:00-03-05 00:00",
+                "daily 03-05 00:00-03-10 00:00",
+                "raw 03-10 00:00-03-10 00:30",
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            ctx, report := freshness.NewContext(context.Background())
+            var segments []segment
+            err := store.db.View(func(tx *bolt.Tx) error {
+                segments = planRange(ctx, tx, tt.from, tt.to, tt.allowed)
+                return nil
+            })
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            got := describe(segments)
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("planRange =\n%q\nwant\n%q", got, tt.want)
+            }
+            // Ответ сообщает, из каких уровней он собран.
+            if sources := report.Freshness().Sources; len(sources) == 0 ||
+                !sources[0].From.Equal(tt.from) || !sources[len(sources)-1].To.Equal(tt.to) {
+                t.Errorf("freshness does not cover %v-%v: %+v", tt.from, tt.to, sources)
+            }
+        })
+    }
+}
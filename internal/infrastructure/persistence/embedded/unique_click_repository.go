@@ -0,0 +1,106 @@
+package embedded
+
+import (
+    "bytes"
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/pkg/hll"
+    bolt "go.etcd.io/bbolt"
+)
+
+type uniqueClickRepository struct {
+    store *Store
+}
+
+func NewUniqueClickRepository(store *Store) repository.UniqueClickRepository {
+    return &uniqueClickRepository{
+        store: store,
+    }
+}
+
+// SaveBatch merges the clickers of the batch into the stored sketch of
+// each hour once, not once per click.
+func (r *uniqueClickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
+    type hourKey struct {
+        tenantID, bannerID int64
+        hour               time.Time
+    }
+    sketches := make(map[hourKey]*hll.Sketch)
+    for _, click := range clicks {
+        if click.ClickerID == "" {
+            continue
+        }
+        key := hourKey{tenantID: click.TenantID, bannerID: click.BannerID, hour: click.Timestamp.Truncate(time.Hour)}
+        sketch, ok := sketches[key]
+        if !ok {
+            sketch = hll.New()
+            sketches[key] = sketch
+        }
+        sketch.AddString(click.ClickerID)
+    }
+    if len(sketches) == 0 {
+        return nil
+    }
+
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        for key, sketch := range sketches {
+            b, err := tx.Bucket(uniquesBucket).CreateBucketIfNotExists(bannerKey(key.tenantID, key.bannerID))
+            if err != nil {
+                return err
+            }
+            hour := timeKey(key.hour)
+            if data := b.Get(hour); data != nil {
+                stored := hll.New()
+                if err := stored.UnmarshalBinary(data); err != nil {
+                    return err
+                }
+                if !stored.Merge(sketch) {
+                    continue
+                }
+                sketch = stored
+            }
+            data, err := sketch.MarshalBinary()
+            if err != nil {
+                return err
+            }
+            if err := b.Put(hour, data); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+func (r *uniqueClickRepository) GetSketch(ctx context.Context, bannerID int64, from, to time.Time) (*hll.Sketch, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    sketch := hll.New()
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        b := tx.Bucket(uniquesBucket).Bucket(bannerKey(tenantID, bannerID))
+        if b == nil {
+            return nil
+        }
+
+        end := timeKey(to)
+        c := b.Cursor()
+        for k, v := c.Seek(timeKey(from.Truncate(time.Hour))); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+            bucket := hll.New()
+            if err := bucket.UnmarshalBinary(v); err != nil {
+                return err
+            }
+            sketch.Merge(bucket)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return sketch, nil
+}
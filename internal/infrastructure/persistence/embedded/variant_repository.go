@@ -0,0 +1,228 @@
+package embedded
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    bolt "go.etcd.io/bbolt"
+)
+
+type variantRepository struct {
+    store *Store
+}
+
+func NewVariantRepository(store *Store) repository.VariantRepository {
+    return &variantRepository{
+        store: store,
+    }
+}
+
+func NewVariantStatsRepository(store *Store) repository.VariantStatsRepository {
+    return &variantRepository{
+        store: store,
+    }
+}
+
+func (r *variantRepository) Create(ctx context.Context, variant *entity.Variant) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        if _, err := getBanner(tx, tenantID, variant.BannerID); err != nil {
+            return err
+        }
+
+        id, err := tx.Bucket(variantsBucket).NextSequence()
+        if err != nil {
+            return err
+        }
+        variant.ID = int64(id)
+        if err := putVariant(tx, tenantID, variant); err != nil {
+            return err
+        }
+
+        return putRevision(ctx, tx, &entity.BannerRevision{
+            TenantID:  tenantID,
+            BannerID:  variant.BannerID,
+            VariantID: variant.ID,
+            Action:    entity.RevisionActionVariantCreate,
+            Changes:   entity.DiffVariant(nil, variant),
+        })
+    })
+}
+
+func (r *variantRepository) Update(ctx context.Context, variant *entity.Variant) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.store.db.Update(func(tx *bolt.Tx) error {
+        if _, err := getBanner(tx, tenantID, variant.BannerID); err != nil {
+            return repository.ErrVariantNotFound
+        }
+        b := variantBucket(tx, tenantID, variant.BannerID)
+        if b == nil {
+            return repository.ErrVariantNotFound
+        }
+        value := b.Get(idKey(variant.ID))
+        if value == nil {
+            return repository.ErrVariantNotFound
+        }
+        before := &entity.Variant{}
+        if err := json.Unmarshal(value, before); err != nil {
+            return err
+        }
+
+        updated := *before
+        updated.Name = variant.Name
+        updated.CreativeURL = variant.CreativeURL
+        updated.Weight = variant.Weight
+        if err := putVariant(tx, tenantID, &updated); err != nil {
+            return err
+        }
+
+        changes := entity.DiffVariant(before, variant)
+        if len(changes) == 0 {
+            return nil
+        }
+        return putRevision(ctx, tx, &entity.BannerRevision{
+            TenantID:  tenantID,
+            BannerID:  variant.BannerID,
+            VariantID: variant.ID,
+            Action:    entity.RevisionActionVariantUpdate,
+            Changes:   changes,
+        })
+    })
+}
+
+// GetByID looks through the variants of every banner of the tenant, there
+// are few enough of them.
+func (r *variantRepository) GetByID(ctx context.Context, variantID int64) (*entity.Variant, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var variant *entity.Variant
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        prefix := idKey(tenantID)
+        c := tx.Bucket(variantsBucket).Cursor()
+        for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+            value := tx.Bucket(variantsBucket).Bucket(k).Get(idKey(variantID))
+            if value == nil {
+                continue
+            }
+            variant = &entity.Variant{}
+            return json.Unmarshal(value, variant)
+        }
+        return repository.ErrVariantNotFound
+    })
+    if err != nil {
+        return nil, err
+    }
+    return variant, nil
+}
+
+func (r *variantRepository) ListByBanner(ctx context.Context, bannerID int64) ([]*entity.Variant, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var variants []*entity.Variant
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        variants, err = listVariants(tx, tenantID, bannerID)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+    return variants, nil
+}
+
+// GetVariantStats reads clicks through the aggregates like GetStats, so
+// variant history outlives raw click retention.
+func (r *variantRepository) GetVariantStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.VariantStats, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    var stats []*entity.VariantStats
+    err = r.store.db.View(func(tx *bolt.Tx) error {
+        variants, err := listVariants(tx, tenantID, bannerID)
+        if err != nil {
+            return err
+        }
+
+        impressions := make(map[int64]int64)
+        walk(tx.Bucket(impressionsBucket).Bucket(bannerKey(tenantID, bannerID)), from, to, func(key []byte, count int64) {
+            impressions[parseVariant(key)] += count
+        })
+        clicks := make(map[int64]int64)
+        segments := planRange(ctx, tx, from, to, allowedTiers{hours: true, days: true})
+        visitVariants(tx, tenantID, bannerID, segments, func(key []byte, count int64) {
+            clicks[parseVariant(key)] += count
+        })
+
+        for _, variant := range variants {
+            stats = append(stats, &entity.VariantStats{
+                VariantID:   variant.ID,
+                Name:        variant.Name,
+                Weight:      variant.Weight,
+                Impressions: impressions[variant.ID],
+                Clicks:      clicks[variant.ID],
+            })
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return stats, nil
+}
+
+// variantBucket returns the variants of the banner, nil when it has none.
+func variantBucket(tx *bolt.Tx, tenantID, bannerID int64) *bolt.Bucket {
+    return tx.Bucket(variantsBucket).Bucket(bannerKey(tenantID, bannerID))
+}
+
+// listVariants returns the variants of the banner in ID order, also of a
+// deleted one.
+func listVariants(tx *bolt.Tx, tenantID, bannerID int64) ([]*entity.Variant, error) {
+    b := variantBucket(tx, tenantID, bannerID)
+    if b == nil {
+        return nil, nil
+    }
+
+    var variants []*entity.Variant
+    err := b.ForEach(func(_, value []byte) error {
+        variant := &entity.Variant{}
+        if err := json.Unmarshal(value, variant); err != nil {
+            return err
+        }
+        variants = append(variants, variant)
+        return nil
+    })
+    return variants, err
+}
+
+func putVariant(tx *bolt.Tx, tenantID int64, variant *entity.Variant) error {
+    b, err := tx.Bucket(variantsBucket).CreateBucketIfNotExists(bannerKey(tenantID, variant.BannerID))
+    if err != nil {
+        return err
+    }
+    value, err := json.Marshal(variant)
+    if err != nil {
+        return err
+    }
+    return b.Put(idKey(variant.ID), value)
+}
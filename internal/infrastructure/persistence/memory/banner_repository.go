@@ -0,0 +1,205 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+type bannerRepository struct {
+    store *Store
+}
+
+func NewBannerRepository(store *Store) repository.BannerRepository {
+    return &bannerRepository{
+        store: store,
+    }
+}
+
+func (r *bannerRepository) Create(ctx context.Context, banner *entity.Banner) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    r.store.lastBanner++
+    banner.ID = r.store.lastBanner
+    banner.TenantID = tenantID
+    // Метки задаются отдельно через SetLabels, как и в Postgres.
+    stored := copyBanner(banner)
+    stored.Labels = map[string]string{}
+    r.store.banners[banner.ID] = &storedBanner{banner: *stored}
+
+    r.store.addRevision(ctx, &entity.BannerRevision{
+        TenantID: tenantID,
+        BannerID: banner.ID,
+        Action:   entity.RevisionActionCreate,
+        Changes:  entity.DiffBanner(nil, banner),
+    })
+    return nil
+}
+
+func (r *bannerRepository) Update(ctx context.Context, banner *entity.Banner) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    stored := r.store.banner(tenantID, banner.ID)
+    if stored == nil || stored.deleted {
+        return repository.ErrBannerNotFound
+    }
+
+    before := stored.banner
+    updated := before
+    updated.Name = banner.Name
+    updated.URL = banner.URL
+    updated.CreativeURL = banner.CreativeURL
+    updated.Placement = banner.Placement
+    updated.Status = banner.Status
+    updated.Weight = banner.Weight
+    updated.DailyImpressionCap = banner.DailyImpressionCap
+    stored.banner = updated
+
+    if changes := entity.DiffBanner(&before, banner); len(changes) > 0 {
+        r.store.addRevision(ctx, &entity.BannerRevision{
+            TenantID: tenantID,
+            BannerID: banner.ID,
+            Action:   entity.RevisionActionUpdate,
+            Changes:  changes,
+        })
+    }
+    return nil
+}
+
+func (r *bannerRepository) Delete(ctx context.Context, bannerID int64) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    stored := r.store.banner(tenantID, bannerID)
+    if stored == nil || stored.deleted {
+        return repository.ErrBannerNotFound
+    }
+    stored.deleted = true
+
+    r.store.addRevision(ctx, &entity.BannerRevision{
+        TenantID: tenantID,
+        BannerID: bannerID,
+        Action:   entity.RevisionActionDelete,
+    })
+    return nil
+}
+
+func (r *bannerRepository) GetByID(ctx context.Context, bannerID int64) (*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    stored := r.store.banner(tenantID, bannerID)
+    if stored == nil || stored.deleted {
+        return nil, repository.ErrBannerNotFound
+    }
+    return copyBanner(&stored.banner), nil
+}
+
+func (r *bannerRepository) SetLabels(ctx context.Context, bannerID int64, labels map[string]string) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    stored := r.store.banner(tenantID, bannerID)
+    if stored == nil || stored.deleted {
+        return repository.ErrBannerNotFound
+    }
+    stored.banner.Labels = make(map[string]string, len(labels))
+    for key, value := range labels {
+        stored.banner.Labels[key] = value
+    }
+    return nil
+}
+
+func (r *bannerRepository) FindByLabels(ctx context.Context, selector map[string]string) ([]*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+    // Как и запрос в Postgres, пустой селектор не выбирает ничего.
+    if len(selector) == 0 {
+        return nil, nil
+    }
+
+    return r.find(tenantID, func(stored *storedBanner) bool {
+        for key, value := range selector {
+            if got, ok := stored.banner.Labels[key]; !ok || got != value {
+                return false
+            }
+        }
+        return true
+    }), nil
+}
+
+func (r *bannerRepository) FindServable(ctx context.Context, placement string) ([]*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now().UTC()
+    today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+    return r.find(tenantID, func(stored *storedBanner) bool {
+        banner := &stored.banner
+        if stored.deleted || banner.Placement != placement ||
+            banner.Status != entity.BannerStatusActive || banner.Weight <= 0 {
+            return false
+        }
+        if banner.DailyImpressionCap == 0 {
+            return true
+        }
+        var shown int64
+        r.store.impressions.each(tenantID, banner.ID, today, now.Add(time.Second), func(_ time.Time, count int64) {
+            shown += count
+        })
+        return shown < int64(banner.DailyImpressionCap)
+    }), nil
+}
+
+// find returns copies of the tenant's banners that match, ordered by ID.
+func (r *bannerRepository) find(tenantID int64, match func(*storedBanner) bool) []*entity.Banner {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var banners []*entity.Banner
+    for _, stored := range r.store.banners {
+        if stored.banner.TenantID == tenantID && match(stored) {
+            banners = append(banners, copyBanner(&stored.banner))
+        }
+    }
+
+    sort.Slice(banners, func(i, j int) bool {
+        return banners[i].ID < banners[j].ID
+    })
+    return banners
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+    "context"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+type bannerRevisionRepository struct {
+    store *Store
+}
+
+func NewBannerRevisionRepository(store *Store) repository.BannerRevisionRepository {
+    return &bannerRevisionRepository{
+        store: store,
+    }
+}
+
+func (r *bannerRevisionRepository) ListByBanner(ctx context.Context, bannerID int64) ([]*entity.BannerRevision, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var revisions []*entity.BannerRevision
+    for _, stored := range r.store.revisions[bannerID] {
+        if stored.TenantID == tenantID {
+            revision := *stored
+            revisions = append(revisions, &revision)
+        }
+    }
+    return revisions, nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+)
+
+type clickExportRepository struct {
+    store *Store
+}
+
+func NewClickExportRepository(store *Store) repository.ClickExportRepository {
+    return &clickExportRepository{
+        store: store,
+    }
+}
+
+// ExportClicks yields a row per second and variant. The memory backend
+// keeps no archive, so there are never restored clicks.
+func (r *clickExportRepository) ExportClicks(ctx context.Context, bannerIDs []int64, from, to time.Time, restored bool,
+    fn func(*entity.Click) error) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+    if restored {
+        return nil
+    }
+
+    // Строки копируются под блокировкой, а отдаются без неё: fn пишет
+    // клиенту и может ждать его сколько угодно.
+    var clicks []*entity.Click
+    r.store.mu.RLock()
+    for _, bannerID := range bannerIDs {
+        r.store.clicks.eachVariant(tenantID, bannerID, from, to, func(ts time.Time, variantID, count int64) {
+            clicks = append(clicks, &entity.Click{
+                TenantID:  tenantID,
+                BannerID:  bannerID,
+                VariantID: variantID,
+                Timestamp: ts,
+                Count:     int(count),
+            })
+        })
+    }
+    r.store.mu.RUnlock()
+
+    sort.Slice(clicks, func(i, j int) bool {
+        if !clicks[i].Timestamp.Equal(clicks[j].Timestamp) {
+            return clicks[i].Timestamp.Before(clicks[j].Timestamp)
+        }
+        if clicks[i].BannerID != clicks[j].BannerID {
+            return clicks[i].BannerID < clicks[j].BannerID
+        }
+        return clicks[i].VariantID < clicks[j].VariantID
+    })
+    for _, click := range clicks {
+        if err := fn(click); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (r *clickExportRepository) ExportSeries(ctx context.Context, bannerIDs []int64, from, to time.Time,
+    granularity string, loc *time.Location, restored bool, fn func(*entity.Click) error) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+    if restored {
+        return nil
+    }
+
+    ids := append([]int64(nil), bannerIDs...)
+    sort.Slice(ids, func(i, j int) bool {
+        return ids[i] < ids[j]
+    })
+
+    var series []*entity.Click
+    r.store.mu.RLock()
+    for i, bannerID := range ids {
+        if i > 0 && ids[i-1] == bannerID {
+            continue
+        }
+        buckets := make(map[int64]*entity.Click)
+        var banner []*entity.Click
+        r.store.clicks.each(tenantID, bannerID, from, to, func(ts time.Time, count int64) {
+            start := timeseries.Truncate(ts, granularity, loc)
+            if bucket, ok := buckets[start.Unix()]; ok {
+                bucket.Count += int(count)
+                return
+            }
+            bucket := &entity.Click{TenantID: tenantID, BannerID: bannerID, Timestamp: start, Count: int(count)}
+            buckets[start.Unix()] = bucket
+            banner = append(banner, bucket)
+        })
+        sort.Slice(banner, func(i, j int) bool {
+            return banner[i].Timestamp.Before(banner[j].Timestamp)
+        })
+        series = append(series, banner...)
+    }
+    r.store.mu.RUnlock()
+
+    for _, click := range series {
+        if err := fn(click); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+    "context"
+    "log"
+    "sync"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+)
+
+// subscriberBuffer is how many flushes a slow subscriber may lag behind
+// before it starts losing them.
+const subscriberBuffer = 64
+
+type feedSubscriber struct {
+    tenantID int64
+    updates  chan []*entity.ClickUpdate
+}
+
+// clickFeed hands flushed clicks straight to the subscribers of this
+// process, the only replica there is with the memory backend.
+type clickFeed struct {
+    mu          sync.RWMutex
+    subscribers map[*feedSubscriber]struct{}
+}
+
+func NewClickFeed() repository.ClickFeed {
+    return &clickFeed{
+        subscribers: make(map[*feedSubscriber]struct{}),
+    }
+}
+
+func (f *clickFeed) Publish(ctx context.Context, updates []*entity.ClickUpdate) error {
+    byTenant := make(map[int64][]*entity.ClickUpdate)
+    for _, u := range updates {
+        byTenant[u.TenantID] = append(byTenant[u.TenantID], u)
+    }
+
+    f.mu.RLock()
+    defer f.mu.RUnlock()
+
+    for sub := range f.subscribers {
+        tenantUpdates, ok := byTenant[sub.tenantID]
+        if !ok {
+            continue
+        }
+        select {
+        case sub.updates <- tenantUpdates:
+        default:
+            log.Printf("Memory: Dropping click update for a slow subscriber of tenant %d", sub.tenantID)
+        }
+    }
+    return nil
+}
+
+func (f *clickFeed) Subscribe(ctx context.Context, tenantID int64) (<-chan []*entity.ClickUpdate, error) {
+    sub := &feedSubscriber{
+        tenantID: tenantID,
+        updates:  make(chan []*entity.ClickUpdate, subscriberBuffer),
+    }
+
+    f.mu.Lock()
+    f.subscribers[sub] = struct{}{}
+    f.mu.Unlock()
+
+    go func() {
+        <-ctx.Done()
+        f.mu.Lock()
+        delete(f.subscribers, sub)
+        f.mu.Unlock()
+        close(sub.updates)
+    }()
+
+    return sub.updates, nil
+}
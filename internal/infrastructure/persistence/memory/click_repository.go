@@ -0,0 +1,59 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+type clickRepository struct {
+    store *Store
+}
+
+func NewClickRepository(store *Store) repository.ClickRepository {
+    return &clickRepository{
+        store: store,
+    }
+}
+
+func (r *clickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for _, click := range clicks {
+        r.store.add(click)
+    }
+    return nil
+}
+
+// GetStats returns a row per second with clicks, ordered by time.
+func (r *clickRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    freshness.Record(ctx, entity.TierRaw, from, to)
+    var clicks []*entity.Click
+    r.store.each(tenantID, bannerID, from, to, func(ts time.Time, count int64) {
+        clicks = append(clicks, &entity.Click{
+            TenantID:  tenantID,
+            BannerID:  bannerID,
+            Timestamp: ts,
+            Count:     int(count),
+        })
+    })
+
+    sort.Slice(clicks, func(i, j int) bool {
+        return clicks[i].Timestamp.Before(clicks[j].Timestamp)
+    })
+    return clicks, nil
+}
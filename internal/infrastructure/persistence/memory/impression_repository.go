@@ -0,0 +1,29 @@
+package memory
+
+import (
+    "context"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+)
+
+type impressionRepository struct {
+    store *Store
+}
+
+func NewImpressionRepository(store *Store) repository.ImpressionRepository {
+    return &impressionRepository{
+        store: store,
+    }
+}
+
+func (r *impressionRepository) SaveBatch(ctx context.Context, impressions []*entity.Impression) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for _, impression := range impressions {
+        r.store.impressions.add(impression.TenantID, impression.BannerID, impression.VariantID,
+            impression.Timestamp, int64(impression.Count))
+    }
+    return nil
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "clicker/internal/domain/repository"
+)
+
+// quotaRetention is how long a day's counter is kept after the day starts,
+// like the expiry of the Redis counters.
+const quotaRetention = 48 * time.Hour
+
+type quotaKey struct {
+    tenantID int64
+    day      int64
+}
+
+type quotaRepository struct {
+    mu     sync.Mutex
+    clicks map[quotaKey]int64
+}
+
+func NewQuotaRepository() repository.QuotaRepository {
+    return &quotaRepository{
+        clicks: make(map[quotaKey]int64),
+    }
+}
+
+func (r *quotaRepository) IncrDailyClicks(ctx context.Context, tenantID int64, day time.Time, n int64) (int64, error) {
+    day = day.UTC()
+    start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    // Счётчики прошедших дней больше не нужны.
+    expired := time.Now().Add(-quotaRetention).Unix()
+    for key := range r.clicks {
+        if key.day < expired {
+            delete(r.clicks, key)
+        }
+    }
+
+    key := quotaKey{tenantID: tenantID, day: start.Unix()}
+    r.clicks[key] += n
+    return r.clicks[key], nil
+}
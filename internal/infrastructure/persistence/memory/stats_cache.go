@@ -0,0 +1,102 @@
+package memory
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+type cacheEntry struct {
+    value   []byte
+    expires time.Time
+}
+
+// statsCache keeps entries only for the current watermark of each banner:
+// moving the watermark drops the entries of the old one.
+type statsCache struct {
+    mu         sync.Mutex
+    watermarks map[bannerKey]int64
+    entries    map[bannerKey]map[string]cacheEntry
+}
+
+func NewStatsCache() repository.StatsCache {
+    return &statsCache{
+        watermarks: make(map[bannerKey]int64),
+        entries:    make(map[bannerKey]map[string]cacheEntry),
+    }
+}
+
+func (c *statsCache) Watermark(ctx context.Context, bannerID int64) (int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    return c.watermarks[bannerKey{tenantID: tenantID, bannerID: bannerID}], nil
+}
+
+func (c *statsCache) Advance(ctx context.Context, updates []*entity.ClickUpdate) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    for _, u := range updates {
+        key := bannerKey{tenantID: u.TenantID, bannerID: u.BannerID}
+        c.watermarks[key]++
+        delete(c.entries, key)
+    }
+    return nil
+}
+
+func (c *statsCache) Get(ctx context.Context, bannerID, watermark int64, key string) ([]byte, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    banner := bannerKey{tenantID: tenantID, bannerID: bannerID}
+    if c.watermarks[banner] != watermark {
+        return nil, nil
+    }
+    entry, ok := c.entries[banner][key]
+    if !ok {
+        return nil, nil
+    }
+    if time.Now().After(entry.expires) {
+        delete(c.entries[banner], key)
+        return nil, nil
+    }
+    return entry.value, nil
+}
+
+func (c *statsCache) Set(ctx context.Context, bannerID, watermark int64, key string, value []byte, ttl time.Duration) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    // Значение, посчитанное до сдвига водяного знака, уже устарело.
+    banner := bannerKey{tenantID: tenantID, bannerID: bannerID}
+    if c.watermarks[banner] != watermark {
+        return nil
+    }
+    entries, ok := c.entries[banner]
+    if !ok {
+        entries = make(map[string]cacheEntry)
+        c.entries[banner] = entries
+    }
+    entries[key] = cacheEntry{value: value, expires: time.Now().Add(ttl)}
+    return nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+)
+
+type statsRepository struct {
+    clickRepository
+}
+
+func NewStatsRepository(store *Store) repository.StatsRepository {
+    return &statsRepository{clickRepository{store: store}}
+}
+
+func NewTopBannersRepository(store *Store) repository.TopBannersRepository {
+    return &statsRepository{clickRepository{store: store}}
+}
+
+func NewBannerTotalsRepository(store *Store) repository.BannerTotalsRepository {
+    return &statsRepository{clickRepository{store: store}}
+}
+
+func (r *statsRepository) GetSeries(ctx context.Context, bannerID int64, from, to time.Time,
+    granularity string, loc *time.Location) ([]*entity.Click, error) {
+    clicks, err := r.GetStats(ctx, bannerID, from, to)
+    if err != nil {
+        return nil, err
+    }
+
+    buckets := make(map[int64]*entity.Click)
+    var series []*entity.Click
+    for _, click := range clicks {
+        start := timeseries.Truncate(click.Timestamp, granularity, loc)
+        if bucket, ok := buckets[start.Unix()]; ok {
+            bucket.Count += click.Count
+            continue
+        }
+        bucket := &entity.Click{BannerID: bannerID, Timestamp: start, Count: click.Count}
+        buckets[start.Unix()] = bucket
+        series = append(series, bucket)
+    }
+
+    return series, nil
+}
+
+func (r *statsRepository) GetTotals(ctx context.Context, bannerIDs []int64, from, to time.Time) (map[int64]int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    freshness.Record(ctx, entity.TierRaw, from, to)
+    totals := make(map[int64]int64, len(bannerIDs))
+    for _, bannerID := range bannerIDs {
+        r.store.each(tenantID, bannerID, from, to, func(_ time.Time, count int64) {
+            totals[bannerID] += count
+        })
+    }
+    return totals, nil
+}
+
+func (r *statsRepository) TopBanners(ctx context.Context, from, to time.Time, limit int) ([]*entity.BannerTotal, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    freshness.Record(ctx, entity.TierRaw, from, to)
+    r.store.mu.RLock()
+    var top []*entity.BannerTotal
+    for bannerID := range r.store.clicked[tenantID] {
+        total := &entity.BannerTotal{BannerID: bannerID}
+        r.store.each(tenantID, bannerID, from, to, func(_ time.Time, count int64) {
+            total.Clicks += count
+        })
+        if total.Clicks > 0 {
+            top = append(top, total)
+        }
+    }
+    r.store.mu.RUnlock()
+
+    sort.Slice(top, func(i, j int) bool {
+        if top[i].Clicks != top[j].Clicks {
+            return top[i].Clicks > top[j].Clicks
+        }
+        return top[i].BannerID < top[j].BannerID
+    })
+    if len(top) > limit {
+        top = top[:limit]
+    }
+    return top, nil
+}
+
+func (r *statsRepository) GetTotal(ctx context.Context, bannerID int64) (int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return r.store.totals[bannerKey{tenantID: tenantID, bannerID: bannerID}], nil
+}
@@ -0,0 +1,216 @@
+package memory
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "clicker/internal/domain/audit"
+    "clicker/internal/domain/entity"
+    "clicker/pkg/hll"
+)
+
+// hourKey names one hour of clicks of a banner, like the hourly hashes in
+// Redis.
+type hourKey struct {
+    tenantID int64
+    bannerID int64
+    hour     int64
+}
+
+type bannerKey struct {
+    tenantID int64
+    bannerID int64
+}
+
+// hourSpan is the first and last hour a banner has counts in.
+type hourSpan struct {
+    first, last int64
+}
+
+// series keeps counts per banner, bucketed by hour with a counter per
+// second and variant. Clicks without a variant count under variant 0.
+type series struct {
+    hours map[hourKey]map[int64]map[int64]int64
+    spans map[bannerKey]hourSpan
+}
+
+func newSeries() *series {
+    return &series{
+        hours: make(map[hourKey]map[int64]map[int64]int64),
+        spans: make(map[bannerKey]hourSpan),
+    }
+}
+
+func (s *series) add(tenantID, bannerID, variantID int64, ts time.Time, count int64) {
+    hour := ts.Truncate(time.Hour).Unix()
+    key := hourKey{tenantID: tenantID, bannerID: bannerID, hour: hour}
+    seconds, ok := s.hours[key]
+    if !ok {
+        seconds = make(map[int64]map[int64]int64)
+        s.hours[key] = seconds
+    }
+    variants, ok := seconds[ts.Unix()]
+    if !ok {
+        variants = make(map[int64]int64)
+        seconds[ts.Unix()] = variants
+    }
+    variants[variantID] += count
+
+    bk := bannerKey{tenantID: tenantID, bannerID: bannerID}
+    span, ok := s.spans[bk]
+    switch {
+    case !ok:
+        span = hourSpan{first: hour, last: hour}
+    case hour < span.first:
+        span.first = hour
+    case hour > span.last:
+        span.last = hour
+    }
+    s.spans[bk] = span
+}
+
+// eachVariant calls fn for every second and variant of the banner with
+// counts in [from, to). Only the hours between the first and last the
+// banner has counts in are walked, so an open range costs no more than
+// the banner's history. The caller holds the lock.
+func (s *series) eachVariant(tenantID, bannerID int64, from, to time.Time,
+    fn func(ts time.Time, variantID, count int64)) {
+    span, ok := s.spans[bannerKey{tenantID: tenantID, bannerID: bannerID}]
+    if !ok {
+        return
+    }
+    first, last := from.Truncate(time.Hour), to
+    if start := time.Unix(span.first, 0); first.Before(start) {
+        first = start
+    }
+    if end := time.Unix(span.last, 0).Add(time.Hour); last.After(end) {
+        last = end
+    }
+
+    for hour := first; hour.Before(last); hour = hour.Add(time.Hour) {
+        seconds := s.hours[hourKey{tenantID: tenantID, bannerID: bannerID, hour: hour.Unix()}]
+        for sec, variants := range seconds {
+            ts := time.Unix(sec, 0)
+            if ts.Before(from) || !ts.Before(to) {
+                continue
+            }
+            for variantID, count := range variants {
+                fn(ts, variantID, count)
+            }
+        }
+    }
+}
+
+// each is eachVariant summed over the variants of each second.
+func (s *series) each(tenantID, bannerID int64, from, to time.Time, fn func(ts time.Time, count int64)) {
+    sums := make(map[int64]int64)
+    s.eachVariant(tenantID, bannerID, from, to, func(ts time.Time, _ int64, count int64) {
+        sums[ts.Unix()] += count
+    })
+    for sec, count := range sums {
+        fn(time.Unix(sec, 0), count)
+    }
+}
+
+// Store keeps everything the memory backend has in process memory: clicks
+// and impressions, unique clicker sketches and the banners, variants and
+// tenants they belong to. It is shared by the memory repositories and lost
+// on restart.
+type Store struct {
+    mu sync.RWMutex
+
+    clicks      *series
+    impressions *series
+    // clicked lists the banners of each tenant that got clicks.
+    clicked map[int64]map[int64]struct{}
+    // totals are all-time clicks per banner.
+    totals map[bannerKey]int64
+    // uniques are the clicker sketches per banner and hour.
+    uniques map[hourKey]*hll.Sketch
+
+    tenants   map[int64]*storedTenant
+    banners   map[int64]*storedBanner
+    variants  map[int64]*entity.Variant
+    revisions map[int64][]*entity.BannerRevision
+    // last* are the last IDs handed out, like Postgres sequences.
+    lastTenant, lastBanner, lastVariant, lastRevision int64
+}
+
+type storedTenant struct {
+    tenant     entity.Tenant
+    apiKeyHash string
+}
+
+type storedBanner struct {
+    banner  entity.Banner
+    deleted bool
+}
+
+func NewStore() *Store {
+    return &Store{
+        clicks:      newSeries(),
+        impressions: newSeries(),
+        clicked:     make(map[int64]map[int64]struct{}),
+        totals:      make(map[bannerKey]int64),
+        uniques:     make(map[hourKey]*hll.Sketch),
+        tenants:     make(map[int64]*storedTenant),
+        banners:     make(map[int64]*storedBanner),
+        variants:    make(map[int64]*entity.Variant),
+        revisions:   make(map[int64][]*entity.BannerRevision),
+    }
+}
+
+func (s *Store) add(click *entity.Click) {
+    count := int64(click.Count)
+    s.clicks.add(click.TenantID, click.BannerID, click.VariantID, click.Timestamp, count)
+
+    banners, ok := s.clicked[click.TenantID]
+    if !ok {
+        banners = make(map[int64]struct{})
+        s.clicked[click.TenantID] = banners
+    }
+    banners[click.BannerID] = struct{}{}
+
+    s.totals[bannerKey{tenantID: click.TenantID, bannerID: click.BannerID}] += count
+}
+
+// each calls fn for every second of the banner with clicks in [from, to).
+// The caller holds the lock.
+func (s *Store) each(tenantID, bannerID int64, from, to time.Time, fn func(ts time.Time, count int64)) {
+    s.clicks.each(tenantID, bannerID, from, to, fn)
+}
+
+// banner returns the tenant's banner, nil when there is none. Deleted
+// banners are returned too. The caller holds the lock.
+func (s *Store) banner(tenantID, bannerID int64) *storedBanner {
+    stored, ok := s.banners[bannerID]
+    if !ok || stored.banner.TenantID != tenantID {
+        return nil
+    }
+    return stored
+}
+
+// addRevision records a history entry, taking the actor from the request
+// context like the Postgres repositories do. The caller holds the lock.
+func (s *Store) addRevision(ctx context.Context, revision *entity.BannerRevision) {
+    if revision.Changes == nil {
+        revision.Changes = []entity.FieldChange{}
+    }
+    revision.Actor = audit.Actor(ctx)
+    s.lastRevision++
+    revision.ID = s.lastRevision
+    revision.CreatedAt = time.Now()
+    stored := *revision
+    s.revisions[revision.BannerID] = append(s.revisions[revision.BannerID], &stored)
+}
+
+// copyBanner keeps callers from changing stored banners behind the lock.
+func copyBanner(banner *entity.Banner) *entity.Banner {
+    c := *banner
+    c.Labels = make(map[string]string, len(banner.Labels))
+    for key, value := range banner.Labels {
+        c.Labels[key] = value
+    }
+    return &c
+}
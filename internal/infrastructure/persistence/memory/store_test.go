@@ -0,0 +1,193 @@
+package memory
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
+)
+
+func tenantContext(tenantID int64) context.Context {
+    return tenant.NewContext(context.Background(), &entity.Tenant{ID: tenantID})
+}
+
+func click(tenantID, bannerID, variantID int64, ts time.Time, count int) *entity.Click {
+    return &entity.Click{TenantID: tenantID, BannerID: bannerID, VariantID: variantID, Timestamp: ts, Count: count}
+}
+
+func TestStatsBucketing(t *testing.T) {
+    store := NewStore()
+    clicks := NewClickRepository(store)
+    stats := NewStatsRepository(store)
+    ctx := tenantContext(1)
+
+    hour := time.Date(2024, 3, 10, 14, 0, 0, 0, time.UTC)
+    err := clicks.SaveBatch(ctx, []*entity.Click{
+        click(1, 7, 0, hour.Add(-time.Second), 1),
+        click(1, 7, 0, hour, 2),
+        // Одна секунда с разными вариантами складывается в одну строку.
+        click(1, 7, 3, hour.Add(30*time.Minute), 1),
+        click(1, 7, 4, hour.Add(30*time.Minute), 4),
+        click(1, 7, 0, hour.Add(30*time.Minute+500*time.Millisecond), 1),
+        click(1, 7, 0, hour.Add(time.Hour), 5),
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    rows, err := stats.GetStats(ctx, 7, hour, hour.Add(time.Hour))
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []struct {
+        ts    time.Time
+        count int
+    }{
+        {hour, 2},
+        {hour.Add(30 * time.Minute), 6},
+    }
+    if len(rows) != len(want) {
+        t.Fatalf("GetStats returned %d rows, want %d", len(rows), len(want))
+    }
+    for i, w := range want {
+        if !rows[i].Timestamp.Equal(w.ts) || rows[i].Count != w.count {
+            t.Errorf("row %d = %v %d, want %v %d", i, rows[i].Timestamp, rows[i].Count, w.ts, w.count)
+        }
+    }
+
+    series, err := stats.GetSeries(ctx, 7, time.Unix(0, 0), hour.AddDate(1, 0, 0), timeseries.Hour, time.UTC)
+    if err != nil {
+        t.Fatal(err)
+    }
+    wantSeries := map[time.Time]int{hour.Add(-time.Hour): 1, hour: 8, hour.Add(time.Hour): 5}
+    if len(series) != len(wantSeries) {
+        t.Fatalf("GetSeries returned %d buckets, want %d", len(series), len(wantSeries))
+    }
+    for _, bucket := range series {
+        if wantSeries[bucket.Timestamp.UTC()] != bucket.Count {
+            t.Errorf("bucket %v = %d, want %d", bucket.Timestamp, bucket.Count, wantSeries[bucket.Timestamp.UTC()])
+        }
+    }
+
+    totals, err := stats.GetTotals(ctx, []int64{7, 8}, hour, hour.Add(2*time.Hour))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(totals) != 1 || totals[7] != 13 {
+        t.Fatalf("GetTotals = %v, want map[7:13]", totals)
+    }
+
+    var exported []*entity.Click
+    err = NewClickExportRepository(store).ExportClicks(ctx, []int64{7}, hour.Add(30*time.Minute), hour.Add(31*time.Minute), false,
+        func(c *entity.Click) error {
+            exported = append(exported, c)
+            return nil
+        })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(exported) != 3 || exported[0].VariantID != 0 || exported[1].VariantID != 3 || exported[2].VariantID != 4 {
+        t.Fatalf("ExportClicks returned %d rows, want one per variant", len(exported))
+    }
+}
+
+func TestTenantIsolation(t *testing.T) {
+    store := NewStore()
+    clicks := NewClickRepository(store)
+    stats := NewStatsRepository(store)
+    top := NewTopBannersRepository(store)
+    totals := NewBannerTotalsRepository(store)
+    banners := NewBannerRepository(store)
+    first, second := tenantContext(1), tenantContext(2)
+
+    banner := &entity.Banner{Name: "b", Status: entity.BannerStatusActive, Placement: "top", Weight: 1}
+    if err := banners.Create(first, banner); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := banners.GetByID(second, banner.ID); !errors.Is(err, repository.ErrBannerNotFound) {
+        t.Fatalf("GetByID of another tenant's banner: err = %v, want ErrBannerNotFound", err)
+    }
+    if err := banners.Delete(second, banner.ID); !errors.Is(err, repository.ErrBannerNotFound) {
+        t.Fatalf("Delete of another tenant's banner: err = %v, want ErrBannerNotFound", err)
+    }
+    servable, err := banners.FindServable(second, "top")
+    if err != nil || len(servable) != 0 {
+        t.Fatalf("FindServable of another tenant = %d banners, %v", len(servable), err)
+    }
+
+    now := time.Date(2024, 3, 10, 14, 0, 0, 0, time.UTC)
+    err = clicks.SaveBatch(first, []*entity.Click{
+        click(1, banner.ID, 0, now, 3),
+        click(2, banner.ID, 0, now, 5),
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    for _, c := range []struct {
+        ctx  context.Context
+        want int64
+    }{
+        {first, 3},
+        {second, 5},
+    } {
+        rows, err := stats.GetStats(c.ctx, banner.ID, now, now.Add(time.Hour))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(rows) != 1 || int64(rows[0].Count) != c.want {
+            t.Errorf("GetStats = %d rows, want one of %d clicks", len(rows), c.want)
+        }
+
+        leaders, err := top.TopBanners(c.ctx, now, now.Add(time.Hour), 10)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(leaders) != 1 || leaders[0].Clicks != c.want {
+            t.Errorf("TopBanners = %d banners, want one with %d clicks", len(leaders), c.want)
+        }
+
+        total, err := totals.GetTotal(c.ctx, banner.ID)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if total != c.want {
+            t.Errorf("GetTotal = %d, want %d", total, c.want)
+        }
+    }
+
+    if _, err := stats.GetStats(context.Background(), banner.ID, now, now.Add(time.Hour)); !errors.Is(err, tenant.ErrNoTenant) {
+        t.Fatalf("GetStats without a tenant: err = %v, want ErrNoTenant", err)
+    }
+}
+
+func TestFindServableCap(t *testing.T) {
+    store := NewStore()
+    banners := NewBannerRepository(store)
+    impressions := NewImpressionRepository(store)
+    ctx := tenantContext(1)
+
+    banner := &entity.Banner{Status: entity.BannerStatusActive, Placement: "top", Weight: 1, DailyImpressionCap: 2}
+    if err := banners.Create(ctx, banner); err != nil {
+        t.Fatal(err)
+    }
+
+    for shown := 0; shown < 3; shown++ {
+        servable, err := banners.FindServable(ctx, "top")
+        if err != nil {
+            t.Fatal(err)
+        }
+        if want := shown < banner.DailyImpressionCap; (len(servable) == 1) != want {
+            t.Fatalf("after %d impressions servable = %v, want %v", shown, len(servable) == 1, want)
+        }
+        err = impressions.SaveBatch(ctx, []*entity.Impression{{TenantID: 1, BannerID: banner.ID, Timestamp: time.Now(), Count: 1}})
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+}
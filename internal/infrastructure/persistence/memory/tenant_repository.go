@@ -0,0 +1,112 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+)
+
+type tenantRepository struct {
+    store *Store
+}
+
+func NewTenantRepository(store *Store) repository.TenantRepository {
+    return &tenantRepository{
+        store: store,
+    }
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant *entity.Tenant, apiKeyHash string) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    r.store.lastTenant++
+    tenant.ID = r.store.lastTenant
+    tenant.CreatedAt = time.Now()
+    r.store.tenants[tenant.ID] = &storedTenant{tenant: *tenant, apiKeyHash: apiKeyHash}
+    return nil
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, tenantID int64) (*entity.Tenant, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    stored, ok := r.store.tenants[tenantID]
+    if !ok {
+        return nil, repository.ErrTenantNotFound
+    }
+    tenant := stored.tenant
+    return &tenant, nil
+}
+
+func (r *tenantRepository) GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*entity.Tenant, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    for _, stored := range r.store.tenants {
+        if stored.apiKeyHash == apiKeyHash {
+            tenant := stored.tenant
+            return &tenant, nil
+        }
+    }
+    return nil, repository.ErrTenantNotFound
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]*entity.Tenant, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    tenants := make([]*entity.Tenant, 0, len(r.store.tenants))
+    for _, stored := range r.store.tenants {
+        tenant := stored.tenant
+        tenants = append(tenants, &tenant)
+    }
+
+    sort.Slice(tenants, func(i, j int) bool {
+        return tenants[i].ID < tenants[j].ID
+    })
+    return tenants, nil
+}
+
+func (r *tenantRepository) UpdateQuota(ctx context.Context, tenant *entity.Tenant) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    stored, ok := r.store.tenants[tenant.ID]
+    if !ok {
+        return repository.ErrTenantNotFound
+    }
+    stored.tenant.MaxBanners = tenant.MaxBanners
+    stored.tenant.MaxDailyClicks = tenant.MaxDailyClicks
+    tenant.Name = stored.tenant.Name
+    tenant.CreatedAt = stored.tenant.CreatedAt
+    return nil
+}
+
+func (r *tenantRepository) SetAPIKeyHash(ctx context.Context, tenantID int64, apiKeyHash string) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    stored, ok := r.store.tenants[tenantID]
+    if !ok {
+        return repository.ErrTenantNotFound
+    }
+    stored.apiKeyHash = apiKeyHash
+    return nil
+}
+
+func (r *tenantRepository) CountBanners(ctx context.Context, tenantID int64) (int, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var count int
+    for _, stored := range r.store.banners {
+        if stored.banner.TenantID == tenantID && !stored.deleted {
+            count++
+        }
+    }
+    return count, nil
+}
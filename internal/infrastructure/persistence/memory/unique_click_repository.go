@@ -0,0 +1,71 @@
+package memory
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/pkg/hll"
+)
+
+type uniqueClickRepository struct {
+    store *Store
+}
+
+func NewUniqueClickRepository(store *Store) repository.UniqueClickRepository {
+    return &uniqueClickRepository{
+        store: store,
+    }
+}
+
+func (r *uniqueClickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for _, click := range clicks {
+        if click.ClickerID == "" {
+            continue
+        }
+        key := hourKey{tenantID: click.TenantID, bannerID: click.BannerID, hour: click.Timestamp.Truncate(time.Hour).Unix()}
+        sketch, ok := r.store.uniques[key]
+        if !ok {
+            sketch = hll.New()
+            r.store.uniques[key] = sketch
+        }
+        sketch.AddString(click.ClickerID)
+    }
+    return nil
+}
+
+// GetSketch walks only the hours the banner has clicks in, like the click
+// reads of the store.
+func (r *uniqueClickRepository) GetSketch(ctx context.Context, bannerID int64, from, to time.Time) (*hll.Sketch, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    sketch := hll.New()
+    span, ok := r.store.clicks.spans[bannerKey{tenantID: tenantID, bannerID: bannerID}]
+    if !ok {
+        return sketch, nil
+    }
+    first, last := from.Truncate(time.Hour).Unix(), to.Unix()
+    if first < span.first {
+        first = span.first
+    }
+    if last > span.last+3600 {
+        last = span.last + 3600
+    }
+    for hour := first; hour < last; hour += 3600 {
+        if bucket, ok := r.store.uniques[hourKey{tenantID: tenantID, bannerID: bannerID, hour: hour}]; ok {
+            sketch.Merge(bucket)
+        }
+    }
+    return sketch, nil
+}
@@ -0,0 +1,171 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+type variantRepository struct {
+    store *Store
+}
+
+func NewVariantRepository(store *Store) repository.VariantRepository {
+    return &variantRepository{
+        store: store,
+    }
+}
+
+func NewVariantStatsRepository(store *Store) repository.VariantStatsRepository {
+    return &variantRepository{
+        store: store,
+    }
+}
+
+func (r *variantRepository) Create(ctx context.Context, variant *entity.Variant) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    banner := r.store.banner(tenantID, variant.BannerID)
+    if banner == nil || banner.deleted {
+        return repository.ErrBannerNotFound
+    }
+
+    r.store.lastVariant++
+    variant.ID = r.store.lastVariant
+    stored := *variant
+    r.store.variants[variant.ID] = &stored
+
+    r.store.addRevision(ctx, &entity.BannerRevision{
+        TenantID:  tenantID,
+        BannerID:  variant.BannerID,
+        VariantID: variant.ID,
+        Action:    entity.RevisionActionVariantCreate,
+        Changes:   entity.DiffVariant(nil, variant),
+    })
+    return nil
+}
+
+func (r *variantRepository) Update(ctx context.Context, variant *entity.Variant) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    stored, ok := r.store.variants[variant.ID]
+    if !ok || stored.BannerID != variant.BannerID {
+        return repository.ErrVariantNotFound
+    }
+    if banner := r.store.banner(tenantID, stored.BannerID); banner == nil || banner.deleted {
+        return repository.ErrVariantNotFound
+    }
+
+    before := *stored
+    stored.Name = variant.Name
+    stored.CreativeURL = variant.CreativeURL
+    stored.Weight = variant.Weight
+
+    if changes := entity.DiffVariant(&before, variant); len(changes) > 0 {
+        r.store.addRevision(ctx, &entity.BannerRevision{
+            TenantID:  tenantID,
+            BannerID:  variant.BannerID,
+            VariantID: variant.ID,
+            Action:    entity.RevisionActionVariantUpdate,
+            Changes:   changes,
+        })
+    }
+    return nil
+}
+
+func (r *variantRepository) GetByID(ctx context.Context, variantID int64) (*entity.Variant, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    stored, ok := r.store.variants[variantID]
+    if !ok || r.store.banner(tenantID, stored.BannerID) == nil {
+        return nil, repository.ErrVariantNotFound
+    }
+    variant := *stored
+    return &variant, nil
+}
+
+func (r *variantRepository) ListByBanner(ctx context.Context, bannerID int64) ([]*entity.Variant, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return r.listByBanner(tenantID, bannerID), nil
+}
+
+// listByBanner returns copies of the variants ordered by ID, also of a
+// deleted banner. The caller holds the lock.
+func (r *variantRepository) listByBanner(tenantID, bannerID int64) []*entity.Variant {
+    if r.store.banner(tenantID, bannerID) == nil {
+        return nil
+    }
+
+    var variants []*entity.Variant
+    for _, stored := range r.store.variants {
+        if stored.BannerID == bannerID {
+            variant := *stored
+            variants = append(variants, &variant)
+        }
+    }
+
+    sort.Slice(variants, func(i, j int) bool {
+        return variants[i].ID < variants[j].ID
+    })
+    return variants
+}
+
+func (r *variantRepository) GetVariantStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.VariantStats, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    impressions := make(map[int64]int64)
+    r.store.impressions.eachVariant(tenantID, bannerID, from, to, func(_ time.Time, variantID, count int64) {
+        impressions[variantID] += count
+    })
+    clicks := make(map[int64]int64)
+    r.store.clicks.eachVariant(tenantID, bannerID, from, to, func(_ time.Time, variantID, count int64) {
+        clicks[variantID] += count
+    })
+
+    var stats []*entity.VariantStats
+    for _, variant := range r.listByBanner(tenantID, bannerID) {
+        stats = append(stats, &entity.VariantStats{
+            VariantID:   variant.ID,
+            Name:        variant.Name,
+            Weight:      variant.Weight,
+            Impressions: impressions[variant.ID],
+            Clicks:      clicks[variant.ID],
+        })
+    }
+    return stats, nil
+}
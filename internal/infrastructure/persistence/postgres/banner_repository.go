@@ -0,0 +1,338 @@
+package postgres
+
+import (
+    "context"
+    "errors"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+const bannerColumns = `b.id, b.tenant_id, b.name, b.url, b.creative_url, b.placement, b.status, b.weight, b.daily_impression_cap`
+
+type bannerRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewBannerRepository(db *pgxpool.Pool) repository.BannerRepository {
+    return &bannerRepository{
+        db: db,
+    }
+}
+
+func (r *bannerRepository) Create(ctx context.Context, banner *entity.Banner) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    banner.TenantID = tenantID
+    err = tx.QueryRow(ctx, `
+        INSERT INTO banners (tenant_id, name, url, creative_url, placement, status, weight, daily_impression_cap)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id
+    `, tenantID, banner.Name, banner.URL, banner.CreativeURL, banner.Placement, banner.Status,
+        banner.Weight, banner.DailyImpressionCap).Scan(&banner.ID)
+    if err != nil {
+        return err
+    }
+
+    err = insertRevision(ctx, tx, &entity.BannerRevision{
+        TenantID: tenantID,
+        BannerID: banner.ID,
+        Action:   entity.RevisionActionCreate,
+        Changes:  entity.DiffBanner(nil, banner),
+    })
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *bannerRepository) Update(ctx context.Context, banner *entity.Banner) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    before, err := scanBanner(tx.QueryRow(ctx, `
+        SELECT `+bannerColumns+`
+        FROM banners b
+        WHERE b.id = $1 AND b.tenant_id = $2 AND b.deleted_at IS NULL
+        FOR UPDATE
+    `, banner.ID, tenantID))
+    if errors.Is(err, pgx.ErrNoRows) {
+        return repository.ErrBannerNotFound
+    }
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.Exec(ctx, `
+        UPDATE banners
+        SET name = $2, url = $3, creative_url = $4, placement = $5, status = $6,
+            weight = $7, daily_impression_cap = $8
+        WHERE id = $1
+    `, banner.ID, banner.Name, banner.URL, banner.CreativeURL, banner.Placement, banner.Status,
+        banner.Weight, banner.DailyImpressionCap)
+    if err != nil {
+        return err
+    }
+
+    if changes := entity.DiffBanner(before, banner); len(changes) > 0 {
+        err = insertRevision(ctx, tx, &entity.BannerRevision{
+            TenantID: tenantID,
+            BannerID: banner.ID,
+            Action:   entity.RevisionActionUpdate,
+            Changes:  changes,
+        })
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *bannerRepository) Delete(ctx context.Context, bannerID int64) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    tag, err := tx.Exec(ctx, `
+        UPDATE banners
+        SET deleted_at = now()
+        WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+    `, bannerID, tenantID)
+    if err != nil {
+        return err
+    }
+    if tag.RowsAffected() == 0 {
+        return repository.ErrBannerNotFound
+    }
+
+    err = insertRevision(ctx, tx, &entity.BannerRevision{
+        TenantID: tenantID,
+        BannerID: bannerID,
+        Action:   entity.RevisionActionDelete,
+    })
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *bannerRepository) GetByID(ctx context.Context, bannerID int64) (*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    banner, err := scanBanner(r.db.QueryRow(ctx, `
+        SELECT `+bannerColumns+`
+        FROM banners b
+        WHERE b.id = $1 AND b.tenant_id = $2 AND b.deleted_at IS NULL
+    `, bannerID, tenantID))
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, repository.ErrBannerNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    if err := r.loadLabels(ctx, []*entity.Banner{banner}); err != nil {
+        return nil, err
+    }
+
+    return banner, nil
+}
+
+func (r *bannerRepository) SetLabels(ctx context.Context, bannerID int64, labels map[string]string) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    var exists bool
+    err = tx.QueryRow(ctx, `
+        SELECT EXISTS(SELECT 1 FROM banners WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL)
+    `, bannerID, tenantID).Scan(&exists)
+    if err != nil {
+        return err
+    }
+    if !exists {
+        return repository.ErrBannerNotFound
+    }
+
+    if _, err = tx.Exec(ctx, `DELETE FROM banner_labels WHERE banner_id = $1`, bannerID); err != nil {
+        return err
+    }
+
+    batch := &pgx.Batch{}
+    for key, value := range labels {
+        batch.Queue(
+            "INSERT INTO banner_labels (banner_id, key, value) VALUES ($1, $2, $3)",
+            bannerID, key, value,
+        )
+    }
+    if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *bannerRepository) FindByLabels(ctx context.Context, selector map[string]string) ([]*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    keys := make([]string, 0, len(selector))
+    values := make([]string, 0, len(selector))
+    for key, value := range selector {
+        keys = append(keys, key)
+        values = append(values, value)
+    }
+
+    return r.queryBanners(ctx, `
+        SELECT `+bannerColumns+`
+        FROM banners b
+        JOIN banner_labels l ON l.banner_id = b.id
+        JOIN unnest($1::text[], $2::text[]) AS s(key, value)
+            ON s.key = l.key AND s.value = l.value
+        WHERE b.tenant_id = $4
+        GROUP BY b.id
+        HAVING COUNT(*) = $3
+        ORDER BY b.id
+    `, keys, values, len(selector), tenantID)
+}
+
+func (r *bannerRepository) FindServable(ctx context.Context, placement string) ([]*entity.Banner, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    return r.queryBanners(ctx, `
+        SELECT `+bannerColumns+`
+        FROM banners b
+        LEFT JOIN (
+            SELECT banner_id, SUM(count) AS shown
+            FROM impressions
+            WHERE banner_id IN (SELECT id FROM banners WHERE tenant_id = $2 AND placement = $1)
+            AND timestamp >= date_trunc('day', now(), 'UTC')
+            GROUP BY banner_id
+        ) i ON i.banner_id = b.id
+        WHERE b.tenant_id = $2
+        AND b.placement = $1
+        AND b.deleted_at IS NULL
+        AND b.status = 'active'
+        AND b.weight > 0
+        AND (b.daily_impression_cap = 0 OR COALESCE(i.shown, 0) < b.daily_impression_cap)
+        ORDER BY b.id
+    `, placement, tenantID)
+}
+
+func (r *bannerRepository) queryBanners(ctx context.Context, query string, args ...any) ([]*entity.Banner, error) {
+    rows, err := r.db.Query(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var banners []*entity.Banner
+    for rows.Next() {
+        banner, err := scanBanner(rows)
+        if err != nil {
+            return nil, err
+        }
+        banners = append(banners, banner)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    if err := r.loadLabels(ctx, banners); err != nil {
+        return nil, err
+    }
+
+    return banners, nil
+}
+
+func scanBanner(row pgx.Row) (*entity.Banner, error) {
+    banner := &entity.Banner{}
+    err := row.Scan(&banner.ID, &banner.TenantID, &banner.Name, &banner.URL, &banner.CreativeURL,
+        &banner.Placement, &banner.Status, &banner.Weight, &banner.DailyImpressionCap)
+    if err != nil {
+        return nil, err
+    }
+    return banner, nil
+}
+
+func (r *bannerRepository) loadLabels(ctx context.Context, banners []*entity.Banner) error {
+    if len(banners) == 0 {
+        return nil
+    }
+
+    byID := make(map[int64]*entity.Banner, len(banners))
+    ids := make([]int64, 0, len(banners))
+    for _, banner := range banners {
+        banner.Labels = make(map[string]string)
+        byID[banner.ID] = banner
+        ids = append(ids, banner.ID)
+    }
+
+    rows, err := r.db.Query(ctx, `
+        SELECT banner_id, key, value
+        FROM banner_labels
+        WHERE banner_id = ANY($1)
+    `, ids)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var (
+            bannerID   int64
+            key, value string
+        )
+        if err := rows.Scan(&bannerID, &key, &value); err != nil {
+            return err
+        }
+        byID[bannerID].Labels[key] = value
+    }
+
+    return rows.Err()
+}
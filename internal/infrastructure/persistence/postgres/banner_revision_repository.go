@@ -0,0 +1,81 @@
+package postgres
+
+import (
+    "context"
+    "encoding/json"
+
+    "clicker/internal/domain/audit"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type bannerRevisionRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewBannerRevisionRepository(db *pgxpool.Pool) repository.BannerRevisionRepository {
+    return &bannerRevisionRepository{
+        db: db,
+    }
+}
+
+func (r *bannerRevisionRepository) ListByBanner(ctx context.Context, bannerID int64) ([]*entity.BannerRevision, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    rows, err := r.db.Query(ctx, `
+        SELECT id, tenant_id, banner_id, COALESCE(variant_id, 0), action, actor, changes, created_at
+        FROM banner_revisions
+        WHERE banner_id = $1 AND tenant_id = $2
+        ORDER BY created_at, id
+    `, bannerID, tenantID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var revisions []*entity.BannerRevision
+    for rows.Next() {
+        var (
+            revision = &entity.BannerRevision{}
+            changes  []byte
+        )
+        err := rows.Scan(&revision.ID, &revision.TenantID, &revision.BannerID, &revision.VariantID,
+            &revision.Action, &revision.Actor, &changes, &revision.CreatedAt)
+        if err != nil {
+            return nil, err
+        }
+        if err := json.Unmarshal(changes, &revision.Changes); err != nil {
+            return nil, err
+        }
+        revisions = append(revisions, revision)
+    }
+
+    return revisions, rows.Err()
+}
+
+// insertRevision writes a history entry in the transaction of the change
+// itself, so a change is never stored without its revision. The actor is
+// taken from the request context.
+func insertRevision(ctx context.Context, tx pgx.Tx, revision *entity.BannerRevision) error {
+    if revision.Changes == nil {
+        revision.Changes = []entity.FieldChange{}
+    }
+    changes, err := json.Marshal(revision.Changes)
+    if err != nil {
+        return err
+    }
+
+    revision.Actor = audit.Actor(ctx)
+    return tx.QueryRow(ctx, `
+        INSERT INTO banner_revisions (tenant_id, banner_id, variant_id, action, actor, changes)
+        VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6)
+        RETURNING id, created_at
+    `, revision.TenantID, revision.BannerID, revision.VariantID, revision.Action, revision.Actor, changes,
+    ).Scan(&revision.ID, &revision.CreatedAt)
+}
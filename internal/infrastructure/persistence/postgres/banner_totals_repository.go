@@ -0,0 +1,43 @@
+package postgres
+
+import (
+    "context"
+    "errors"
+
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type bannerTotalsRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewBannerTotalsRepository(db *pgxpool.Pool) repository.BannerTotalsRepository {
+    return &bannerTotalsRepository{
+        db: db,
+    }
+}
+
+func (r *bannerTotalsRepository) GetTotal(ctx context.Context, bannerID int64) (int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    var total int64
+    err = r.db.QueryRow(ctx, `
+        SELECT clicks
+        FROM banner_totals
+        WHERE banner_id = $1 AND tenant_id = $2
+    `, bannerID, tenantID).Scan(&total)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return 0, nil
+    }
+    if err != nil {
+        return 0, err
+    }
+
+    return total, nil
+}
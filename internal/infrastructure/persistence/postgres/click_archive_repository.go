@@ -0,0 +1,60 @@
+package postgres
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type clickArchiveRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewClickArchiveRepository(db *pgxpool.Pool) repository.ClickArchiveRepository {
+    return &clickArchiveRepository{
+        db: db,
+    }
+}
+
+func (r *clickArchiveRepository) ReadPartition(ctx context.Context, name string, fn func(*entity.Click) error) error {
+    cursor := &clickExportRepository{db: r.db}
+    return cursor.withCursor(ctx, `
+        SELECT id, tenant_id, banner_id, COALESCE(variant_id, 0), timestamp, count
+        FROM `+pgx.Identifier{name}.Sanitize()+`
+        ORDER BY timestamp, id
+    `, nil, func(rows pgx.Rows) error {
+        click := &entity.Click{}
+        err := rows.Scan(&click.ID, &click.TenantID, &click.BannerID, &click.VariantID, &click.Timestamp, &click.Count)
+        if err != nil {
+            return err
+        }
+        return fn(click)
+    })
+}
+
+func (r *clickArchiveRepository) ClearRestored(ctx context.Context, from, to time.Time) error {
+    _, err := r.db.Exec(ctx, `
+        DELETE FROM restored_clicks WHERE timestamp >= $1 AND timestamp < $2
+    `, from, to)
+    return err
+}
+
+func (r *clickArchiveRepository) SaveRestored(ctx context.Context, clicks []*entity.Click) error {
+    _, err := r.db.CopyFrom(ctx,
+        pgx.Identifier{"restored_clicks"},
+        []string{"id", "tenant_id", "banner_id", "variant_id", "timestamp", "count"},
+        pgx.CopyFromSlice(len(clicks), func(i int) ([]any, error) {
+            c := clicks[i]
+            var variantID *int64
+            if c.VariantID != 0 {
+                variantID = &c.VariantID
+            }
+            return []any{c.ID, c.TenantID, c.BannerID, variantID, c.Timestamp, c.Count}, nil
+        }),
+    )
+    return err
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// exportFetchSize is how many rows one FETCH pulls from the export cursor.
+const exportFetchSize = 1000
+
+type clickExportRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewClickExportRepository(db *pgxpool.Pool) repository.ClickExportRepository {
+    return &clickExportRepository{
+        db: db,
+    }
+}
+
+func (r *clickExportRepository) ExportClicks(ctx context.Context, bannerIDs []int64, from, to time.Time, restored bool,
+    fn func(*entity.Click) error) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.withCursor(ctx, `
+        SELECT id, banner_id, COALESCE(variant_id, 0), timestamp, count
+        FROM `+exportTable(restored)+`
+        WHERE banner_id = ANY($1)
+        AND tenant_id = $4
+        AND timestamp >= $2
+        AND timestamp < $3
+        ORDER BY timestamp, id
+    `, []any{bannerIDs, from, to, tenantID}, func(rows pgx.Rows) error {
+        click := &entity.Click{TenantID: tenantID}
+        if err := rows.Scan(&click.ID, &click.BannerID, &click.VariantID, &click.Timestamp, &click.Count); err != nil {
+            return err
+        }
+        return fn(click)
+    })
+}
+
+func (r *clickExportRepository) ExportSeries(ctx context.Context, bannerIDs []int64, from, to time.Time,
+    granularity string, loc *time.Location, restored bool, fn func(*entity.Click) error) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return r.withCursor(ctx, `
+        SELECT banner_id, date_trunc($5, timestamp, $6) AS bucket, SUM(count)
+        FROM `+exportTable(restored)+`
+        WHERE banner_id = ANY($1)
+        AND tenant_id = $4
+        AND timestamp >= $2
+        AND timestamp < $3
+        GROUP BY banner_id, bucket
+        ORDER BY banner_id, bucket
+    `, []any{bannerIDs, from, to, tenantID, granularity, loc.String()}, func(rows pgx.Rows) error {
+        click := &entity.Click{TenantID: tenantID}
+        if err := rows.Scan(&click.BannerID, &click.Timestamp, &click.Count); err != nil {
+            return err
+        }
+        return fn(click)
+    })
+}
+
+func exportTable(restored bool) string {
+    if restored {
+        return "restored_clicks"
+    }
+    return "clicks"
+}
+
+// withCursor runs the query through a server-side cursor and fetches it in
+// pages, so neither pgx nor the server materializes the whole result. The
+// read-only repeatable read transaction keeps the export consistent even
+// though it may stay open for as long as the client takes to read it.
+func (r *clickExportRepository) withCursor(ctx context.Context, query string, args []any,
+    scan func(pgx.Rows) error) error {
+    tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+        IsoLevel:   pgx.RepeatableRead,
+        AccessMode: pgx.ReadOnly,
+    })
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    if _, err := tx.Exec(ctx, "DECLARE export_cursor NO SCROLL CURSOR FOR "+query, args...); err != nil {
+        return fmt.Errorf("failed to open export cursor: %w", err)
+    }
+
+    fetch := fmt.Sprintf("FETCH FORWARD %d FROM export_cursor", exportFetchSize)
+    for {
+        rows, err := tx.Query(ctx, fetch)
+        if err != nil {
+            return err
+        }
+
+        fetched := 0
+        for rows.Next() {
+            fetched++
+            if err := scan(rows); err != nil {
+                rows.Close()
+                return err
+            }
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return err
+        }
+
+        if fetched < exportFetchSize {
+            break
+        }
+    }
+
+    return tx.Commit(ctx)
+}
@@ -0,0 +1,233 @@
+package postgres
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "sort"
+    "strings"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// partitionLockKey is the advisory lock held while partitions are
+// maintained.
+const partitionLockKey = 7_042_001
+
+// Partitions are named after their range: clicks_pYYYYMM for a month and
+// clicks_pYYYYMMDD for a day, always in UTC.
+const (
+    partitionPrefix      = "clicks_p"
+    monthPartitionLayout = "200601"
+    dayPartitionLayout   = "20060102"
+    // defaultPartition catches clicks outside every range partition.
+    defaultPartition     = "clicks_default"
+)
+
+type clickPartitionRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewClickPartitionRepository(db *pgxpool.Pool) repository.ClickPartitionRepository {
+    return &clickPartitionRepository{
+        db: db,
+    }
+}
+
+func (r *clickPartitionRepository) List(ctx context.Context) ([]*entity.ClickPartition, error) {
+    rows, err := r.db.Query(ctx, `
+        SELECT c.relname
+        FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'clicks'::regclass
+        ORDER BY c.relname
+    `)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var partitions []*entity.ClickPartition
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            return nil, err
+        }
+        if name == defaultPartition {
+            continue
+        }
+        partition, ok := parsePartitionName(name)
+        if !ok {
+            log.Printf("Skipping clicks partition %s not named by its range", name)
+            continue
+        }
+        partitions = append(partitions, partition)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    // Дни и месяцы по имени сортируются неверно, сортируем по диапазону.
+    sort.Slice(partitions, func(i, j int) bool {
+        return partitions[i].From.Before(partitions[j].From)
+    })
+    return partitions, nil
+}
+
+// Create moves the clicks of [from, to) out of the default partition into
+// the new one: Postgres refuses to create a partition for a range the
+// default partition has rows in.
+func (r *clickPartitionRepository) Create(ctx context.Context, from, to time.Time) error {
+    name, err := partitionName(from, to)
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    // Пока секции нет, новые клики диапазона тоже идут в DEFAULT, так что
+    // её держим до конца транзакции.
+    _, err = tx.Exec(ctx, `LOCK TABLE `+pgx.Identifier{defaultPartition}.Sanitize()+` IN EXCLUSIVE MODE`)
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.Exec(ctx, `CREATE TEMP TABLE clicks_moving (LIKE clicks) ON COMMIT DROP`)
+    if err != nil {
+        return err
+    }
+    moved, err := tx.Exec(ctx, `
+        WITH moved AS (
+            DELETE FROM `+pgx.Identifier{defaultPartition}.Sanitize()+`
+            WHERE timestamp >= $1 AND timestamp < $2
+            RETURNING *
+        )
+        INSERT INTO clicks_moving SELECT * FROM moved
+    `, from, to)
+    if err != nil {
+        return err
+    }
+
+    // DDL не принимает параметры, границы подставляются литералами.
+    _, err = tx.Exec(ctx, fmt.Sprintf(
+        `CREATE TABLE IF NOT EXISTS %s PARTITION OF clicks FOR VALUES FROM ('%s') TO ('%s')`,
+        pgx.Identifier{name}.Sanitize(), from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339),
+    ))
+    if err != nil {
+        return err
+    }
+
+    if moved.RowsAffected() > 0 {
+        if _, err := tx.Exec(ctx, `INSERT INTO clicks SELECT * FROM clicks_moving`); err != nil {
+            return err
+        }
+        log.Printf("Moved %d clicks from %s to %s", moved.RowsAffected(), defaultPartition, name)
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *clickPartitionRepository) StrayDays(ctx context.Context) ([]time.Time, error) {
+    rows, err := r.db.Query(ctx, `
+        SELECT DISTINCT date_trunc('day', timestamp AT TIME ZONE 'UTC')
+        FROM `+pgx.Identifier{defaultPartition}.Sanitize()+`
+        ORDER BY 1
+    `)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var days []time.Time
+    for rows.Next() {
+        var day time.Time
+        if err := rows.Scan(&day); err != nil {
+            return nil, err
+        }
+        days = append(days, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC))
+    }
+    return days, rows.Err()
+}
+
+func (r *clickPartitionRepository) Detach(ctx context.Context, name string) error {
+    _, err := r.db.Exec(ctx, `ALTER TABLE clicks DETACH PARTITION `+pgx.Identifier{name}.Sanitize())
+    return err
+}
+
+func (r *clickPartitionRepository) Drop(ctx context.Context, name string) error {
+    _, err := r.db.Exec(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{name}.Sanitize())
+    return err
+}
+
+func (r *clickPartitionRepository) TryLock(ctx context.Context) (func(), bool, error) {
+    return tryAdvisoryLock(ctx, r.db, partitionLockKey)
+}
+
+// tryAdvisoryLock takes a session advisory lock, so it holds a pooled
+// connection until unlocked.
+func tryAdvisoryLock(ctx context.Context, db *pgxpool.Pool, key int64) (func(), bool, error) {
+    conn, err := db.Acquire(ctx)
+    if err != nil {
+        return nil, false, err
+    }
+
+    var locked bool
+    if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+        conn.Release()
+        return nil, false, err
+    }
+    if !locked {
+        conn.Release()
+        return nil, false, nil
+    }
+
+    unlock := func() {
+        if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, key); err != nil {
+            log.Printf("Failed to release advisory lock %d: %v", key, err)
+        }
+        conn.Release()
+    }
+    return unlock, true, nil
+}
+
+func partitionName(from, to time.Time) (string, error) {
+    from, to = from.UTC(), to.UTC()
+    switch {
+    case to.Equal(from.AddDate(0, 0, 1)) && from.Equal(from.Truncate(24*time.Hour)):
+        return partitionPrefix + from.Format(dayPartitionLayout), nil
+    case to.Equal(from.AddDate(0, 1, 0)) && from.Day() == 1 && from.Equal(from.Truncate(24*time.Hour)):
+        return partitionPrefix + from.Format(monthPartitionLayout), nil
+    }
+    return "", fmt.Errorf("partition %v - %v is not a whole UTC day or month", from, to)
+}
+
+func parsePartitionName(name string) (*entity.ClickPartition, bool) {
+    suffix, found := strings.CutPrefix(name, partitionPrefix)
+    if !found {
+        return nil, false
+    }
+
+    switch len(suffix) {
+    case len(dayPartitionLayout):
+        from, err := time.Parse(dayPartitionLayout, suffix)
+        if err != nil {
+            return nil, false
+        }
+        return &entity.ClickPartition{Name: name, From: from, To: from.AddDate(0, 0, 1)}, true
+    case len(monthPartitionLayout):
+        from, err := time.Parse(monthPartitionLayout, suffix)
+        if err != nil {
+            return nil, false
+        }
+        return &entity.ClickPartition{Name: name, From: from, To: from.AddDate(0, 1, 0)}, true
+    }
+    return nil, false
+}
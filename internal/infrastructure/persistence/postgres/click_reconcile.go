@@ -0,0 +1,103 @@
+package postgres
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/pkg/hll"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reconcileLockKey is the advisory lock held while Redis is reconciled.
+const reconcileLockKey = 7_048_001
+
+type clickHourSource struct {
+    db *pgxpool.Pool
+}
+
+func NewClickHourSource(db *pgxpool.Pool) repository.ClickHourSource {
+    return &clickHourSource{
+        db: db,
+    }
+}
+
+func (r *clickHourSource) HourCounts(ctx context.Context, from, to time.Time) ([]*entity.HourCount, error) {
+    rows, err := r.db.Query(ctx, `
+        SELECT tenant_id, banner_id, date_trunc('hour', timestamp, 'UTC') AS hour, SUM(count)
+        FROM clicks
+        WHERE timestamp >= $1 AND timestamp < $2
+        GROUP BY 1, 2, 3
+        ORDER BY 1, 2, 3
+    `, from, to)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var counts []*entity.HourCount
+    for rows.Next() {
+        count := &entity.HourCount{}
+        if err := rows.Scan(&count.TenantID, &count.BannerID, &count.Hour, &count.Clicks); err != nil {
+            return nil, err
+        }
+        counts = append(counts, count)
+    }
+    return counts, rows.Err()
+}
+
+func (r *clickHourSource) SecondCounts(ctx context.Context, tenantID, bannerID int64, hour time.Time) ([]*entity.Click, error) {
+    rows, err := r.db.Query(ctx, `
+        SELECT date_trunc('second', timestamp) AS ts, SUM(count)
+        FROM clicks
+        WHERE tenant_id = $1 AND banner_id = $2
+        AND timestamp >= $3 AND timestamp < $4
+        GROUP BY 1
+        ORDER BY 1
+    `, tenantID, bannerID, hour, hour.Add(time.Hour))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var clicks []*entity.Click
+    for rows.Next() {
+        click := &entity.Click{
+            TenantID: tenantID,
+            BannerID: bannerID,
+        }
+        if err := rows.Scan(&click.Timestamp, &click.Count); err != nil {
+            return nil, err
+        }
+        clicks = append(clicks, click)
+    }
+    return clicks, rows.Err()
+}
+
+func (r *clickHourSource) HourSketch(ctx context.Context, tenantID, bannerID int64, hour time.Time) (*hll.Sketch, error) {
+    var data []byte
+    err := r.db.QueryRow(ctx, `
+        SELECT sketch
+        FROM click_sketches
+        WHERE tenant_id = $1 AND banner_id = $2 AND bucket = $3
+    `, tenantID, bannerID, hour).Scan(&data)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    sketch := hll.New()
+    if err := sketch.UnmarshalBinary(data); err != nil {
+        return nil, err
+    }
+    return sketch, nil
+}
+
+func (r *clickHourSource) TryLock(ctx context.Context) (func(), bool, error) {
+    return tryAdvisoryLock(ctx, r.db, reconcileLockKey)
+}
@@ -3,14 +3,22 @@ package postgres
 import (
     "context"
     "log"
+    "sort"
     "time"
     
     "clicker/internal/domain/entity"
     "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
     "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgxpool"
 )
 
+const upsertBannerTotal = `
+    INSERT INTO banner_totals (tenant_id, banner_id, clicks)
+    VALUES ($1, $2, $3)
+    ON CONFLICT (banner_id) DO UPDATE SET clicks = banner_totals.clicks + EXCLUDED.clicks
+`
+
 type clickRepository struct {
     db *pgxpool.Pool
 }
@@ -22,6 +30,11 @@ func NewClickRepository(db *pgxpool.Pool) repository.ClickRepository {
 }
 
 func (r *clickRepository) IncrementClick(ctx context.Context, bannerID int64) (int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return 0, err
+    }
+
     tx, err := r.db.Begin(ctx)
     if err != nil {
         return 0, err
@@ -29,19 +42,23 @@ func (r *clickRepository) IncrementClick(ctx context.Context, bannerID int64) (i
     defer tx.Rollback(ctx)
 
     _, err = tx.Exec(ctx, `
-        INSERT INTO clicks (banner_id, timestamp, count)
-        VALUES ($1, $2, 1)
-    `, bannerID, time.Now())
+        INSERT INTO clicks (tenant_id, banner_id, timestamp, count)
+        VALUES ($1, $2, $3, 1)
+    `, tenantID, bannerID, time.Now())
     if err != nil {
         return 0, err
     }
 
+    if _, err = tx.Exec(ctx, upsertBannerTotal, tenantID, bannerID, 1); err != nil {
+        return 0, err
+    }
+
     var total int64
     err = tx.QueryRow(ctx, `
         SELECT COALESCE(SUM(count), 0)
         FROM clicks
-        WHERE banner_id = $1
-    `, bannerID).Scan(&total)
+        WHERE banner_id = $1 AND tenant_id = $2
+    `, bannerID, tenantID).Scan(&total)
     if err != nil {
         return 0, err
     }
@@ -53,14 +70,31 @@ func (r *clickRepository) IncrementClick(ctx context.Context, bannerID int64) (i
     return total, nil
 }
 
+// SaveBatch inserts the clicks and adds them to banner_totals. The batch
+// runs as one implicit transaction, so the totals never drift from the
+// clicks table.
 func (r *clickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
     batch := &pgx.Batch{}
+    totals := make(map[int64]int64)
+    owners := make(map[int64]int64)
     
     for _, click := range clicks {
         batch.Queue(
-            "INSERT INTO clicks (banner_id, timestamp, count) VALUES ($1, $2, $3)",
-            click.BannerID, click.Timestamp, click.Count,
+            "INSERT INTO clicks (tenant_id, banner_id, variant_id, timestamp, count) VALUES ($1, $2, NULLIF($3, 0), $4, $5)",
+            click.TenantID, click.BannerID, click.VariantID, click.Timestamp, click.Count,
         )
+        totals[click.BannerID] += int64(click.Count)
+        owners[click.BannerID] = click.TenantID
+    }
+
+    // Одинаковый порядок блокировок строк у всех реплик, иначе возможен дедлок.
+    bannerIDs := make([]int64, 0, len(totals))
+    for id := range totals {
+        bannerIDs = append(bannerIDs, id)
+    }
+    sort.Slice(bannerIDs, func(i, j int) bool { return bannerIDs[i] < bannerIDs[j] })
+    for _, id := range bannerIDs {
+        batch.Queue(upsertBannerTotal, owners[id], id, totals[id])
     }
     
     results := r.db.SendBatch(ctx, batch)
@@ -69,18 +103,30 @@ func (r *clickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click)
     return results.Close()
 }
 
+// GetStats returns hourly rows. Like the stats repository it reads through
+// the rollups where they are complete, so counter windows keep their clicks
+// after raw retention or archiving has removed them; an aggregate row counts
+// at the start of its bucket.
 func (r *clickRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
     log.Printf("Postgres: Getting stats for banner %d from %v to %v", bannerID, from, to)
-    
+
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    segments, err := planClickSources(ctx, r.db, from, to, clickTiers{hourly: true, daily: true})
+    if err != nil || len(segments) == 0 {
+        return nil, err
+    }
+    source, args := unionSegments(segments, "banner_id = $1 AND tenant_id = $2", []any{bannerID, tenantID})
+
     rows, err := r.db.Query(ctx, `
-        SELECT banner_id, date_trunc('hour', timestamp) as hour_timestamp, SUM(count) as total_count
-        FROM clicks
-        WHERE banner_id = $1 
-        AND timestamp >= $2 
-        AND timestamp < $3
+        SELECT banner_id, date_trunc('hour', ts, 'UTC') AS hour_timestamp, SUM(n) AS total_count
+        FROM `+source+` s
         GROUP BY banner_id, hour_timestamp
         ORDER BY hour_timestamp
-    `, bannerID, from, to)
+    `, args...)
     if err != nil {
         log.Printf("Postgres: Error querying: %v", err)
         return nil, err
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
+    "clicker/internal/domain/timeseries"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// clickTier is a table clicks can be read from, with its time, count and
+// variant columns. Aggregated tiers only cover whole buckets before their
+// watermark.
+type clickTier struct {
+    name          string
+    table         string
+    tsColumn      string
+    countColumn   string
+    variantColumn string
+    granularity   string
+    watermark     time.Time
+}
+
+func rawTier() clickTier {
+    return clickTier{
+        name:          entity.TierRaw,
+        table:         "clicks",
+        tsColumn:      "timestamp",
+        countColumn:   "count",
+        variantColumn: "COALESCE(variant_id, 0)",
+    }
+}
+
+func hourlyTier(watermark time.Time) clickTier {
+    return clickTier{
+        name:          entity.TierHourly,
+        table:         "clicks_hourly",
+        tsColumn:      "bucket",
+        countColumn:   "clicks",
+        variantColumn: "variant_id",
+        granularity:   timeseries.Hour,
+        watermark:     watermark,
+    }
+}
+
+func dailyTier(watermark time.Time) clickTier {
+    return clickTier{
+        name:          entity.TierDaily,
+        table:         "clicks_daily",
+        tsColumn:      "bucket",
+        countColumn:   "clicks",
+        variantColumn: "variant_id",
+        granularity:   timeseries.Day,
+        watermark:     watermark,
+    }
+}
+
+// clickSegment is a part of the requested range read from one tier.
+type clickSegment struct {
+    tier     clickTier
+    from, to time.Time
+}
+
+// clickTiers says which aggregates a query may use where finer data
+// still exists. Past a retention horizon the finest tier left is used
+// regardless.
+type clickTiers struct {
+    hourly, daily bool
+}
+
+// planSegments splits [from, to) between the tiers, coarsest first: each
+// tier takes the whole buckets it has, the edges go down to a finer tier,
+// and finest takes whatever is left. When finest is an aggregate, its
+// buckets are counted where they start.
+func planSegments(from, to time.Time, tiers []clickTier, finest clickTier) []clickSegment {
+    if !from.Before(to) {
+        return nil
+    }
+    if len(tiers) == 0 {
+        return []clickSegment{{tier: finest, from: from, to: to}}
+    }
+
+    tier, finer := tiers[0], tiers[1:]
+    start := timeseries.Truncate(from, tier.granularity, time.UTC)
+    if start.Before(from) {
+        start = timeseries.Next(start, tier.granularity, time.UTC)
+    }
+    end := timeseries.Truncate(to, tier.granularity, time.UTC)
+    if tier.watermark.Before(end) {
+        end = tier.watermark
+    }
+    if !start.Before(end) {
+        return planSegments(from, to, finer, finest)
+    }
+
+    segments := planSegments(from, start, finer, finest)
+    segments = append(segments, clickSegment{tier: tier, from: start, to: end})
+    return append(segments, planSegments(end, to, finer, finest)...)
+}
+
+// tierState is where each tier is complete up to (the rollup watermarks)
+// and where it starts after retention (zero when kept whole).
+type tierState struct {
+    hourlyWatermark, dailyWatermark time.Time
+    rawHorizon, hourlyHorizon       time.Time
+}
+
+func loadTierState(ctx context.Context, db *pgxpool.Pool) (*tierState, error) {
+    var hourlyWM, dailyWM, rawHorizon, hourlyHorizon *time.Time
+    err := db.QueryRow(ctx, `
+        SELECT
+            (SELECT watermark FROM rollup_watermarks WHERE name = $1),
+            (SELECT watermark FROM rollup_watermarks WHERE name = $2),
+            (SELECT horizon FROM retention_horizons WHERE tier = $3),
+            (SELECT horizon FROM retention_horizons WHERE tier = $4)
+    `, hourlyWatermark, dailyWatermark, rawRetentionTier, hourlyRetentionTier).Scan(
+        &hourlyWM, &dailyWM, &rawHorizon, &hourlyHorizon)
+    if err != nil {
+        return nil, err
+    }
+
+    state := &tierState{}
+    for _, v := range []struct {
+        src *time.Time
+        dst *time.Time
+    }{
+        {hourlyWM, &state.hourlyWatermark},
+        {dailyWM, &state.dailyWatermark},
+        {rawHorizon, &state.rawHorizon},
+        {hourlyHorizon, &state.hourlyHorizon},
+    } {
+        if v.src != nil {
+            *v.dst = *v.src
+        }
+    }
+    return state, nil
+}
+
+// planClickSources splits [from, to) at the retention horizons and plans
+// each part over the tiers that still exist there. The plan is recorded as
+// the sources of the answer.
+func planClickSources(ctx context.Context, db *pgxpool.Pool, from, to time.Time, allowed clickTiers) ([]clickSegment, error) {
+    state, err := loadTierState(ctx, db)
+    if err != nil {
+        return nil, err
+    }
+
+    cuts := []time.Time{from}
+    for _, horizon := range []time.Time{state.rawHorizon, state.hourlyHorizon} {
+        if horizon.After(from) && horizon.Before(to) {
+            cuts = append(cuts, horizon)
+        }
+    }
+    sort.Slice(cuts, func(i, j int) bool { return cuts[i].Before(cuts[j]) })
+    cuts = append(cuts, to)
+
+    var segments []clickSegment
+    for i := 0; i+1 < len(cuts); i++ {
+        tiers, finest := state.available(cuts[i], allowed)
+        segments = append(segments, planSegments(cuts[i], cuts[i+1], tiers, finest)...)
+    }
+    for _, s := range segments {
+        freshness.Record(ctx, s.tier.name, s.from, s.to)
+    }
+    return segments, nil
+}
+
+// available returns the allowed aggregate tiers, coarsest first, and the
+// finest tier left at t. Horizons only move up to the watermark above
+// them, so the tier above covers whatever retention has removed.
+func (s *tierState) available(t time.Time, allowed clickTiers) ([]clickTier, clickTier) {
+    daily := dailyTier(s.dailyWatermark)
+    hourly := hourlyTier(s.hourlyWatermark)
+    hasRaw := !t.Before(s.rawHorizon)
+    hasHourly := !t.Before(s.hourlyHorizon)
+
+    var tiers []clickTier
+    switch {
+    case hasRaw:
+        if allowed.daily {
+            tiers = append(tiers, daily)
+        }
+        if allowed.hourly && hasHourly {
+            tiers = append(tiers, hourly)
+        }
+        return tiers, rawTier()
+    case hasHourly:
+        if allowed.daily {
+            tiers = append(tiers, daily)
+        }
+        return tiers, hourly
+    }
+    return nil, daily
+}
+
+// unionSegments builds a subquery of (banner_id, variant_id, ts, n) rows
+// over the segments. Aggregates have a row per variant, so readers sum by
+// whatever they group on. filter refers to args by number, the segment bounds are added
+// after them.
+func unionSegments(segments []clickSegment, filter string, args []any) (string, []any) {
+    parts := make([]string, 0, len(segments))
+    for _, s := range segments {
+        args = append(args, s.from, s.to)
+        parts = append(parts, fmt.Sprintf(
+            "SELECT banner_id, %[7]s AS variant_id, %[2]s AS ts, %[3]s AS n FROM %[1]s WHERE %[4]s AND %[2]s >= $%[5]d AND %[2]s < $%[6]d",
+            s.tier.table, s.tier.tsColumn, s.tier.countColumn, filter, len(args)-1, len(args), s.tier.variantColumn,
+        ))
+    }
+    return "(" + strings.Join(parts, "\nUNION ALL\n") + ")", args
+}
+
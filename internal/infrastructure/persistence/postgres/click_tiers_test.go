@@ -0,0 +1,153 @@
+package postgres
+
+import (
+    "fmt"
+    "reflect"
+    "testing"
+    "time"
+
+    "clicker/internal/domain/entity"
+)
+
+// describe renders segments as "tier from-to" in UTC, which is easier to
+// compare and read in a failure than the structs.
+func describe(segments []clickSegment) []string {
+    var out []string
+    for _, s := range segments {
+        out = append(out, fmt.Sprintf("%s %s-%s", s.tier.name,
+            s.from.UTC().Format("01-02 15:04"), s.to.UTC().Format("01-02 15:04")))
+    }
+    return out
+}
+
+func TestPlanSegments(t *testing.T) {
+    at := func(day, hour, minute int) time.Time {
+        return time.Date(2024, 3, day, hour, minute, 0, 0, time.UTC)
+    }
+    // Агрегаты, досчитанные далеко вперёд, не ограничивают план.
+    complete := at(31, 0, 0)
+
+    tests := []struct {
+        name     string
+        from, to time.Time
+        tiers    []clickTier
+        finest   clickTier
+        want     []string
+    }{
+        {
+            name: "empty range",
+            from: at(10, 12, 0), to: at(10, 12, 0),
+            tiers:  []clickTier{dailyTier(complete), hourlyTier(complete)},
+            finest: rawTier(),
+            want:   nil,
+        },
+        {
+            name: "raw only",
+            from: at(9, 22, 30), to: at(12, 1, 15),
+            finest: rawTier(),
+            want:   []string{"raw 03-09 22:30-03-12 01:15"},
+        },
+        {
+            name: "days in the middle, hours and seconds at the edges",
+            from: at(9, 22, 30), to: at(12, 1, 15),
+            tiers:  []clickTier{dailyTier(complete), hourlyTier(complete)},
+            finest: rawTier(),
+            want: []string{
+                "raw 03-09 22:30-03-09 23:00",
+                "hourly 03-09 23:00-03-10 00:00",
+                "daily 03-10 00:00-03-12 00:00",
+                "hourly 03-12 00:00-03-12 01:00",
+                "raw 03-12 01:00-03-12 01:15",
+            },
+        },
+        {
+            name: "shorter than a day",
+            from: at(10, 10, 20), to: at(10, 13, 0),
+            tiers:  []clickTier{dailyTier(complete), hourlyTier(complete)},
+            finest: rawTier(),
+            want: []string{
+                "raw 03-10 10:20-03-10 11:00",
+                "hourly 03-10 11:00-03-10 13:00",
+            },
+        },
+        {
+            name: "hours rolled up only to the watermark",
+            from: at(10, 10, 0), to: at(10, 15, 0),
+            tiers:  []clickTier{hourlyTier(at(10, 12, 0))},
+            finest: rawTier(),
+            want: []string{
+                "hourly 03-10 10:00-03-10 12:00",
+                "raw 03-10 12:00-03-10 15:00",
+            },
+        },
+        {
+            name: "watermark before the range",
+            from: at(10, 10, 0), to: at(10, 15, 0),
+            tiers:  []clickTier{hourlyTier(at(10, 9, 0))},
+            finest: rawTier(),
+            want:   []string{"raw 03-10 10:00-03-10 15:00"},
+        },
+        {
+            name: "aggregate as the finest tier",
+            from: at(9, 22, 30), to: at(11, 1, 15),
+            tiers:  []clickTier{dailyTier(complete)},
+            finest: hourlyTier(complete),
+            want: []string{
+                "hourly 03-09 22:30-03-10 00:00",
+                "daily 03-10 00:00-03-11 00:00",
+                "hourly 03-11 00:00-03-11 01:15",
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := describe(planSegments(tt.from, tt.to, tt.tiers, tt.finest))
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("planSegments =\n%q\nwant\n%q", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestTierStateAvailable(t *testing.T) {
+    rawHorizon := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+    hourlyHorizon := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+    state := &tierState{rawHorizon: rawHorizon, hourlyHorizon: hourlyHorizon}
+
+    tiers := func(list []clickTier) []string {
+        var names []string
+        for _, tier := range list {
+            names = append(names, tier.name)
+        }
+        return names
+    }
+
+    tests := []struct {
+        name       string
+        at         time.Time
+        allowed    clickTiers
+        wantTiers  []string
+        wantFinest string
+    }{
+        {"raw kept", rawHorizon, clickTiers{hourly: true, daily: true},
+            []string{entity.TierDaily, entity.TierHourly}, entity.TierRaw},
+        {"raw kept, no aggregates allowed", rawHorizon, clickTiers{}, nil, entity.TierRaw},
+        // За горизонтом агрегаты используются, даже если их не просили.
+        {"raw expired", rawHorizon.Add(-time.Hour), clickTiers{},
+            nil, entity.TierHourly},
+        {"raw expired, days allowed", rawHorizon.Add(-time.Hour), clickTiers{daily: true},
+            []string{entity.TierDaily}, entity.TierHourly},
+        {"hours expired", hourlyHorizon.Add(-time.Hour), clickTiers{hourly: true, daily: true},
+            nil, entity.TierDaily},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            gotTiers, finest := state.available(tt.at, tt.allowed)
+            if got := tiers(gotTiers); !reflect.DeepEqual(got, tt.wantTiers) || finest.name != tt.wantFinest {
+                t.Errorf("available = %q, %s; want %q, %s", got, finest.name, tt.wantTiers, tt.wantFinest)
+            }
+        })
+    }
+}
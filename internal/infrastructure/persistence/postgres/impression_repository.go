@@ -0,0 +1,33 @@
+package postgres
+
+import (
+    "context"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type impressionRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewImpressionRepository(db *pgxpool.Pool) repository.ImpressionRepository {
+    return &impressionRepository{
+        db: db,
+    }
+}
+
+func (r *impressionRepository) SaveBatch(ctx context.Context, impressions []*entity.Impression) error {
+    batch := &pgx.Batch{}
+
+    for _, impression := range impressions {
+        batch.Queue(
+            "INSERT INTO impressions (tenant_id, banner_id, variant_id, timestamp, count) VALUES ($1, $2, NULLIF($3, 0), $4, $5)",
+            impression.TenantID, impression.BannerID, impression.VariantID, impression.Timestamp, impression.Count,
+        )
+    }
+
+    return r.db.SendBatch(ctx, batch).Close()
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// retentionLockKey is the advisory lock held while retention is enforced.
+const retentionLockKey = 7_044_001
+
+// Tier names in retention_horizons.
+const (
+    rawRetentionTier    = "raw"
+    hourlyRetentionTier = "hourly"
+)
+
+type retentionRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewRetentionRepository(db *pgxpool.Pool) repository.RetentionRepository {
+    return &retentionRepository{
+        db: db,
+    }
+}
+
+// ExpireRaw moves the horizon first, so stats stop reading raw clicks there
+// before they are gone. Whole partitions are dropped or detached, which is
+// much cheaper than deleting their rows, and only the partition holding the
+// horizon and the default partition are deleted from.
+func (r *retentionRepository) ExpireRaw(ctx context.Context, before time.Time, detach bool) (*entity.TierExpiry, error) {
+    horizon, err := r.moveHorizon(ctx, rawRetentionTier, hourlyWatermark, before)
+    if err != nil {
+        return nil, err
+    }
+    expiry := &entity.TierExpiry{Horizon: horizon}
+
+    partitions := &clickPartitionRepository{db: r.db}
+    existing, err := partitions.List(ctx)
+    if err != nil {
+        return nil, err
+    }
+    for _, p := range existing {
+        if p.To.After(horizon) {
+            continue
+        }
+        if detach {
+            err = partitions.Detach(ctx, p.Name)
+        } else {
+            err = partitions.Drop(ctx, p.Name)
+        }
+        if err != nil {
+            return nil, err
+        }
+        expiry.PartitionsDropped++
+    }
+
+    tag, err := r.db.Exec(ctx, `DELETE FROM clicks WHERE timestamp < $1`, horizon)
+    if err != nil {
+        return nil, err
+    }
+    expiry.Deleted = tag.RowsAffected()
+
+    return expiry, nil
+}
+
+func (r *retentionRepository) ExpireHourly(ctx context.Context, before time.Time) (*entity.TierExpiry, error) {
+    horizon, err := r.moveHorizon(ctx, hourlyRetentionTier, dailyWatermark, before)
+    if err != nil {
+        return nil, err
+    }
+
+    tag, err := r.db.Exec(ctx, `DELETE FROM clicks_hourly WHERE bucket < $1`, horizon)
+    if err != nil {
+        return nil, err
+    }
+
+    return &entity.TierExpiry{Horizon: horizon, Deleted: tag.RowsAffected()}, nil
+}
+
+// moveHorizon sets the horizon of tier to before, capped by the watermark
+// of the rollup built from it, and returns where it ends up. The watermark
+// row is locked meanwhile, so it cannot move under us.
+func (r *retentionRepository) moveHorizon(ctx context.Context, tier, watermarkName string, before time.Time) (time.Time, error) {
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return time.Time{}, err
+    }
+    defer tx.Rollback(ctx)
+
+    var watermark time.Time
+    err = tx.QueryRow(ctx, `
+        SELECT watermark FROM rollup_watermarks WHERE name = $1 FOR SHARE
+    `, watermarkName).Scan(&watermark)
+    if err != nil {
+        return time.Time{}, err
+    }
+    if watermark.Before(before) {
+        before = watermark
+    }
+
+    // Удалённое не вернуть, поэтому горизонт назад не сдвигается.
+    var horizon time.Time
+    err = tx.QueryRow(ctx, `
+        INSERT INTO retention_horizons (tier, horizon)
+        VALUES ($1, $2)
+        ON CONFLICT (tier) DO UPDATE
+        SET horizon = GREATEST(retention_horizons.horizon, EXCLUDED.horizon), updated_at = now()
+        RETURNING horizon
+    `, tier, before).Scan(&horizon)
+    if err != nil {
+        return time.Time{}, err
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return time.Time{}, err
+    }
+    return horizon, nil
+}
+
+func (r *retentionRepository) SaveRun(ctx context.Context, run *entity.RetentionRun) error {
+    var (
+        rawHorizon, hourlyHorizon *time.Time
+        rawDeleted, hourlyDeleted int64
+        partitionsDropped         int
+    )
+    if run.Raw != nil {
+        rawHorizon, rawDeleted, partitionsDropped = &run.Raw.Horizon, run.Raw.Deleted, run.Raw.PartitionsDropped
+    }
+    if run.Hourly != nil {
+        hourlyHorizon, hourlyDeleted = &run.Hourly.Horizon, run.Hourly.Deleted
+    }
+
+    return r.db.QueryRow(ctx, `
+        INSERT INTO retention_runs (started_at, finished_at, raw_horizon, raw_deleted, partitions_dropped,
+            hourly_horizon, hourly_deleted, error)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id
+    `, run.StartedAt, run.FinishedAt, rawHorizon, rawDeleted, partitionsDropped,
+        hourlyHorizon, hourlyDeleted, run.Error).Scan(&run.ID)
+}
+
+func (r *retentionRepository) ListRuns(ctx context.Context, limit int) ([]*entity.RetentionRun, error) {
+    rows, err := r.db.Query(ctx, `
+        SELECT id, started_at, finished_at, raw_horizon, raw_deleted, partitions_dropped,
+            hourly_horizon, hourly_deleted, error
+        FROM retention_runs
+        ORDER BY started_at DESC, id DESC
+        LIMIT $1
+    `, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var runs []*entity.RetentionRun
+    for rows.Next() {
+        run, err := scanRetentionRun(rows)
+        if err != nil {
+            return nil, err
+        }
+        runs = append(runs, run)
+    }
+
+    return runs, rows.Err()
+}
+
+func (r *retentionRepository) TryLock(ctx context.Context) (func(), bool, error) {
+    return tryAdvisoryLock(ctx, r.db, retentionLockKey)
+}
+
+func scanRetentionRun(row pgx.Row) (*entity.RetentionRun, error) {
+    var (
+        run                       entity.RetentionRun
+        rawHorizon, hourlyHorizon *time.Time
+        rawDeleted, hourlyDeleted int64
+        partitionsDropped         int
+    )
+    err := row.Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &rawHorizon, &rawDeleted, &partitionsDropped,
+        &hourlyHorizon, &hourlyDeleted, &run.Error)
+    if err != nil {
+        return nil, err
+    }
+
+    if rawHorizon != nil {
+        run.Raw = &entity.TierExpiry{Horizon: *rawHorizon, Deleted: rawDeleted, PartitionsDropped: partitionsDropped}
+    }
+    if hourlyHorizon != nil {
+        run.Hourly = &entity.TierExpiry{Horizon: *hourlyHorizon, Deleted: hourlyDeleted}
+    }
+    return &run, nil
+}
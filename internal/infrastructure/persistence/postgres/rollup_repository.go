@@ -0,0 +1,170 @@
+package postgres
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/timeseries"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Watermark names in rollup_watermarks.
+const (
+    hourlyWatermark = "hourly"
+    dailyWatermark  = "daily"
+)
+
+// rollup is one aggregation step: the watermark it advances, the bucket it
+// aligns to and the query filling [$1, $2). Buckets are per variant, clicks
+// without one go to variant 0. The query replaces buckets
+// instead of adding to them, so rolling up a range twice is harmless.
+type rollup struct {
+    watermark   string
+    granularity string
+    query       string
+}
+
+var hourlyRollup = rollup{
+    watermark:   hourlyWatermark,
+    granularity: timeseries.Hour,
+    query: `
+        INSERT INTO clicks_hourly (tenant_id, banner_id, variant_id, bucket, clicks)
+        SELECT tenant_id, banner_id, COALESCE(variant_id, 0) AS variant, date_trunc('hour', timestamp, 'UTC') AS bucket, SUM(count)
+        FROM clicks
+        WHERE timestamp >= $1
+        AND timestamp < $2
+        GROUP BY tenant_id, banner_id, variant, bucket
+        ON CONFLICT (banner_id, variant_id, bucket) DO UPDATE SET clicks = EXCLUDED.clicks
+    `,
+}
+
+var dailyRollup = rollup{
+    watermark:   dailyWatermark,
+    granularity: timeseries.Day,
+    query: `
+        INSERT INTO clicks_daily (tenant_id, banner_id, variant_id, bucket, clicks)
+        SELECT tenant_id, banner_id, variant_id, date_trunc('day', bucket, 'UTC') AS day, SUM(clicks)
+        FROM clicks_hourly
+        WHERE bucket >= $1
+        AND bucket < $2
+        GROUP BY tenant_id, banner_id, variant_id, day
+        ON CONFLICT (banner_id, variant_id, bucket) DO UPDATE SET clicks = EXCLUDED.clicks
+    `,
+}
+
+type rollupRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewRollupRepository(db *pgxpool.Pool) repository.RollupRepository {
+    return &rollupRepository{
+        db: db,
+    }
+}
+
+func (r *rollupRepository) RollUpHourly(ctx context.Context, until time.Time, span time.Duration) (time.Time, error) {
+    return r.rollUp(ctx, hourlyRollup, until, span)
+}
+
+func (r *rollupRepository) RollUpDaily(ctx context.Context, until time.Time, span time.Duration) (time.Time, error) {
+    return r.rollUp(ctx, dailyRollup, until, span)
+}
+
+func (r *rollupRepository) Revisit(ctx context.Context, span time.Duration) error {
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    // Обе строки под замком в одном порядке, rollUp держит лишь одну, так
+    // что взаимной блокировки с ним нет.
+    rows, err := tx.Query(ctx, `
+        SELECT name, watermark FROM rollup_watermarks
+        WHERE name IN ($1, $2)
+        ORDER BY name
+        FOR UPDATE
+    `, dailyWatermark, hourlyWatermark)
+    if err != nil {
+        return err
+    }
+    watermarks := make(map[string]time.Time)
+    for rows.Next() {
+        var (
+            name      string
+            watermark time.Time
+        )
+        if err := rows.Scan(&name, &watermark); err != nil {
+            rows.Close()
+            return err
+        }
+        watermarks[name] = watermark
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return err
+    }
+
+    hourlyTo := watermarks[hourlyWatermark]
+    hourlyFrom := timeseries.Truncate(hourlyTo.Add(-span), hourlyRollup.granularity, time.UTC)
+    if !hourlyFrom.Before(hourlyTo) {
+        return nil
+    }
+    if _, err := tx.Exec(ctx, hourlyRollup.query, hourlyFrom, hourlyTo); err != nil {
+        return err
+    }
+
+    dailyTo := watermarks[dailyWatermark]
+    dailyFrom := timeseries.Truncate(hourlyFrom, dailyRollup.granularity, time.UTC)
+    if dailyFrom.Before(dailyTo) {
+        if _, err := tx.Exec(ctx, dailyRollup.query, dailyFrom, dailyTo); err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit(ctx)
+}
+
+// rollUp aggregates and moves the watermark in one transaction. The
+// watermark row stays locked meanwhile, so replicas take turns.
+func (r *rollupRepository) rollUp(ctx context.Context, step rollup, until time.Time, span time.Duration) (time.Time, error) {
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return time.Time{}, err
+    }
+    defer tx.Rollback(ctx)
+
+    var watermark time.Time
+    err = tx.QueryRow(ctx, `
+        SELECT watermark FROM rollup_watermarks WHERE name = $1 FOR UPDATE
+    `, step.watermark).Scan(&watermark)
+    if err != nil {
+        return time.Time{}, err
+    }
+
+    target := watermark.Add(span)
+    if until.Before(target) {
+        target = until
+    }
+    target = timeseries.Truncate(target, step.granularity, time.UTC)
+    if !target.After(watermark) {
+        return watermark, nil
+    }
+
+    if _, err := tx.Exec(ctx, step.query, watermark, target); err != nil {
+        return time.Time{}, err
+    }
+
+    _, err = tx.Exec(ctx, `
+        UPDATE rollup_watermarks SET watermark = $2, updated_at = now() WHERE name = $1
+    `, step.watermark, target)
+    if err != nil {
+        return time.Time{}, err
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return time.Time{}, err
+    }
+    return target, nil
+}
@@ -7,6 +7,8 @@ import (
     "github.com/jackc/pgx/v5/pgxpool"
     "clicker/internal/domain/entity"
     "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
 )
 
 type statsRepository struct {
@@ -19,15 +21,38 @@ func NewStatsRepository(db *pgxpool.Pool) repository.StatsRepository {
     }
 }
 
+func NewVariantStatsRepository(db *pgxpool.Pool) repository.VariantStatsRepository {
+    return &statsRepository{
+        db: db,
+    }
+}
+
+func NewTopBannersRepository(db *pgxpool.Pool) repository.TopBannersRepository {
+    return &statsRepository{
+        db: db,
+    }
+}
+
+// GetStats reads whole days and hours from the rollups, so a row may be an
+// aggregate of many clicks with Timestamp set to the bucket start.
 func (r *statsRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    segments, err := planClickSources(ctx, r.db, from, to, clickTiers{hourly: true, daily: true})
+    if err != nil || len(segments) == 0 {
+        return nil, err
+    }
+    source, args := unionSegments(segments, "banner_id = $1 AND tenant_id = $2", []any{bannerID, tenantID})
+
     rows, err := r.db.Query(ctx, `
-        SELECT banner_id, timestamp, count
-        FROM clicks
-        WHERE banner_id = $1 
-        AND timestamp >= $2 
-        AND timestamp < $3
-        ORDER BY timestamp
-    `, bannerID, from, to)
+        SELECT banner_id, ts, SUM(n)
+        FROM `+source+` s
+        GROUP BY banner_id, ts
+        ORDER BY ts
+    `, args...)
     if err != nil {
         return nil, err
     }
@@ -44,3 +69,184 @@ func (r *statsRepository) GetStats(ctx context.Context, bannerID int64, from, to
 
     return clicks, rows.Err()
 }
+
+// GetSeries buckets in the database. date_trunc with a time zone cuts days,
+// weeks and months at local midnight and handles DST the same way Go does.
+// Rollups are used only where their buckets fit whole into the requested
+// ones: hours when loc is a whole number of hours off UTC, days only in UTC.
+func (r *statsRepository) GetSeries(ctx context.Context, bannerID int64, from, to time.Time,
+    granularity string, loc *time.Location) ([]*entity.Click, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    allowed := clickTiers{
+        hourly: granularity != timeseries.Minute && wholeHourOffset(from, loc) && wholeHourOffset(to, loc),
+        daily:  granularity != timeseries.Minute && granularity != timeseries.Hour && loc.String() == "UTC",
+    }
+    segments, err := planClickSources(ctx, r.db, from, to, allowed)
+    if err != nil || len(segments) == 0 {
+        return nil, err
+    }
+    source, args := unionSegments(segments, "banner_id = $1 AND tenant_id = $2",
+        []any{bannerID, tenantID, granularity, loc.String()})
+
+    rows, err := r.db.Query(ctx, `
+        SELECT date_trunc($3, ts, $4) AS bucket, SUM(n)
+        FROM `+source+` s
+        GROUP BY bucket
+        ORDER BY bucket
+    `, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var clicks []*entity.Click
+    for rows.Next() {
+        click := &entity.Click{BannerID: bannerID}
+        if err := rows.Scan(&click.Timestamp, &click.Count); err != nil {
+            return nil, err
+        }
+        clicks = append(clicks, click)
+    }
+
+    return clicks, rows.Err()
+}
+
+func (r *statsRepository) GetTotals(ctx context.Context, bannerIDs []int64, from, to time.Time) (map[int64]int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    totals := make(map[int64]int64, len(bannerIDs))
+    segments, err := planClickSources(ctx, r.db, from, to, clickTiers{hourly: true, daily: true})
+    if err != nil || len(segments) == 0 {
+        return totals, err
+    }
+    source, args := unionSegments(segments, "banner_id = ANY($1) AND tenant_id = $2", []any{bannerIDs, tenantID})
+
+    rows, err := r.db.Query(ctx, `
+        SELECT banner_id, SUM(n)
+        FROM `+source+` s
+        GROUP BY banner_id
+    `, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var bannerID, total int64
+        if err := rows.Scan(&bannerID, &total); err != nil {
+            return nil, err
+        }
+        totals[bannerID] = total
+    }
+
+    return totals, rows.Err()
+}
+
+func (r *statsRepository) TopBanners(ctx context.Context, from, to time.Time, limit int) ([]*entity.BannerTotal, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    segments, err := planClickSources(ctx, r.db, from, to, clickTiers{hourly: true, daily: true})
+    if err != nil || len(segments) == 0 {
+        return nil, err
+    }
+    source, args := unionSegments(segments, "tenant_id = $1", []any{tenantID, limit})
+
+    rows, err := r.db.Query(ctx, `
+        SELECT banner_id, SUM(n) AS clicks
+        FROM `+source+` s
+        GROUP BY banner_id
+        ORDER BY clicks DESC, banner_id
+        LIMIT $2
+    `, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var top []*entity.BannerTotal
+    for rows.Next() {
+        total := &entity.BannerTotal{}
+        if err := rows.Scan(&total.BannerID, &total.Clicks); err != nil {
+            return nil, err
+        }
+        top = append(top, total)
+    }
+
+    return top, rows.Err()
+}
+
+// GetVariantStats reads clicks from the rollups like GetStats, so variant
+// history outlives raw click retention. Impressions are not rolled up and
+// come from the raw table.
+func (r *statsRepository) GetVariantStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.VariantStats, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    segments, err := planClickSources(ctx, r.db, from, to, clickTiers{hourly: true, daily: true})
+    if err != nil {
+        return nil, err
+    }
+    clicks := `(SELECT 0 AS variant_id, 0 AS n WHERE false)`
+    args := []any{bannerID, from, to, tenantID}
+    if len(segments) > 0 {
+        clicks, args = unionSegments(segments, "banner_id = $1 AND tenant_id = $4", args)
+    }
+
+    rows, err := r.db.Query(ctx, `
+        SELECT v.id, v.name, v.weight,
+            COALESCE(i.impressions, 0), COALESCE(c.clicks, 0)
+        FROM banner_variants v
+        LEFT JOIN (
+            SELECT variant_id, SUM(count) AS impressions
+            FROM impressions
+            WHERE banner_id = $1
+            AND tenant_id = $4
+            AND timestamp >= $2
+            AND timestamp < $3
+            GROUP BY variant_id
+        ) i ON i.variant_id = v.id
+        LEFT JOIN (
+            SELECT variant_id, SUM(n) AS clicks
+            FROM `+clicks+` s
+            GROUP BY variant_id
+        ) c ON c.variant_id = v.id
+        JOIN banners b ON b.id = v.banner_id
+        WHERE v.banner_id = $1
+        AND b.tenant_id = $4
+        ORDER BY v.id
+    `, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var stats []*entity.VariantStats
+    for rows.Next() {
+        s := &entity.VariantStats{}
+        if err := rows.Scan(&s.VariantID, &s.Name, &s.Weight, &s.Impressions, &s.Clicks); err != nil {
+            return nil, err
+        }
+        stats = append(stats, s)
+    }
+
+    return stats, rows.Err()
+}
+
+// wholeHourOffset reports whether loc is a whole number of hours off UTC
+// at t, so its hours and days are made of whole UTC hours.
+func wholeHourOffset(t time.Time, loc *time.Location) bool {
+    _, offset := t.In(loc).Zone()
+    return offset%3600 == 0
+}
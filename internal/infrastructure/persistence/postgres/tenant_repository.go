@@ -0,0 +1,115 @@
+package postgres
+
+import (
+    "context"
+    "errors"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tenantRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewTenantRepository(db *pgxpool.Pool) repository.TenantRepository {
+    return &tenantRepository{
+        db: db,
+    }
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant *entity.Tenant, apiKeyHash string) error {
+    return r.db.QueryRow(ctx, `
+        INSERT INTO tenants (name, api_key_hash, max_banners, max_daily_clicks)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `, tenant.Name, apiKeyHash, tenant.MaxBanners, tenant.MaxDailyClicks).Scan(&tenant.ID, &tenant.CreatedAt)
+}
+
+func (r *tenantRepository) GetByID(ctx context.Context, tenantID int64) (*entity.Tenant, error) {
+    return r.getOne(ctx, `
+        SELECT id, name, max_banners, max_daily_clicks, created_at
+        FROM tenants
+        WHERE id = $1
+    `, tenantID)
+}
+
+func (r *tenantRepository) GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*entity.Tenant, error) {
+    return r.getOne(ctx, `
+        SELECT id, name, max_banners, max_daily_clicks, created_at
+        FROM tenants
+        WHERE api_key_hash = $1
+    `, apiKeyHash)
+}
+
+func (r *tenantRepository) getOne(ctx context.Context, query string, args ...any) (*entity.Tenant, error) {
+    tenant := &entity.Tenant{}
+    err := r.db.QueryRow(ctx, query, args...).
+        Scan(&tenant.ID, &tenant.Name, &tenant.MaxBanners, &tenant.MaxDailyClicks, &tenant.CreatedAt)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, repository.ErrTenantNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return tenant, nil
+}
+
+func (r *tenantRepository) List(ctx context.Context) ([]*entity.Tenant, error) {
+    rows, err := r.db.Query(ctx, `
+        SELECT id, name, max_banners, max_daily_clicks, created_at
+        FROM tenants
+        ORDER BY id
+    `)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var tenants []*entity.Tenant
+    for rows.Next() {
+        tenant := &entity.Tenant{}
+        if err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.MaxBanners, &tenant.MaxDailyClicks, &tenant.CreatedAt); err != nil {
+            return nil, err
+        }
+        tenants = append(tenants, tenant)
+    }
+
+    return tenants, rows.Err()
+}
+
+func (r *tenantRepository) UpdateQuota(ctx context.Context, tenant *entity.Tenant) error {
+    err := r.db.QueryRow(ctx, `
+        UPDATE tenants
+        SET max_banners = $2, max_daily_clicks = $3
+        WHERE id = $1
+        RETURNING name, created_at
+    `, tenant.ID, tenant.MaxBanners, tenant.MaxDailyClicks).Scan(&tenant.Name, &tenant.CreatedAt)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return repository.ErrTenantNotFound
+    }
+    return err
+}
+
+func (r *tenantRepository) SetAPIKeyHash(ctx context.Context, tenantID int64, apiKeyHash string) error {
+    tag, err := r.db.Exec(ctx, `
+        UPDATE tenants SET api_key_hash = $2 WHERE id = $1
+    `, tenantID, apiKeyHash)
+    if err != nil {
+        return err
+    }
+    if tag.RowsAffected() == 0 {
+        return repository.ErrTenantNotFound
+    }
+    return nil
+}
+
+func (r *tenantRepository) CountBanners(ctx context.Context, tenantID int64) (int, error) {
+    var count int
+    err := r.db.QueryRow(ctx, `
+        SELECT COUNT(*) FROM banners WHERE tenant_id = $1 AND deleted_at IS NULL
+    `, tenantID).Scan(&count)
+    return count, err
+}
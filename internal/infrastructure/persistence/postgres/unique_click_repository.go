@@ -0,0 +1,194 @@
+package postgres
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/pkg/hll"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type uniqueClickRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewUniqueClickRepository(db *pgxpool.Pool) repository.UniqueClickRepository {
+    return &uniqueClickRepository{
+        db: db,
+    }
+}
+
+type sketchBucket struct {
+    tenantID int64
+    bannerID int64
+    hour     time.Time
+}
+
+// SaveBatch merges the batch into the stored hourly sketches in three
+// statements whatever the number of buckets: missing rows are created empty,
+// all rows are locked and read at once, and the ones the batch actually
+// changed are written back together. Locking makes replicas flushing the
+// same hour merge one after another instead of overwriting each other, and
+// the fixed lock order avoids deadlocks between them. Sketches whose
+// registers did not grow, the common case for returning clickers, are not
+// rewritten.
+func (r *uniqueClickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
+    sketches := make(map[sketchBucket]*hll.Sketch)
+    for _, click := range clicks {
+        if click.ClickerID == "" {
+            continue
+        }
+        key := sketchBucket{tenantID: click.TenantID, bannerID: click.BannerID, hour: click.Timestamp.Truncate(time.Hour).UTC()}
+        sketch, ok := sketches[key]
+        if !ok {
+            sketch = hll.New()
+            sketches[key] = sketch
+        }
+        sketch.AddString(click.ClickerID)
+    }
+    if len(sketches) == 0 {
+        return nil
+    }
+
+    tenantIDs := make([]int64, 0, len(sketches))
+    bannerIDs := make([]int64, 0, len(sketches))
+    hours := make([]time.Time, 0, len(sketches))
+    for key := range sketches {
+        tenantIDs = append(tenantIDs, key.tenantID)
+        bannerIDs = append(bannerIDs, key.bannerID)
+        hours = append(hours, key.hour)
+    }
+
+    empty, err := hll.New().MarshalBinary()
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    _, err = tx.Exec(ctx, `
+        INSERT INTO click_sketches (tenant_id, banner_id, bucket, sketch)
+        SELECT b.tenant_id, b.banner_id, b.bucket, $4
+        FROM unnest($1::int[], $2::int[], $3::timestamptz[]) AS b(tenant_id, banner_id, bucket)
+        ORDER BY b.banner_id, b.bucket
+        ON CONFLICT (banner_id, bucket) DO NOTHING
+    `, tenantIDs, bannerIDs, hours, empty)
+    if err != nil {
+        return err
+    }
+
+    rows, err := tx.Query(ctx, `
+        SELECT c.tenant_id, c.banner_id, c.bucket, c.sketch
+        FROM click_sketches c
+        JOIN unnest($1::int[], $2::timestamptz[]) AS b(banner_id, bucket)
+            ON c.banner_id = b.banner_id AND c.bucket = b.bucket
+        ORDER BY c.banner_id, c.bucket
+        FOR UPDATE OF c
+    `, bannerIDs, hours)
+    if err != nil {
+        return err
+    }
+
+    var (
+        changedBanners []int64
+        changedHours   []time.Time
+        changedData    [][]byte
+    )
+    for rows.Next() {
+        var (
+            key    sketchBucket
+            stored []byte
+        )
+        if err := rows.Scan(&key.tenantID, &key.bannerID, &key.hour, &stored); err != nil {
+            rows.Close()
+            return err
+        }
+        key.hour = key.hour.UTC()
+
+        batch, ok := sketches[key]
+        if !ok {
+            continue
+        }
+        merged := hll.New()
+        if err := merged.UnmarshalBinary(stored); err != nil {
+            rows.Close()
+            return err
+        }
+        if !merged.Merge(batch) {
+            continue
+        }
+
+        data, err := merged.MarshalBinary()
+        if err != nil {
+            rows.Close()
+            return err
+        }
+        changedBanners = append(changedBanners, key.bannerID)
+        changedHours = append(changedHours, key.hour)
+        changedData = append(changedData, data)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return err
+    }
+
+    if len(changedData) > 0 {
+        _, err = tx.Exec(ctx, `
+            UPDATE click_sketches c SET sketch = u.sketch
+            FROM unnest($1::int[], $2::timestamptz[], $3::bytea[]) AS u(banner_id, bucket, sketch)
+            WHERE c.banner_id = u.banner_id AND c.bucket = u.bucket
+        `, changedBanners, changedHours, changedData)
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *uniqueClickRepository) GetSketch(ctx context.Context, bannerID int64, from, to time.Time) (*hll.Sketch, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    rows, err := r.db.Query(ctx, `
+        SELECT sketch
+        FROM click_sketches
+        WHERE banner_id = $1
+        AND tenant_id = $4
+        AND bucket >= $2
+        AND bucket < $3
+    `, bannerID, from.Truncate(time.Hour), to, tenantID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    sketch := hll.New()
+    for rows.Next() {
+        var data []byte
+        if err := rows.Scan(&data); err != nil {
+            return nil, err
+        }
+        bucket := hll.New()
+        if err := bucket.UnmarshalBinary(data); err != nil {
+            return nil, err
+        }
+        sketch.Merge(bucket)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    freshness.Record(ctx, entity.TierHourly, from, to)
+
+    return sketch, nil
+}
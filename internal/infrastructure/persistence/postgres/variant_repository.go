@@ -0,0 +1,168 @@
+package postgres
+
+import (
+    "context"
+    "errors"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type variantRepository struct {
+    db *pgxpool.Pool
+}
+
+func NewVariantRepository(db *pgxpool.Pool) repository.VariantRepository {
+    return &variantRepository{
+        db: db,
+    }
+}
+
+func (r *variantRepository) Create(ctx context.Context, variant *entity.Variant) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    err = tx.QueryRow(ctx, `
+        INSERT INTO banner_variants (banner_id, name, creative_url, weight)
+        SELECT id, $2, $3, $4
+        FROM banners
+        WHERE id = $1 AND tenant_id = $5 AND deleted_at IS NULL
+        RETURNING id
+    `, variant.BannerID, variant.Name, variant.CreativeURL, variant.Weight, tenantID).Scan(&variant.ID)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return repository.ErrBannerNotFound
+    }
+    if err != nil {
+        return err
+    }
+
+    err = insertRevision(ctx, tx, &entity.BannerRevision{
+        TenantID:  tenantID,
+        BannerID:  variant.BannerID,
+        VariantID: variant.ID,
+        Action:    entity.RevisionActionVariantCreate,
+        Changes:   entity.DiffVariant(nil, variant),
+    })
+    if err != nil {
+        return err
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *variantRepository) Update(ctx context.Context, variant *entity.Variant) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    before := &entity.Variant{}
+    err = tx.QueryRow(ctx, `
+        SELECT v.id, v.banner_id, v.name, v.creative_url, v.weight
+        FROM banner_variants v
+        JOIN banners b ON b.id = v.banner_id
+        WHERE v.id = $1 AND v.banner_id = $2
+        AND b.tenant_id = $3 AND b.deleted_at IS NULL
+        FOR UPDATE OF v
+    `, variant.ID, variant.BannerID, tenantID).Scan(&before.ID, &before.BannerID, &before.Name,
+        &before.CreativeURL, &before.Weight)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return repository.ErrVariantNotFound
+    }
+    if err != nil {
+        return err
+    }
+
+    _, err = tx.Exec(ctx, `
+        UPDATE banner_variants
+        SET name = $2, creative_url = $3, weight = $4
+        WHERE id = $1
+    `, variant.ID, variant.Name, variant.CreativeURL, variant.Weight)
+    if err != nil {
+        return err
+    }
+
+    if changes := entity.DiffVariant(before, variant); len(changes) > 0 {
+        err = insertRevision(ctx, tx, &entity.BannerRevision{
+            TenantID:  tenantID,
+            BannerID:  variant.BannerID,
+            VariantID: variant.ID,
+            Action:    entity.RevisionActionVariantUpdate,
+            Changes:   changes,
+        })
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Commit(ctx)
+}
+
+func (r *variantRepository) GetByID(ctx context.Context, variantID int64) (*entity.Variant, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    variant := &entity.Variant{}
+    err = r.db.QueryRow(ctx, `
+        SELECT v.id, v.banner_id, v.name, v.creative_url, v.weight
+        FROM banner_variants v
+        JOIN banners b ON b.id = v.banner_id
+        WHERE v.id = $1 AND b.tenant_id = $2
+    `, variantID, tenantID).Scan(&variant.ID, &variant.BannerID, &variant.Name, &variant.CreativeURL, &variant.Weight)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return nil, repository.ErrVariantNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    return variant, nil
+}
+
+func (r *variantRepository) ListByBanner(ctx context.Context, bannerID int64) ([]*entity.Variant, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    rows, err := r.db.Query(ctx, `
+        SELECT v.id, v.banner_id, v.name, v.creative_url, v.weight
+        FROM banner_variants v
+        JOIN banners b ON b.id = v.banner_id
+        WHERE v.banner_id = $1 AND b.tenant_id = $2
+        ORDER BY v.id
+    `, bannerID, tenantID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var variants []*entity.Variant
+    for rows.Next() {
+        variant := &entity.Variant{}
+        if err := rows.Scan(&variant.ID, &variant.BannerID, &variant.Name, &variant.CreativeURL, &variant.Weight); err != nil {
+            return nil, err
+        }
+        variants = append(variants, variant)
+    }
+
+    return variants, rows.Err()
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+    "context"
+    "strconv"
+    "time"
+
+    "clicker/internal/domain/repository"
+    "github.com/redis/go-redis/v9"
+)
+
+// markGapScript never moves the coverage back, since another replica may
+// have recorded a later gap already.
+var markGapScript = redis.NewScript(`
+local from = tonumber(redis.call('HGET', KEYS[1], 'from') or '0')
+if from < tonumber(ARGV[1]) then
+    redis.call('HSET', KEYS[1], 'from', ARGV[1])
+end
+redis.call('HINCRBY', KEYS[1], 'gen', 1)
+return 1
+`)
+
+// restoreCoverageScript checks the generation and moves the coverage in one
+// step, so a gap marked in between is never overwritten. A flush drops gen
+// along with everything else, so it never matches after one.
+var restoreCoverageScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'gen') ~= ARGV[1] then
+    return 0
+end
+local from = tonumber(redis.call('HGET', KEYS[1], 'from') or ARGV[2])
+if from > tonumber(ARGV[3]) then
+    return 0
+end
+if from >= tonumber(ARGV[2]) then
+    redis.call('HSET', KEYS[1], 'from', ARGV[2])
+end
+return 1
+`)
+
+type clickCoverage struct {
+    redis *redis.Client
+}
+
+func NewClickCoverage(redis *redis.Client) repository.ClickCoverage {
+    return &clickCoverage{
+        redis: redis,
+    }
+}
+
+func (c *clickCoverage) CoveredFrom(ctx context.Context) (time.Time, error) {
+    now := time.Now()
+    // Самый старый час, ключи которого ещё не истекли.
+    kept := now.Add(-clickRetention).Truncate(time.Hour).Add(time.Hour)
+
+    from, err := c.redis.HGet(ctx, clickCoverageKey, "from").Int64()
+    if err == redis.Nil {
+        return now, nil
+    }
+    if err != nil {
+        return time.Time{}, err
+    }
+
+    covered := time.Unix(from, 0)
+    if covered.Before(kept) {
+        covered = kept
+    }
+    return covered, nil
+}
+
+func (c *clickCoverage) MarkGap(ctx context.Context, until time.Time) error {
+    return markGapScript.Run(ctx, c.redis, []string{clickCoverageKey}, until.Unix()).Err()
+}
+
+func (c *clickCoverage) Claim(ctx context.Context) (int64, error) {
+    return c.redis.HIncrBy(ctx, clickCoverageKey, "gen", 1).Result()
+}
+
+func (c *clickCoverage) Restore(ctx context.Context, token int64, from, to time.Time) (bool, error) {
+    restored, err := restoreCoverageScript.Run(ctx, c.redis, []string{clickCoverageKey},
+        strconv.FormatInt(token, 10), from.Unix(), to.Unix()).Int()
+    if err != nil {
+        return false, err
+    }
+    return restored == 1, nil
+}
@@ -0,0 +1,111 @@
+package redis
+
+import (
+    "context"
+    "encoding/json"
+    "log"
+    "sync"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "github.com/redis/go-redis/v9"
+)
+
+const clickFeedChannel = "clicker:clicks"
+
+// subscriberBuffer is how many flushes a slow subscriber may lag behind
+// before it starts losing them.
+const subscriberBuffer = 64
+
+type feedSubscriber struct {
+    tenantID int64
+    updates  chan []*entity.ClickUpdate
+}
+
+// clickFeed keeps a single Redis subscription per replica and fans the
+// messages out to local subscribers.
+type clickFeed struct {
+    redis *redis.Client
+
+    mu          sync.RWMutex
+    subscribers map[*feedSubscriber]struct{}
+    once        sync.Once
+}
+
+func NewClickFeed(redis *redis.Client) repository.ClickFeed {
+    return &clickFeed{
+        redis:       redis,
+        subscribers: make(map[*feedSubscriber]struct{}),
+    }
+}
+
+func (f *clickFeed) Publish(ctx context.Context, updates []*entity.ClickUpdate) error {
+    payload, err := json.Marshal(updates)
+    if err != nil {
+        return err
+    }
+    return f.redis.Publish(ctx, clickFeedChannel, payload).Err()
+}
+
+func (f *clickFeed) Subscribe(ctx context.Context, tenantID int64) (<-chan []*entity.ClickUpdate, error) {
+    f.once.Do(func() {
+        go f.listen()
+    })
+
+    sub := &feedSubscriber{
+        tenantID: tenantID,
+        updates:  make(chan []*entity.ClickUpdate, subscriberBuffer),
+    }
+
+    f.mu.Lock()
+    f.subscribers[sub] = struct{}{}
+    f.mu.Unlock()
+
+    go func() {
+        <-ctx.Done()
+        f.mu.Lock()
+        delete(f.subscribers, sub)
+        f.mu.Unlock()
+        close(sub.updates)
+    }()
+
+    return sub.updates, nil
+}
+
+// listen runs until the Redis client is closed. go-redis resubscribes by
+// itself after a reconnect.
+func (f *clickFeed) listen() {
+    pubsub := f.redis.Subscribe(context.Background(), clickFeedChannel)
+    defer pubsub.Close()
+
+    for msg := range pubsub.Channel() {
+        var updates []*entity.ClickUpdate
+        if err := json.Unmarshal([]byte(msg.Payload), &updates); err != nil {
+            log.Printf("Redis: Invalid click feed message: %v", err)
+            continue
+        }
+        f.dispatch(updates)
+    }
+}
+
+func (f *clickFeed) dispatch(updates []*entity.ClickUpdate) {
+    byTenant := make(map[int64][]*entity.ClickUpdate)
+    for _, u := range updates {
+        byTenant[u.TenantID] = append(byTenant[u.TenantID], u)
+    }
+
+    f.mu.RLock()
+    defer f.mu.RUnlock()
+
+    for sub := range f.subscribers {
+        tenantUpdates, ok := byTenant[sub.tenantID]
+        if !ok {
+            continue
+        }
+        select {
+        case sub.updates <- tenantUpdates:
+        default:
+            log.Printf("Redis: Dropping click update for a slow subscriber of tenant %d", sub.tenantID)
+        }
+    }
+}
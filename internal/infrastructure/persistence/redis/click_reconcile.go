@@ -0,0 +1,209 @@
+package redis
+
+import (
+    "context"
+    "encoding/json"
+    "strconv"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/pkg/hll"
+    "github.com/redis/go-redis/v9"
+)
+
+// mergeHourScript raises each second of the hour to the count from Postgres,
+// keeping clicks counted meanwhile, and then sets the leaderboard scores of
+// the banner from the merged seconds. KEYS are the hour hash, the hour
+// leaderboard and the 60 minute leaderboards; ARGV the member, the hour
+// start, when the hash expires, the leaderboard retention in seconds and
+// then pairs of second and count.
+var mergeHourScript = redis.NewScript(`
+for i = 5, #ARGV, 2 do
+    local current = tonumber(redis.call('HGET', KEYS[1], ARGV[i]) or '0')
+    if current < tonumber(ARGV[i + 1]) then
+        redis.call('HSET', KEYS[1], ARGV[i], ARGV[i + 1])
+    end
+end
+
+local hour = tonumber(ARGV[2])
+local retention = tonumber(ARGV[4])
+local minutes = {}
+local total = 0
+local fields = redis.call('HGETALL', KEYS[1])
+for i = 1, #fields, 2 do
+    local minute = math.floor((tonumber(fields[i]) - hour) / 60)
+    local count = tonumber(fields[i + 1])
+    minutes[minute] = (minutes[minute] or 0) + count
+    total = total + count
+end
+if #fields > 0 then
+    redis.call('EXPIREAT', KEYS[1], ARGV[3])
+end
+
+for minute = 0, 59 do
+    local key = KEYS[minute + 3]
+    if minutes[minute] then
+        redis.call('ZADD', key, minutes[minute], ARGV[1])
+        redis.call('EXPIREAT', key, hour + minute * 60 + retention)
+    else
+        redis.call('ZREM', key, ARGV[1])
+    end
+end
+if total > 0 then
+    redis.call('ZADD', KEYS[2], total, ARGV[1])
+    redis.call('EXPIREAT', KEYS[2], hour + retention)
+else
+    redis.call('ZREM', KEYS[2], ARGV[1])
+end
+return total
+`)
+
+type clickHourCache struct {
+    redis *redis.Client
+}
+
+func NewClickHourCache(redis *redis.Client) repository.ClickHourCache {
+    return &clickHourCache{
+        redis: redis,
+    }
+}
+
+func (c *clickHourCache) HourCounts(ctx context.Context, hours []*entity.HourCount) ([]int64, error) {
+    if len(hours) == 0 {
+        return nil, nil
+    }
+
+    pipe := c.redis.Pipeline()
+    for _, h := range hours {
+        pipe.HVals(ctx, clickHourKey(h.TenantID, h.BannerID, h.Hour.Unix()))
+    }
+
+    cmds, err := pipe.Exec(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    counts := make([]int64, len(hours))
+    for i, cmd := range cmds {
+        for _, value := range cmd.(*redis.StringSliceCmd).Val() {
+            count, err := strconv.ParseInt(value, 10, 64)
+            if err != nil {
+                continue
+            }
+            counts[i] += count
+        }
+    }
+    return counts, nil
+}
+
+// ReplaceHour rewrites the hour in one MULTI, so readers see either the old
+// counters or the new ones, never an empty hour.
+func (c *clickHourCache) ReplaceHour(ctx context.Context, tenantID, bannerID int64, hour time.Time,
+    clicks []*entity.Click) error {
+    hour = hour.Truncate(time.Hour)
+    key := clickHourKey(tenantID, bannerID, hour.Unix())
+    member := strconv.FormatInt(bannerID, 10)
+
+    fields := make(map[string]any, len(clicks))
+    minutes := make(map[int64]int64)
+    var total int64
+    for _, click := range clicks {
+        fields[strconv.FormatInt(click.Timestamp.Unix(), 10)] = click.Count
+        minutes[click.Timestamp.Truncate(time.Minute).Unix()] += int64(click.Count)
+        total += int64(click.Count)
+    }
+
+    pipe := c.redis.TxPipeline()
+    pipe.Del(ctx, key)
+    if len(fields) > 0 {
+        pipe.HSet(ctx, key, fields)
+        pipe.ExpireAt(ctx, key, hour.Add(clickRetention))
+    }
+
+    // Баннер убирается из всех минут часа, а потом ставится туда, где
+    // у него есть клики, чтобы не осталось лишних очков.
+    for minute := hour; minute.Before(hour.Add(time.Hour)); minute = minute.Add(time.Minute) {
+        pipe.ZRem(ctx, topMinuteKey(tenantID, minute.Unix()), member)
+    }
+    for minute, count := range minutes {
+        minuteKey := topMinuteKey(tenantID, minute)
+        pipe.ZAdd(ctx, minuteKey, redis.Z{Score: float64(count), Member: member})
+        pipe.ExpireAt(ctx, minuteKey, time.Unix(minute, 0).Add(topRetention))
+    }
+
+    hourKey := topHourKey(tenantID, hour.Unix())
+    if total > 0 {
+        pipe.ZAdd(ctx, hourKey, redis.Z{Score: float64(total), Member: member})
+        pipe.ExpireAt(ctx, hourKey, hour.Add(topRetention))
+    } else {
+        pipe.ZRem(ctx, hourKey, member)
+    }
+
+    _, err := pipe.Exec(ctx)
+    return err
+}
+
+func (c *clickHourCache) MergeHour(ctx context.Context, tenantID, bannerID int64, hour time.Time,
+    clicks []*entity.Click) error {
+    hour = hour.Truncate(time.Hour)
+
+    keys := make([]string, 0, 62)
+    keys = append(keys, clickHourKey(tenantID, bannerID, hour.Unix()), topHourKey(tenantID, hour.Unix()))
+    for minute := hour; minute.Before(hour.Add(time.Hour)); minute = minute.Add(time.Minute) {
+        keys = append(keys, topMinuteKey(tenantID, minute.Unix()))
+    }
+
+    args := make([]any, 0, 4+2*len(clicks))
+    args = append(args, bannerID, hour.Unix(), hour.Add(clickRetention).Unix(), int64(topRetention/time.Second))
+    for _, click := range clicks {
+        args = append(args, click.Timestamp.Unix(), click.Count)
+    }
+
+    return mergeHourScript.Run(ctx, c.redis, keys, args...).Err()
+}
+
+// MergeSketch loads the sketch next to the cached one and merges them with
+// PFMERGE, so clickers added meanwhile are kept.
+func (c *clickHourCache) MergeSketch(ctx context.Context, tenantID, bannerID int64, hour time.Time,
+    sketch *hll.Sketch) error {
+    data, err := sketch.MarshalBinary()
+    if err != nil {
+        return err
+    }
+    hour = hour.Truncate(time.Hour)
+    key := uniqueKey(tenantID, bannerID, hour.Unix())
+    loaded := key + ":rebuild"
+
+    pipe := c.redis.TxPipeline()
+    pipe.Set(ctx, loaded, data, time.Minute)
+    pipe.PFMerge(ctx, key, loaded)
+    pipe.Del(ctx, loaded)
+    pipe.ExpireAt(ctx, key, hour.Add(topRetention))
+    _, err = pipe.Exec(ctx)
+    return err
+}
+
+func (c *clickHourCache) Rebuild(ctx context.Context) (*entity.CacheRebuild, error) {
+    data, err := c.redis.Get(ctx, cacheRebuildKey).Bytes()
+    if err == redis.Nil {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var rebuild entity.CacheRebuild
+    if err := json.Unmarshal(data, &rebuild); err != nil {
+        return nil, err
+    }
+    return &rebuild, nil
+}
+
+func (c *clickHourCache) SaveRebuild(ctx context.Context, rebuild *entity.CacheRebuild) error {
+    data, err := json.Marshal(rebuild)
+    if err != nil {
+        return err
+    }
+    return c.redis.Set(ctx, cacheRebuildKey, data, 0).Err()
+}
@@ -2,16 +2,22 @@ package redis
 
 import (
     "context"
-    "fmt"
-    "time"
+    "log"
+    "sort"
     "strconv"
+    "time"
+
     "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
     "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
     "github.com/redis/go-redis/v9"
-    "log"
-    "strings"
 )
 
+// clickRetention is how long an hour of clicks is kept after it starts:
+// the hour itself plus the 24 hours served from Redis.
+const clickRetention = 25 * time.Hour
+
 type clickRepository struct {
     redis *redis.Client
 }
@@ -24,80 +30,116 @@ func NewClickRepository(redis *redis.Client) repository.ClickRepository {
 
 func (r *clickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
     pipe := r.redis.Pipeline()
+    // Срок жизни ставится один раз на ключ за пачку, а не на каждый инкремент.
+    expireAt := make(map[string]time.Time)
     
     for _, click := range clicks {
-        key := fmt.Sprintf("banner:%d:%d", click.BannerID, click.Timestamp.Unix())
-        pipe.IncrBy(ctx, key, int64(click.Count))
-        pipe.Expire(ctx, key, 24*time.Hour)
+        hour := click.Timestamp.Truncate(time.Hour)
+        key := clickHourKey(click.TenantID, click.BannerID, hour.Unix())
+        pipe.HIncrBy(ctx, key, strconv.FormatInt(click.Timestamp.Unix(), 10), int64(click.Count))
+        expireAt[key] = hour.Add(clickRetention)
+
+        member := strconv.FormatInt(click.BannerID, 10)
+        minute := click.Timestamp.Truncate(time.Minute)
+        minuteKey := topMinuteKey(click.TenantID, minute.Unix())
+        hourKey := topHourKey(click.TenantID, hour.Unix())
+        pipe.ZIncrBy(ctx, minuteKey, float64(click.Count), member)
+        pipe.ZIncrBy(ctx, hourKey, float64(click.Count), member)
+        expireAt[minuteKey] = minute.Add(topRetention)
+        expireAt[hourKey] = hour.Add(topRetention)
+    }
+
+    for key, at := range expireAt {
+        pipe.ExpireAt(ctx, key, at)
+    }
+    // Первая пачка после очистки Redis начинает его покрытие.
+    if len(clicks) > 0 {
+        pipe.HSetNX(ctx, clickCoverageKey, "from", earliestClick(clicks).Unix())
     }
     
     _, err := pipe.Exec(ctx)
     return err
 }
 
+func earliestClick(clicks []*entity.Click) time.Time {
+    earliest := clicks[0].Timestamp
+    for _, click := range clicks[1:] {
+        if click.Timestamp.Before(earliest) {
+            earliest = click.Timestamp
+        }
+    }
+    return earliest
+}
+
 func (r *clickRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
     log.Printf("Redis: Getting stats for banner %d from %v to %v", bannerID, from, to)
     
-    // Сначала получим все ключи для данного баннера
-    pattern := fmt.Sprintf("banner:%d:*", bannerID)
-    keys, err := r.redis.Keys(ctx, pattern).Result()
+    tenantID, err := tenant.ID(ctx)
     if err != nil {
-        log.Printf("Redis: Error getting keys: %v", err)
         return nil, err
     }
-    
-    log.Printf("Redis: Found %d keys for banner %d", len(keys), bannerID)
-    
-    if len(keys) == 0 {
-        return nil, nil
+
+    var clicks []*entity.Click
+    err = readClickHours(ctx, r.redis, tenantID, []int64{bannerID}, from, to, func(bannerID int64, ts time.Time, count int64) {
+        clicks = append(clicks, &entity.Click{
+            BannerID:  bannerID,
+            Timestamp: ts,
+            Count:     int(count),
+        })
+    })
+    if err != nil {
+        log.Printf("Redis: Error reading clicks: %v", err)
+        return nil, err
     }
 
-    // Получаем все значения за раз
-    pipe := r.redis.Pipeline()
-    for _, key := range keys {
-        pipe.Get(ctx, key)
+    sort.Slice(clicks, func(i, j int) bool {
+        return clicks[i].Timestamp.Before(clicks[j].Timestamp)
+    })
+    return clicks, nil
+}
+
+// readClickHours reads the hourly hashes of the banners covering
+// [from, to] in one pipeline and calls fn for every second in the range.
+// The number of keys read depends only on the range, never on the keyspace.
+func readClickHours(ctx context.Context, client *redis.Client, tenantID int64, bannerIDs []int64,
+    from, to time.Time, fn func(bannerID int64, ts time.Time, count int64)) error {
+    from, to = keptRange(from, to, clickRetention)
+    if from.After(to) {
+        return nil
     }
-    
+
+    pipe := client.Pipeline()
+    var owners []int64
+    for _, bannerID := range bannerIDs {
+        for hour := from.Truncate(time.Hour); !hour.After(to); hour = hour.Add(time.Hour) {
+            pipe.HGetAll(ctx, clickHourKey(tenantID, bannerID, hour.Unix()))
+            owners = append(owners, bannerID)
+        }
+    }
+
     cmds, err := pipe.Exec(ctx)
-    if err != nil && err != redis.Nil {
-        log.Printf("Redis: Error executing pipeline: %v", err)
-        return nil, err
+    if err != nil {
+        return err
     }
-    
-    clicks := make([]*entity.Click, 0)
-    
+    freshness.Record(ctx, entity.TierCache, from, to)
+
     for i, cmd := range cmds {
-        if cmd.Err() == redis.Nil {
-            continue
-        }
-        
-        count, err := cmd.(*redis.StringCmd).Int64()
-        if err != nil {
-            log.Printf("Redis: Error parsing count from key %s: %v", keys[i], err)
-            continue
-        }
-        
-        parts := strings.Split(keys[i], ":")
-        if len(parts) != 3 {
-            continue
+        for field, value := range cmd.(*redis.MapStringStringCmd).Val() {
+            sec, err := strconv.ParseInt(field, 10, 64)
+            if err != nil {
+                continue
+            }
+            ts := time.Unix(sec, 0)
+            if ts.Before(from) || ts.After(to) {
+                continue
+            }
+            count, err := strconv.ParseInt(value, 10, 64)
+            if err != nil {
+                continue
+            }
+            fn(owners[i], ts, count)
         }
-        
-        ts, err := strconv.ParseInt(parts[2], 10, 64)
-        if err != nil {
-            continue
-        }
-        
-        timestamp := time.Unix(ts, 0)
-        if timestamp.Before(from) || timestamp.After(to) {
-            continue
-        }
-        
-        clicks = append(clicks, &entity.Click{
-            BannerID:  bannerID,
-            Timestamp: timestamp,
-            Count:     int(count),
-        })
     }
-    
-    return clicks, nil
+
+    return nil
 }
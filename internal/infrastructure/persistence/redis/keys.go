@@ -0,0 +1,91 @@
+package redis
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Все ключи тенанта лежат под префиксом tenant:<id>:, чтобы тенанты
+// не пересекались в общем keyspace.
+func tenantPrefix(tenantID int64) string {
+    return fmt.Sprintf("tenant:%d:", tenantID)
+}
+
+// Клики баннера за час: хэш, где поле — секунда (unix), а значение — число
+// кликов в эту секунду. Диапазон читается по часовым ключам, без KEYS.
+func clickHourKey(tenantID, bannerID, hour int64) string {
+    return fmt.Sprintf("%sclicks:%d:%d", tenantPrefix(tenantID), bannerID, hour)
+}
+
+// Лидерборды: поминутные и почасовые sorted set'ы, где member — ID баннера,
+// а score — число кликов за этот интервал.
+func topMinuteKey(tenantID, minute int64) string {
+    return fmt.Sprintf("%stop:m:%d", tenantPrefix(tenantID), minute)
+}
+
+func topHourKey(tenantID, hour int64) string {
+    return fmt.Sprintf("%stop:h:%d", tenantPrefix(tenantID), hour)
+}
+
+// uniqueKey is the HyperLogLog of clicker IDs of a banner in one hour.
+func uniqueKey(tenantID, bannerID, hour int64) string {
+    return fmt.Sprintf("%suniq:%d:%d", tenantPrefix(tenantID), bannerID, hour)
+}
+
+// keptRange clamps a read to the keys that can exist: nothing older than
+// retention is kept, and nothing newer than the current hour is written (an
+// hour of slack covers the clocks of other replicas). A far-off end would
+// otherwise make one read walk millions of empty keys.
+func keptRange(from, to time.Time, retention time.Duration) (time.Time, time.Time) {
+    now := time.Now()
+    if earliest := now.Add(-retention); from.Before(earliest) {
+        from = earliest
+    }
+    if latest := now.Add(time.Hour); to.After(latest) {
+        to = latest
+    }
+    return from, to
+}
+
+// Кэш статистики: водяной знак баннера растёт при каждом сбросе его кликов,
+// и входит в ключи закэшированных ответов, так что старые просто не читаются.
+func statsWatermarkKey(tenantID, bannerID int64) string {
+    return fmt.Sprintf("%sstats:wm:%d", tenantPrefix(tenantID), bannerID)
+}
+
+func statsCacheKey(tenantID, bannerID, watermark int64, key string) string {
+    return fmt.Sprintf("%sstats:cache:%d:%d:%s", tenantPrefix(tenantID), bannerID, watermark, key)
+}
+
+// Прогресс пересборки кэша из Postgres. Общий для всех тенантов и без TTL:
+// пропал ключ - значит, Redis опустел и его пора собирать заново.
+const cacheRebuildKey = "cache:rebuild"
+
+// Покрытие кэша кликов: хэш с полями from (unix, с этой секунды в Redis
+// есть все клики из Postgres) и gen (растёт при каждой дыре). Общий для
+// всех тенантов, потому что пачка кликов смешивает тенантов.
+const clickCoverageKey = "clicks:coverage"
+
+// Старая раскладка: отдельный ключ tenant:<id>:banner:<banner>:<секунда> на
+// каждую секунду. Нужна только для переноса в часовые хэши.
+const legacyClickKeyPattern = "tenant:*:banner:*"
+
+// parseLegacyClickKey splits a key of the old per-second layout.
+func parseLegacyClickKey(key string) (tenantID, bannerID, ts int64, ok bool) {
+    parts := strings.Split(key, ":")
+    if len(parts) != 5 || parts[0] != "tenant" || parts[2] != "banner" {
+        return 0, 0, 0, false
+    }
+
+    var ids [3]int64
+    for i, part := range []string{parts[1], parts[3], parts[4]} {
+        id, err := strconv.ParseInt(part, 10, 64)
+        if err != nil {
+            return 0, 0, 0, false
+        }
+        ids[i] = id
+    }
+    return ids[0], ids[1], ids[2], true
+}
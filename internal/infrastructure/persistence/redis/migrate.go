@@ -0,0 +1,54 @@
+package redis
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    "github.com/redis/go-redis/v9"
+)
+
+// migrateClickKeyScript moves one per-second counter into its hourly hash.
+// It runs atomically, so increments a not yet upgraded replica makes to the
+// old key are either moved along or land after it is gone.
+var migrateClickKeyScript = redis.NewScript(`
+local count = redis.call('GET', KEYS[1])
+if not count then
+    return 0
+end
+redis.call('HINCRBY', KEYS[2], ARGV[1], count)
+redis.call('EXPIREAT', KEYS[2], ARGV[2])
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+// MigrateClickKeys moves click counters from the old one-key-per-second
+// layout into the hourly hashes. The keyspace is walked with SCAN, so the
+// migration never blocks Redis, and it can be stopped and run again.
+func MigrateClickKeys(ctx context.Context, client *redis.Client) (int, error) {
+    moved := 0
+    iter := client.Scan(ctx, 0, legacyClickKeyPattern, 1000).Iterator()
+    for iter.Next(ctx) {
+        key := iter.Val()
+        tenantID, bannerID, ts, ok := parseLegacyClickKey(key)
+        if !ok {
+            continue
+        }
+
+        hour := time.Unix(ts, 0).Truncate(time.Hour)
+        n, err := migrateClickKeyScript.Run(ctx, client,
+            []string{key, clickHourKey(tenantID, bannerID, hour.Unix())},
+            strconv.FormatInt(ts, 10), hour.Add(clickRetention).Unix(),
+        ).Int()
+        if err != nil {
+            return moved, fmt.Errorf("failed to migrate %s: %w", key, err)
+        }
+        moved += n
+    }
+    if err := iter.Err(); err != nil {
+        return moved, fmt.Errorf("failed to scan click keys: %w", err)
+    }
+
+    return moved, nil
+}
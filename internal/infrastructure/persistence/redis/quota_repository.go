@@ -0,0 +1,33 @@
+package redis
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "clicker/internal/domain/repository"
+    "github.com/redis/go-redis/v9"
+)
+
+type quotaRepository struct {
+    redis *redis.Client
+}
+
+func NewQuotaRepository(redis *redis.Client) repository.QuotaRepository {
+    return &quotaRepository{
+        redis: redis,
+    }
+}
+
+func (r *quotaRepository) IncrDailyClicks(ctx context.Context, tenantID int64, day time.Time, n int64) (int64, error) {
+    key := fmt.Sprintf("%squota:clicks:%s", tenantPrefix(tenantID), day.UTC().Format("20060102"))
+
+    pipe := r.redis.TxPipeline()
+    incr := pipe.IncrBy(ctx, key, n)
+    pipe.Expire(ctx, key, 48*time.Hour)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return 0, err
+    }
+
+    return incr.Val(), nil
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "github.com/redis/go-redis/v9"
+)
+
+// watermarkRetention outlives any cache entry by far, so a watermark that
+// expired and starts over never meets an entry from its previous life.
+const watermarkRetention = 25 * time.Hour
+
+type statsCache struct {
+    redis *redis.Client
+}
+
+func NewStatsCache(redis *redis.Client) repository.StatsCache {
+    return &statsCache{
+        redis: redis,
+    }
+}
+
+func (c *statsCache) Watermark(ctx context.Context, bannerID int64) (int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return 0, err
+    }
+
+    watermark, err := c.redis.Get(ctx, statsWatermarkKey(tenantID, bannerID)).Int64()
+    if err == redis.Nil {
+        return 0, nil
+    }
+    return watermark, err
+}
+
+func (c *statsCache) Advance(ctx context.Context, updates []*entity.ClickUpdate) error {
+    if len(updates) == 0 {
+        return nil
+    }
+
+    pipe := c.redis.Pipeline()
+    for _, u := range updates {
+        key := statsWatermarkKey(u.TenantID, u.BannerID)
+        pipe.Incr(ctx, key)
+        pipe.Expire(ctx, key, watermarkRetention)
+    }
+
+    _, err := pipe.Exec(ctx)
+    return err
+}
+
+func (c *statsCache) Get(ctx context.Context, bannerID, watermark int64, key string) ([]byte, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    data, err := c.redis.Get(ctx, statsCacheKey(tenantID, bannerID, watermark, key)).Bytes()
+    if err == redis.Nil {
+        return nil, nil
+    }
+    return data, err
+}
+
+func (c *statsCache) Set(ctx context.Context, bannerID, watermark int64, key string, value []byte, ttl time.Duration) error {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return err
+    }
+
+    return c.redis.Set(ctx, statsCacheKey(tenantID, bannerID, watermark, key), value, ttl).Err()
+}
@@ -2,13 +2,12 @@ package redis
 
 import (
     "context"
-    "fmt"
     "log"
-    "strconv"
-    "strings"
     "time"
     
     "clicker/internal/domain/entity"
+    "clicker/internal/domain/tenant"
+    "clicker/internal/domain/timeseries"
     "github.com/redis/go-redis/v9"
 )
 
@@ -25,62 +24,66 @@ func NewStatsRepository(redis *redis.Client) *statsRepository {
 func (r *statsRepository) GetStats(ctx context.Context, bannerID int64, from, to time.Time) ([]*entity.Click, error) {
     log.Printf("Redis: Getting stats for banner %d from %v to %v", bannerID, from, to)
     
-    pattern := fmt.Sprintf("banner:%d:*", bannerID)
-    keys, err := r.redis.Keys(ctx, pattern).Result()
+    tenantID, err := tenant.ID(ctx)
     if err != nil {
-        log.Printf("Redis: Error getting keys: %v", err)
         return nil, err
     }
-    
-    log.Printf("Redis: Found %d keys for banner %d", len(keys), bannerID)
-    
-    if len(keys) == 0 {
-        return nil, nil
-    }
 
-    pipe := r.redis.Pipeline()
-    for _, key := range keys {
-        pipe.Get(ctx, key)
+    var clicks []*entity.Click
+    err = readClickHours(ctx, r.redis, tenantID, []int64{bannerID}, from, to, func(bannerID int64, ts time.Time, count int64) {
+        clicks = append(clicks, &entity.Click{
+            BannerID:  bannerID,
+            Timestamp: ts,
+            Count:     int(count),
+        })
+    })
+    if err != nil {
+        return nil, err
     }
-    
-    cmds, err := pipe.Exec(ctx)
-    if err != nil && err != redis.Nil {
+
+    log.Printf("Redis: Returning %d clicks", len(clicks))
+    return clicks, nil
+}
+
+// GetSeries buckets the per-second counters in Go, Redis keeps no coarser
+// aggregates.
+func (r *statsRepository) GetSeries(ctx context.Context, bannerID int64, from, to time.Time,
+    granularity string, loc *time.Location) ([]*entity.Click, error) {
+    clicks, err := r.GetStats(ctx, bannerID, from, to)
+    if err != nil {
         return nil, err
     }
 
-    clicks := make([]*entity.Click, 0)
-    for i, cmd := range cmds {
-        if cmd.Err() == redis.Nil {
-            continue
-        }
-        
-        count, err := cmd.(*redis.StringCmd).Int64()
-        if err != nil {
+    buckets := make(map[int64]*entity.Click)
+    var series []*entity.Click
+    for _, click := range clicks {
+        start := timeseries.Truncate(click.Timestamp, granularity, loc)
+        if bucket, ok := buckets[start.Unix()]; ok {
+            bucket.Count += click.Count
             continue
         }
+        bucket := &entity.Click{BannerID: bannerID, Timestamp: start, Count: click.Count}
+        buckets[start.Unix()] = bucket
+        series = append(series, bucket)
+    }
 
-        parts := strings.Split(keys[i], ":")
-        if len(parts) != 3 {
-            continue
-        }
-        
-        ts, err := strconv.ParseInt(parts[2], 10, 64)
-        if err != nil {
-            continue
-        }
+    return series, nil
+}
 
-        timestamp := time.Unix(ts, 0)
-        if timestamp.Before(from) || timestamp.After(to) {
-            continue
-        }
+// GetTotals reads the hourly hashes of all banners in one pipeline.
+func (r *statsRepository) GetTotals(ctx context.Context, bannerIDs []int64, from, to time.Time) (map[int64]int64, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
 
-        clicks = append(clicks, &entity.Click{
-            BannerID:  bannerID,
-            Timestamp: timestamp,
-            Count:     int(count),
-        })
+    totals := make(map[int64]int64, len(bannerIDs))
+    err = readClickHours(ctx, r.redis, tenantID, bannerIDs, from, to, func(bannerID int64, _ time.Time, count int64) {
+        totals[bannerID] += count
+    })
+    if err != nil {
+        return nil, err
     }
 
-    log.Printf("Redis: Returning %d clicks", len(clicks))
-    return clicks, nil
+    return totals, nil
 }
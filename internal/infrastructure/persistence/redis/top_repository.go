@@ -0,0 +1,96 @@
+package redis
+
+import (
+    "context"
+    "log"
+    "sort"
+    "strconv"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "github.com/redis/go-redis/v9"
+)
+
+// topRetention is a bit longer than the 24 hours the leaderboards are read
+// for, so the oldest hour is still complete.
+const topRetention = 25 * time.Hour
+
+type topBannersRepository struct {
+    redis *redis.Client
+}
+
+func NewTopBannersRepository(redis *redis.Client) repository.TopBannersRepository {
+    return &topBannersRepository{
+        redis: redis,
+    }
+}
+
+func (r *topBannersRepository) TopBanners(ctx context.Context, from, to time.Time, limit int) ([]*entity.BannerTotal, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    keys := topKeys(tenantID, from, to)
+    if len(keys) == 0 {
+        return nil, nil
+    }
+
+    scores, err := r.redis.ZUnionWithScores(ctx, redis.ZStore{Keys: keys}).Result()
+    if err != nil {
+        return nil, err
+    }
+    freshness.Record(ctx, entity.TierCache, from, to)
+
+    top := make([]*entity.BannerTotal, 0, len(scores))
+    for _, z := range scores {
+        member, ok := z.Member.(string)
+        if !ok {
+            continue
+        }
+        bannerID, err := strconv.ParseInt(member, 10, 64)
+        if err != nil {
+            continue
+        }
+        top = append(top, &entity.BannerTotal{
+            BannerID: bannerID,
+            Clicks:   int64(z.Score),
+        })
+    }
+
+    sort.Slice(top, func(i, j int) bool {
+        if top[i].Clicks != top[j].Clicks {
+            return top[i].Clicks > top[j].Clicks
+        }
+        return top[i].BannerID < top[j].BannerID
+    })
+    if len(top) > limit {
+        top = top[:limit]
+    }
+
+    log.Printf("Redis: Ranked %d banners over %d leaderboard keys", len(scores), len(keys))
+    return top, nil
+}
+
+// topKeys covers [from, to) with whole-hour leaderboards where possible and
+// minute leaderboards at the edges, so a 24h window reads about 140 keys
+// instead of 1440. The start is rounded down to the minute, and the range
+// is cut to the leaderboards that can exist.
+func topKeys(tenantID int64, from, to time.Time) []string {
+    from, to = keptRange(from, to, topRetention)
+
+    var keys []string
+    for cur := from.Truncate(time.Minute); cur.Before(to); {
+        if cur.Equal(cur.Truncate(time.Hour)) && !cur.Add(time.Hour).After(to) {
+            keys = append(keys, topHourKey(tenantID, cur.Unix()))
+            cur = cur.Add(time.Hour)
+            continue
+        }
+        keys = append(keys, topMinuteKey(tenantID, cur.Unix()))
+        cur = cur.Add(time.Minute)
+    }
+    return keys
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+    "context"
+    "time"
+
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/freshness"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+    "clicker/pkg/hll"
+    "github.com/redis/go-redis/v9"
+)
+
+type uniqueClickRepository struct {
+    redis *redis.Client
+}
+
+func NewUniqueClickRepository(redis *redis.Client) repository.UniqueClickRepository {
+    return &uniqueClickRepository{
+        redis: redis,
+    }
+}
+
+func (r *uniqueClickRepository) SaveBatch(ctx context.Context, clicks []*entity.Click) error {
+    pipe := r.redis.Pipeline()
+
+    for _, click := range clicks {
+        if click.ClickerID == "" {
+            continue
+        }
+        key := uniqueKey(click.TenantID, click.BannerID, click.Timestamp.Truncate(time.Hour).Unix())
+        pipe.PFAdd(ctx, key, click.ClickerID)
+        pipe.Expire(ctx, key, topRetention)
+    }
+
+    if pipe.Len() == 0 {
+        return nil
+    }
+    _, err := pipe.Exec(ctx)
+    return err
+}
+
+// GetSketch reads the raw HyperLogLogs with GET and merges them in Go, so
+// they can be merged with the Postgres ones as well.
+func (r *uniqueClickRepository) GetSketch(ctx context.Context, bannerID int64, from, to time.Time) (*hll.Sketch, error) {
+    tenantID, err := tenant.ID(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    pipe := r.redis.Pipeline()
+    kept, until := keptRange(from, to, topRetention)
+    for hour := kept.Truncate(time.Hour); hour.Before(until); hour = hour.Add(time.Hour) {
+        pipe.Get(ctx, uniqueKey(tenantID, bannerID, hour.Unix()))
+    }
+
+    sketch := hll.New()
+    if pipe.Len() == 0 {
+        return sketch, nil
+    }
+
+    cmds, err := pipe.Exec(ctx)
+    if err != nil && err != redis.Nil {
+        return nil, err
+    }
+    freshness.Record(ctx, entity.TierCache, from, to)
+
+    for _, cmd := range cmds {
+        data, err := cmd.(*redis.StringCmd).Bytes()
+        if err == redis.Nil {
+            continue
+        }
+        if err != nil {
+            return nil, err
+        }
+        bucket := hll.New()
+        if err := bucket.UnmarshalBinary(data); err != nil {
+            return nil, err
+        }
+        sketch.Merge(bucket)
+    }
+
+    return sketch, nil
+}
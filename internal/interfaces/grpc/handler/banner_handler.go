@@ -0,0 +1,149 @@
+package handler
+
+import (
+    "context"
+    "clicker/internal/application/dto"
+    "clicker/internal/application/usecase"
+    "clicker/internal/domain/entity"
+    "clicker/pkg/banner"
+)
+
+type BannerHandler struct {
+    banner.UnimplementedBannerServiceServer
+    useCase usecase.BannerUseCase
+}
+
+func NewBannerHandler(useCase usecase.BannerUseCase) *BannerHandler {
+    return &BannerHandler{useCase: useCase}
+}
+
+func (h *BannerHandler) GetBanner(ctx context.Context, req *banner.GetBannerRequest) (*banner.Banner, error) {
+    b, err := h.useCase.GetBanner(ctx, req.BannerId)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToBannerProto(b), nil
+}
+
+func (h *BannerHandler) DeleteBanner(ctx context.Context, req *banner.DeleteBannerRequest) (*banner.DeleteBannerResponse, error) {
+    if err := h.useCase.DeleteBanner(ctx, req.BannerId); err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return &banner.DeleteBannerResponse{BannerId: req.BannerId}, nil
+}
+
+func (h *BannerHandler) GetBannerHistory(ctx context.Context, req *banner.GetBannerHistoryRequest) (*banner.BannerHistory, error) {
+    revisions, err := h.useCase.GetBannerHistory(ctx, req.BannerId)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    resp := &banner.BannerHistory{
+        Revisions: make([]*banner.BannerRevision, 0, len(revisions)),
+    }
+    for _, r := range revisions {
+        resp.Revisions = append(resp.Revisions, dto.ToBannerRevisionProto(r))
+    }
+
+    return resp, nil
+}
+
+func (h *BannerHandler) SetBannerLabels(ctx context.Context, req *banner.SetBannerLabelsRequest) (*banner.Banner, error) {
+    b, err := h.useCase.SetLabels(ctx, req.BannerId, req.Labels)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToBannerProto(b), nil
+}
+
+func (h *BannerHandler) CreateVariant(ctx context.Context, req *banner.CreateVariantRequest) (*banner.Variant, error) {
+    v, err := h.useCase.CreateVariant(ctx, &entity.Variant{
+        BannerID:    req.BannerId,
+        Name:        req.Name,
+        CreativeURL: req.CreativeUrl,
+        Weight:      int(req.Weight),
+    })
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToVariantProto(v), nil
+}
+
+func (h *BannerHandler) UpdateVariant(ctx context.Context, req *banner.UpdateVariantRequest) (*banner.Variant, error) {
+    v, err := h.useCase.UpdateVariant(ctx, &dto.UpdateVariantRequest{
+        BannerID:    req.BannerId,
+        VariantID:   req.VariantId,
+        Name:        req.Name,
+        CreativeURL: req.CreativeUrl,
+        Weight:      optionalInt(req.Weight),
+    })
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToVariantProto(v), nil
+}
+
+func (h *BannerHandler) ListVariants(ctx context.Context, req *banner.ListVariantsRequest) (*banner.ListVariantsResponse, error) {
+    variants, err := h.useCase.ListVariants(ctx, req.BannerId)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    resp := &banner.ListVariantsResponse{
+        Variants: make([]*banner.Variant, 0, len(variants)),
+    }
+    for _, v := range variants {
+        resp.Variants = append(resp.Variants, dto.ToVariantProto(v))
+    }
+
+    return resp, nil
+}
+
+func (h *BannerHandler) CreateBanner(ctx context.Context, req *banner.CreateBannerRequest) (*banner.Banner, error) {
+    b, err := h.useCase.CreateBanner(ctx, &entity.Banner{
+        Name:               req.Name,
+        URL:                req.Url,
+        CreativeURL:        req.CreativeUrl,
+        Placement:          req.Placement,
+        Status:             req.Status,
+        Weight:             int(req.Weight),
+        DailyImpressionCap: int(req.DailyImpressionCap),
+        Labels:             req.Labels,
+    })
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToBannerProto(b), nil
+}
+
+func (h *BannerHandler) UpdateBanner(ctx context.Context, req *banner.UpdateBannerRequest) (*banner.Banner, error) {
+    b, err := h.useCase.UpdateBanner(ctx, &dto.UpdateBannerRequest{
+        BannerID:           req.BannerId,
+        Name:               req.Name,
+        URL:                req.Url,
+        CreativeURL:        req.CreativeUrl,
+        Placement:          req.Placement,
+        Status:             req.Status,
+        Weight:             optionalInt(req.Weight),
+        DailyImpressionCap: optionalInt(req.DailyImpressionCap),
+    })
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToBannerProto(b), nil
+}
+
+func optionalInt(v *int32) *int {
+    if v == nil {
+        return nil
+    }
+    i := int(*v)
+    return &i
+}
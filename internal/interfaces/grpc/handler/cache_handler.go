@@ -0,0 +1,35 @@
+package handler
+
+import (
+    "context"
+    "clicker/internal/application/dto"
+    "clicker/internal/application/usecase"
+    "clicker/pkg/cache"
+)
+
+type CacheHandler struct {
+    cache.UnimplementedCacheServiceServer
+    useCase usecase.CacheRebuildUseCase
+}
+
+func NewCacheHandler(useCase usecase.CacheRebuildUseCase) *CacheHandler {
+    return &CacheHandler{useCase: useCase}
+}
+
+func (h *CacheHandler) RebuildCache(ctx context.Context, req *cache.RebuildCacheRequest) (*cache.CacheRebuild, error) {
+    rebuild, err := h.useCase.Start(ctx)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToCacheRebuildProto(rebuild), nil
+}
+
+func (h *CacheHandler) GetCacheRebuild(ctx context.Context, req *cache.GetCacheRebuildRequest) (*cache.CacheRebuild, error) {
+    rebuild, err := h.useCase.Progress(ctx)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToCacheRebuildProto(rebuild), nil
+}
@@ -2,10 +2,10 @@ package handler
 
 import (
     "context"
+
+    "clicker/internal/application/dto"
     "clicker/internal/application/usecase"
     "clicker/pkg/counter"
-    "google.golang.org/grpc/codes"
-    "google.golang.org/grpc/status"
 )
 
 type ClickHandler struct {
@@ -18,12 +18,20 @@ func NewClickHandler(useCase usecase.ClickUseCase) *ClickHandler {
 }
 
 func (h *ClickHandler) Counter(ctx context.Context, req *counter.CounterRequest) (*counter.CounterResponse, error) {
-    total, err := h.useCase.Counter(ctx, req.BannerId)
+    total, err := h.useCase.Counter(ctx, dto.CounterRequestFromProto(req))
     if err != nil {
-        return nil, status.Error(codes.Internal, err.Error())
+        return nil, toStatusError(err)
     }
     
     return &counter.CounterResponse{
         TotalClicks: total,
     }, nil
 }
+
+func (h *ClickHandler) Impression(ctx context.Context, req *counter.ImpressionRequest) (*counter.ImpressionResponse, error) {
+    if err := h.useCase.Impression(ctx, dto.ImpressionRequestFromProto(req)); err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return &counter.ImpressionResponse{}, nil
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+    "bufio"
+    "encoding/csv"
+    "encoding/json"
+    "strconv"
+    "time"
+
+    "clicker/internal/application/dto"
+    "clicker/pkg/stats"
+    "google.golang.org/genproto/googleapis/api/httpbody"
+    "google.golang.org/protobuf/encoding/protodelim"
+)
+
+// Export formats of ExportClicks.
+const (
+    exportFormatCSV      = "csv"
+    exportFormatJSONL    = "jsonl"
+    exportFormatProtobuf = "protobuf"
+)
+
+// exportChunkSize is how much encoded output is collected before it is
+// sent as one HttpBody message.
+const exportChunkSize = 64 * 1024
+
+var exportContentTypes = map[string]string{
+    exportFormatCSV:      "text/csv",
+    exportFormatJSONL:    "application/x-ndjson",
+    exportFormatProtobuf: "application/x-protobuf",
+}
+
+var exportCSVHeader = []string{"id", "banner_id", "variant_id", "timestamp", "clicks"}
+
+type rowEncoder interface {
+    Encode(row *dto.ExportRow) error
+    // Flush writes out everything encoded so far.
+    Flush() error
+}
+
+func newRowEncoder(format string, stream stats.StatsService_ExportClicksServer) rowEncoder {
+    body := bufio.NewWriterSize(&bodyWriter{
+        stream:      stream,
+        contentType: exportContentTypes[format],
+    }, exportChunkSize)
+
+    switch format {
+    case exportFormatCSV:
+        return &csvEncoder{body: body, w: csv.NewWriter(body)}
+    case exportFormatJSONL:
+        return &jsonlEncoder{body: body, enc: json.NewEncoder(body)}
+    case exportFormatProtobuf:
+        return &protobufEncoder{body: body}
+    }
+    return nil
+}
+
+// bodyWriter sends every write as a chunk of the response body.
+type bodyWriter struct {
+    stream      stats.StatsService_ExportClicksServer
+    contentType string
+}
+
+func (w *bodyWriter) Write(p []byte) (int, error) {
+    err := w.stream.Send(&httpbody.HttpBody{
+        ContentType: w.contentType,
+        Data:        p,
+    })
+    if err != nil {
+        return 0, err
+    }
+    return len(p), nil
+}
+
+type csvEncoder struct {
+    body          *bufio.Writer
+    w             *csv.Writer
+    headerWritten bool
+}
+
+func (e *csvEncoder) Encode(row *dto.ExportRow) error {
+    if err := e.writeHeader(); err != nil {
+        return err
+    }
+    return e.w.Write([]string{
+        strconv.FormatInt(row.ID, 10),
+        strconv.FormatInt(row.BannerID, 10),
+        strconv.FormatInt(row.VariantID, 10),
+        row.Timestamp.Format(time.RFC3339Nano),
+        strconv.FormatInt(row.Clicks, 10),
+    })
+}
+
+// writeHeader starts the file with column names, even when it has no rows.
+func (e *csvEncoder) writeHeader() error {
+    if e.headerWritten {
+        return nil
+    }
+    e.headerWritten = true
+    return e.w.Write(exportCSVHeader)
+}
+
+func (e *csvEncoder) Flush() error {
+    if err := e.writeHeader(); err != nil {
+        return err
+    }
+    e.w.Flush()
+    if err := e.w.Error(); err != nil {
+        return err
+    }
+    return e.body.Flush()
+}
+
+type jsonlEncoder struct {
+    body *bufio.Writer
+    enc  *json.Encoder
+}
+
+func (e *jsonlEncoder) Encode(row *dto.ExportRow) error {
+    return e.enc.Encode(row)
+}
+
+func (e *jsonlEncoder) Flush() error {
+    return e.body.Flush()
+}
+
+type protobufEncoder struct {
+    body *bufio.Writer
+}
+
+func (e *protobufEncoder) Encode(row *dto.ExportRow) error {
+    _, err := protodelim.MarshalTo(e.body, dto.ToExportRowProto(row))
+    return err
+}
+
+func (e *protobufEncoder) Flush() error {
+    return e.body.Flush()
+}
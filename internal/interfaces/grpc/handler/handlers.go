@@ -1,9 +1,21 @@
 package handler
 
 import (
+	"errors"
+
+	"clicker/internal/application/usecase"
+	"clicker/internal/domain/repository"
+	"clicker/internal/domain/tenant"
+	"clicker/pkg/banner"
+	"clicker/pkg/cache"
 	"clicker/pkg/counter"
+	"clicker/pkg/retention"
+	"clicker/pkg/serving"
+	tenantpb "clicker/pkg/tenant"
 	"clicker/pkg/stats"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type GRPCServer interface {
@@ -18,19 +30,80 @@ type StatsService interface {
 	stats.StatsServiceServer
 }
 
+type BannerService interface {
+	banner.BannerServiceServer
+}
+
+type ServingService interface {
+	serving.ServingServiceServer
+}
+
+type TenantService interface {
+	tenantpb.TenantServiceServer
+}
+
+type RetentionService interface {
+	retention.RetentionServiceServer
+}
+
+type CacheService interface {
+	cache.CacheServiceServer
+}
+
 type Handler struct {
-	clickService ClickService
-	statsService StatsService
+	clickService     ClickService
+	statsService     StatsService
+	bannerService    BannerService
+	servingService   ServingService
+	tenantService    TenantService
+	retentionService RetentionService
+	cacheService     CacheService
 }
 
-func NewHandler(clickService ClickService, statsService StatsService) *Handler {
+func NewHandler(clickService ClickService, statsService StatsService, bannerService BannerService,
+	servingService ServingService, tenantService TenantService, retentionService RetentionService,
+	cacheService CacheService) *Handler {
 	return &Handler{
-		clickService: clickService,
-		statsService: statsService,
+		clickService:     clickService,
+		statsService:     statsService,
+		bannerService:    bannerService,
+		servingService:   servingService,
+		tenantService:    tenantService,
+		retentionService: retentionService,
+		cacheService:     cacheService,
 	}
 }
 
 func (h *Handler) Register(server *grpc.Server) {
 	counter.RegisterCounterServiceServer(server, h.clickService)
 	stats.RegisterStatsServiceServer(server, h.statsService)
+	banner.RegisterBannerServiceServer(server, h.bannerService)
+	serving.RegisterServingServiceServer(server, h.servingService)
+	tenantpb.RegisterTenantServiceServer(server, h.tenantService)
+	// Not every storage backend has retention or a click cache.
+	if h.retentionService != nil {
+		retention.RegisterRetentionServiceServer(server, h.retentionService)
+	}
+	if h.cacheService != nil {
+		cache.RegisterCacheServiceServer(server, h.cacheService)
+	}
+}
+
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrUnauthenticated),
+		errors.Is(err, tenant.ErrNoTenant):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, usecase.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, repository.ErrBannerNotFound),
+		errors.Is(err, repository.ErrVariantNotFound),
+		errors.Is(err, repository.ErrTenantNotFound),
+		errors.Is(err, usecase.ErrNoBannerAvailable):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
 }
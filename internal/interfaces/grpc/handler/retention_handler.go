@@ -0,0 +1,33 @@
+package handler
+
+import (
+    "context"
+    "clicker/internal/application/dto"
+    "clicker/internal/application/usecase"
+    "clicker/pkg/retention"
+)
+
+type RetentionHandler struct {
+    retention.UnimplementedRetentionServiceServer
+    useCase usecase.RetentionUseCase
+}
+
+func NewRetentionHandler(useCase usecase.RetentionUseCase) *RetentionHandler {
+    return &RetentionHandler{useCase: useCase}
+}
+
+func (h *RetentionHandler) ListRetentionRuns(ctx context.Context, req *retention.ListRetentionRunsRequest) (*retention.ListRetentionRunsResponse, error) {
+    runs, err := h.useCase.ListRuns(ctx, int(req.Limit))
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    resp := &retention.ListRetentionRunsResponse{
+        Runs: make([]*retention.RetentionRun, 0, len(runs)),
+    }
+    for _, run := range runs {
+        resp.Runs = append(resp.Runs, dto.ToRetentionRunProto(run))
+    }
+
+    return resp, nil
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+    "context"
+    "clicker/internal/application/dto"
+    "clicker/internal/application/usecase"
+    "clicker/pkg/serving"
+)
+
+type ServingHandler struct {
+    serving.UnimplementedServingServiceServer
+    useCase usecase.ServingUseCase
+}
+
+func NewServingHandler(useCase usecase.ServingUseCase) *ServingHandler {
+    return &ServingHandler{useCase: useCase}
+}
+
+func (h *ServingHandler) ServeBanner(ctx context.Context, req *serving.ServeBannerRequest) (*serving.ServeBannerResponse, error) {
+    served, err := h.useCase.ServeBanner(ctx, req.Placement)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToServeBannerProtoResponse(served), nil
+}
@@ -26,8 +26,112 @@ func (h *StatsHandler) Stats(ctx context.Context, req *stats.StatsRequest) (*sta
 
     dtoResp, err := h.useCase.GetStats(ctx, dtoReq)
     if err != nil {
-        return nil, status.Error(codes.Internal, err.Error())
+        return nil, toStatusError(err)
     }
 
     return dto.ToProtoResponse(dtoResp), nil
 }
+
+func (h *StatsHandler) StatsByLabels(ctx context.Context, req *stats.LabelStatsRequest) (*stats.LabelStatsResponse, error) {
+    dtoReq := dto.LabelStatsRequestFromProto(req)
+    if dtoReq == nil {
+        return nil, status.Error(codes.InvalidArgument, "invalid request")
+    }
+
+    dtoResp, err := h.useCase.GetStatsByLabels(ctx, dtoReq)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToLabelStatsProtoResponse(dtoResp), nil
+}
+
+func (h *StatsHandler) CompareVariants(ctx context.Context, req *stats.CompareVariantsRequest) (*stats.CompareVariantsResponse, error) {
+    dtoReq := dto.CompareVariantsRequestFromProto(req)
+    if dtoReq == nil {
+        return nil, status.Error(codes.InvalidArgument, "invalid request")
+    }
+
+    dtoResp, err := h.useCase.CompareVariants(ctx, dtoReq)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToCompareVariantsProtoResponse(dtoResp), nil
+}
+
+func (h *StatsHandler) BatchStats(ctx context.Context, req *stats.BatchStatsRequest) (*stats.BatchStatsResponse, error) {
+    dtoReq := dto.BatchStatsRequestFromProto(req)
+    if dtoReq == nil {
+        return nil, status.Error(codes.InvalidArgument, "invalid request")
+    }
+
+    dtoResp, err := h.useCase.BatchStats(ctx, dtoReq)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToBatchStatsProtoResponse(dtoResp), nil
+}
+
+func (h *StatsHandler) TopBanners(ctx context.Context, req *stats.TopBannersRequest) (*stats.TopBannersResponse, error) {
+    dtoReq := dto.TopBannersRequestFromProto(req)
+    if dtoReq == nil {
+        return nil, status.Error(codes.InvalidArgument, "invalid request")
+    }
+
+    dtoResp, err := h.useCase.TopBanners(ctx, dtoReq)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToTopBannersProtoResponse(dtoResp), nil
+}
+
+func (h *StatsHandler) WatchStats(req *stats.WatchStatsRequest, stream stats.StatsService_WatchStatsServer) error {
+    dtoReq := dto.WatchStatsRequestFromProto(req)
+    if dtoReq == nil {
+        return status.Error(codes.InvalidArgument, "invalid request")
+    }
+
+    err := h.useCase.WatchStats(stream.Context(), dtoReq, func(update *dto.StatsUpdate) error {
+        return stream.Send(dto.ToStatsUpdateProto(update))
+    })
+    if err != nil {
+        return toStatusError(err)
+    }
+    return nil
+}
+
+func (h *StatsHandler) CreateWatchToken(ctx context.Context, req *stats.CreateWatchTokenRequest) (*stats.WatchToken, error) {
+    token, err := h.useCase.CreateWatchToken(ctx, dto.CreateWatchTokenRequestFromProto(req))
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToWatchTokenProto(token), nil
+}
+
+func (h *StatsHandler) ExportClicks(req *stats.ExportClicksRequest, stream stats.StatsService_ExportClicksServer) error {
+    dtoReq := dto.ExportClicksRequestFromProto(req)
+    if dtoReq == nil {
+        return status.Error(codes.InvalidArgument, "invalid request")
+    }
+
+    format := req.Format
+    if format == "" {
+        format = exportFormatCSV
+    }
+    enc := newRowEncoder(format, stream)
+    if enc == nil {
+        return status.Errorf(codes.InvalidArgument, "unknown export format %q", req.Format)
+    }
+
+    if err := h.useCase.ExportClicks(stream.Context(), dtoReq, enc.Encode); err != nil {
+        return toStatusError(err)
+    }
+    if err := enc.Flush(); err != nil {
+        return toStatusError(err)
+    }
+    return nil
+}
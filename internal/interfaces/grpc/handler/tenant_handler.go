@@ -0,0 +1,75 @@
+package handler
+
+import (
+    "context"
+    "clicker/internal/application/dto"
+    "clicker/internal/application/usecase"
+    "clicker/internal/domain/entity"
+    tenantpb "clicker/pkg/tenant"
+)
+
+type TenantHandler struct {
+    tenantpb.UnimplementedTenantServiceServer
+    useCase usecase.TenantUseCase
+}
+
+func NewTenantHandler(useCase usecase.TenantUseCase) *TenantHandler {
+    return &TenantHandler{useCase: useCase}
+}
+
+func (h *TenantHandler) CreateTenant(ctx context.Context, req *tenantpb.CreateTenantRequest) (*tenantpb.TenantCredentials, error) {
+    t, apiKey, err := h.useCase.CreateTenant(ctx, &entity.Tenant{
+        Name:           req.Name,
+        MaxBanners:     int(req.MaxBanners),
+        MaxDailyClicks: req.MaxDailyClicks,
+    })
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return &tenantpb.TenantCredentials{
+        Tenant: dto.ToTenantProto(t),
+        ApiKey: apiKey,
+    }, nil
+}
+
+func (h *TenantHandler) ListTenants(ctx context.Context, req *tenantpb.ListTenantsRequest) (*tenantpb.ListTenantsResponse, error) {
+    tenants, err := h.useCase.ListTenants(ctx)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    resp := &tenantpb.ListTenantsResponse{
+        Tenants: make([]*tenantpb.Tenant, 0, len(tenants)),
+    }
+    for _, t := range tenants {
+        resp.Tenants = append(resp.Tenants, dto.ToTenantProto(t))
+    }
+
+    return resp, nil
+}
+
+func (h *TenantHandler) UpdateTenantQuota(ctx context.Context, req *tenantpb.UpdateTenantQuotaRequest) (*tenantpb.Tenant, error) {
+    t, err := h.useCase.UpdateQuota(ctx, &entity.Tenant{
+        ID:             req.TenantId,
+        MaxBanners:     int(req.MaxBanners),
+        MaxDailyClicks: req.MaxDailyClicks,
+    })
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return dto.ToTenantProto(t), nil
+}
+
+func (h *TenantHandler) RotateTenantKey(ctx context.Context, req *tenantpb.RotateTenantKeyRequest) (*tenantpb.TenantCredentials, error) {
+    t, apiKey, err := h.useCase.RotateKey(ctx, req.TenantId)
+    if err != nil {
+        return nil, toStatusError(err)
+    }
+
+    return &tenantpb.TenantCredentials{
+        Tenant: dto.ToTenantProto(t),
+        ApiKey: apiKey,
+    }, nil
+}
@@ -0,0 +1,155 @@
+package interceptor
+
+import (
+    "context"
+    "crypto/subtle"
+    "errors"
+    "strings"
+    "unicode/utf8"
+
+    "clicker/internal/application/usecase"
+    "clicker/internal/domain/audit"
+    "clicker/internal/domain/tenant"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+)
+
+// adminServicePrefixes are the services behind the admin token.
+var adminServicePrefixes = []string{
+    "/clicker.TenantService/",
+    "/clicker.RetentionService/",
+    "/clicker.CacheService/",
+}
+
+// actorMetadataKey lets a client name the user behind a tenant API key in
+// the banner change history. grpc-gateway fills it from the
+// Grpc-Metadata-X-Actor HTTP header.
+const actorMetadataKey = "x-actor"
+
+const maxActorLength = 255
+
+type AuthInterceptor struct {
+    tenants    usecase.TenantUseCase
+    adminToken string
+}
+
+func NewAuthInterceptor(tenants usecase.TenantUseCase, adminToken string) *AuthInterceptor {
+    return &AuthInterceptor{
+        tenants:    tenants,
+        adminToken: adminToken,
+    }
+}
+
+func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+        ctx, err := a.authorize(ctx, info.FullMethod)
+        if err != nil {
+            return nil, err
+        }
+        return handler(ctx, req)
+    }
+}
+
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+    return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        ctx, err := a.authorize(ss.Context(), info.FullMethod)
+        if err != nil {
+            return err
+        }
+        return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+    }
+}
+
+// authorizedStream hands the context with the resolved tenant to stream
+// handlers.
+type authorizedStream struct {
+    grpc.ServerStream
+    ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+    return s.ctx
+}
+
+// authorize checks the admin token for the admin API and otherwise resolves
+// the tenant from the API key and puts it into the context.
+func (a *AuthInterceptor) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+    token := BearerToken(ctx)
+
+    if isAdminMethod(fullMethod) {
+        if a.adminToken == "" {
+            return nil, status.Error(codes.PermissionDenied, "admin API is disabled")
+        }
+        if subtle.ConstantTimeCompare([]byte(token), []byte(a.adminToken)) != 1 {
+            return nil, status.Error(codes.PermissionDenied, "invalid admin token")
+        }
+        return ctx, nil
+    }
+
+    t, err := a.tenants.Authenticate(ctx, token)
+    if errors.Is(err, usecase.ErrUnauthenticated) {
+        return nil, status.Error(codes.Unauthenticated, err.Error())
+    }
+    if err != nil {
+        return nil, status.Error(codes.Internal, err.Error())
+    }
+
+    ctx = tenant.NewContext(ctx, t)
+    return audit.NewContext(ctx, actor(ctx, t.Name)), nil
+}
+
+func isAdminMethod(fullMethod string) bool {
+    for _, prefix := range adminServicePrefixes {
+        if strings.HasPrefix(fullMethod, prefix) {
+            return true
+        }
+    }
+    return false
+}
+
+// actor is the tenant name, qualified by the x-actor metadata if the
+// client has sent one.
+func actor(ctx context.Context, tenantName string) string {
+    md, ok := metadata.FromIncomingContext(ctx)
+    if !ok {
+        return tenantName
+    }
+
+    values := md.Get(actorMetadataKey)
+    if len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+        return tenantName
+    }
+    // Заголовок может прийти в любой кодировке, а Postgres примет только
+    // UTF-8, поэтому и обрезается строка по границе символа.
+    name := strings.ToValidUTF8(tenantName+"/"+strings.TrimSpace(values[0]), "")
+    if len(name) > maxActorLength {
+        cut := maxActorLength
+        for cut > 0 && !utf8.RuneStart(name[cut]) {
+            cut--
+        }
+        name = name[:cut]
+    }
+    return name
+}
+
+// BearerToken extracts the credentials from the "authorization: Bearer <key>"
+// metadata, which grpc-gateway fills from the HTTP Authorization header.
+func BearerToken(ctx context.Context) string {
+    md, ok := metadata.FromIncomingContext(ctx)
+    if !ok {
+        return ""
+    }
+
+    values := md.Get("authorization")
+    if len(values) == 0 {
+        return ""
+    }
+
+    token, found := strings.CutPrefix(values[0], "Bearer ")
+    if !found {
+        return ""
+    }
+    return strings.TrimSpace(token)
+}
@@ -0,0 +1,84 @@
+package redirect
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "log"
+    "net/http"
+    "time"
+
+    "clicker/internal/application/dto"
+    "clicker/internal/application/usecase"
+    "clicker/internal/domain/repository"
+    "github.com/gorilla/mux"
+)
+
+// clickerCookie holds a random ID of the browser for unique clicker counts.
+const clickerCookie = "clicker_id"
+
+const clickerCookieMaxAge = 365 * 24 * time.Hour
+
+// ClickHandler counts a click of a tracking link handed out by ServeBanner
+// and sends the browser on to the landing page of the banner:
+//
+//     GET /click/{token}
+//
+// The token is signed and names the tenant, banner and variant, so no API
+// key is needed.
+type ClickHandler struct {
+    clicks usecase.ClickUseCase
+}
+
+func NewClickHandler(clicks usecase.ClickUseCase) *ClickHandler {
+    return &ClickHandler{clicks: clicks}
+}
+
+func (h *ClickHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    target, err := h.clicks.Follow(r.Context(), &dto.FollowRequest{
+        Token:     mux.Vars(r)["token"],
+        ClickerID: clickerID(w, r),
+    })
+    switch {
+    case errors.Is(err, usecase.ErrInvalidClickLink),
+        errors.Is(err, repository.ErrBannerNotFound):
+        http.Error(w, "unknown click link", http.StatusNotFound)
+        return
+    case err != nil:
+        log.Printf("Failed to follow click link: %v", err)
+        http.Error(w, "internal error", http.StatusInternalServerError)
+        return
+    }
+
+    // Каждый переход должен дойти до нас, а не до кэша браузера.
+    w.Header().Set("Cache-Control", "no-store")
+    if target == "" {
+        w.WriteHeader(http.StatusNoContent)
+        return
+    }
+    http.Redirect(w, r, target, http.StatusFound)
+}
+
+// clickerID returns the ID of the browser from its cookie and sets a new
+// one if it has none.
+func clickerID(w http.ResponseWriter, r *http.Request) string {
+    if c, err := r.Cookie(clickerCookie); err == nil && c.Value != "" {
+        return c.Value
+    }
+
+    b := make([]byte, 16)
+    if _, err := rand.Read(b); err != nil {
+        return ""
+    }
+    id := hex.EncodeToString(b)
+    http.SetCookie(w, &http.Cookie{
+        Name:     clickerCookie,
+        Value:    id,
+        Path:     "/click/",
+        MaxAge:   int(clickerCookieMaxAge / time.Second),
+        HttpOnly: true,
+        Secure:   r.TLS != nil,
+        SameSite: http.SameSiteLaxMode,
+    })
+    return id
+}
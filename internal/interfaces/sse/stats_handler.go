@@ -0,0 +1,217 @@
+package sse
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "clicker/internal/application/dto"
+    "clicker/internal/application/usecase"
+    "clicker/internal/domain/entity"
+    "clicker/internal/domain/repository"
+    "clicker/internal/domain/tenant"
+)
+
+// heartbeatInterval keeps proxies from closing an idle stream.
+const heartbeatInterval = 15 * time.Second
+
+// StatsHandler serves WatchStats as server-sent events for browsers, which
+// can't speak gRPC streaming:
+//
+//     GET /stats/watch?banner_id=1&banner_id=2&ts_from=1700000000
+//
+// EventSource can't set headers, so besides "Authorization: Bearer <key>"
+// the stream opens with a token from CreateWatchToken in the token query
+// parameter, which watches the banners it was issued for:
+//
+//     GET /stats/watch?token=<token>&ts_from=1700000000
+//
+// The API key itself is never accepted in the query, where proxies and
+// access logs would record it.
+type StatsHandler struct {
+    stats   usecase.StatsUseCase
+    tenants usecase.TenantUseCase
+    watch   *usecase.WatchTokens
+}
+
+func NewStatsHandler(stats usecase.StatsUseCase, tenants usecase.TenantUseCase,
+    watch *usecase.WatchTokens) *StatsHandler {
+    return &StatsHandler{
+        stats:   stats,
+        tenants: tenants,
+        watch:   watch,
+    }
+}
+
+func (h *StatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    flusher, ok := w.(http.Flusher)
+    if !ok {
+        http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+        return
+    }
+
+    req, err := watchRequest(r)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    t, err := h.authenticate(r, req)
+    if errors.Is(err, usecase.ErrUnauthenticated) {
+        http.Error(w, err.Error(), http.StatusUnauthorized)
+        return
+    }
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    ctx, cancel := context.WithCancel(tenant.NewContext(r.Context(), t))
+    defer cancel()
+
+    stream := &eventStream{w: w, flusher: flusher}
+    defer stream.close()
+    go stream.heartbeat(ctx)
+
+    err = h.stats.WatchStats(ctx, req, func(update *dto.StatsUpdate) error {
+        event := "update"
+        if update.Snapshot {
+            event = "snapshot"
+        }
+        return stream.send(event, update)
+    })
+    if err == nil {
+        return
+    }
+    // Once the stream has started only the handler goroutine writes
+    // started, so it can be read without the lock.
+    if stream.started {
+        log.Printf("Stats stream of tenant %d ended: %v", t.ID, err)
+        return
+    }
+    if errors.Is(err, usecase.ErrInvalidArgument) {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+    http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// eventStream serializes writes of events and heartbeats to the response.
+type eventStream struct {
+    mu      sync.Mutex
+    w       http.ResponseWriter
+    flusher http.Flusher
+    started bool
+    closed  bool
+}
+
+// close stops heartbeats, the response must not be written to after the
+// handler has returned.
+func (s *eventStream) close() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.closed = true
+}
+
+func (s *eventStream) send(event string, data any) error {
+    payload, err := json.Marshal(data)
+    if err != nil {
+        return err
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    // Заголовки пишутся с первым событием, чтобы до него ошибки запроса
+    // ещё можно было вернуть обычным статусом.
+    if !s.started {
+        s.started = true
+        s.w.Header().Set("Content-Type", "text/event-stream")
+        s.w.Header().Set("Cache-Control", "no-cache")
+        s.w.Header().Set("X-Accel-Buffering", "no")
+        s.w.WriteHeader(http.StatusOK)
+    }
+
+    if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+        return err
+    }
+    s.flusher.Flush()
+    return nil
+}
+
+func (s *eventStream) heartbeat(ctx context.Context) {
+    ticker := time.NewTicker(heartbeatInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            s.mu.Lock()
+            if s.started && !s.closed {
+                fmt.Fprint(s.w, ": ping\n\n")
+                s.flusher.Flush()
+            }
+            s.mu.Unlock()
+        }
+    }
+}
+
+// authenticate resolves the tenant from the API key in the Authorization
+// header or from a watch token in the query. A token also decides which
+// banners are watched.
+func (h *StatsHandler) authenticate(r *http.Request, req *dto.WatchStatsRequest) (*entity.Tenant, error) {
+    if key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+        return h.tenants.Authenticate(r.Context(), strings.TrimSpace(key))
+    }
+
+    token := r.URL.Query().Get("token")
+    if token == "" {
+        return nil, fmt.Errorf("%w: api key or watch token is required", usecase.ErrUnauthenticated)
+    }
+    tenantID, bannerIDs, err := h.watch.Parse(token, time.Now())
+    if err != nil {
+        return nil, err
+    }
+    req.BannerIDs = bannerIDs
+
+    // Тенант токена мог быть удалён после выдачи: это 401, как и
+    // неизвестный ключ, а не ошибка сервера.
+    t, err := h.tenants.GetTenant(r.Context(), tenantID)
+    if errors.Is(err, repository.ErrTenantNotFound) {
+        return nil, fmt.Errorf("%w: unknown tenant", usecase.ErrUnauthenticated)
+    }
+    return t, err
+}
+
+func watchRequest(r *http.Request) (*dto.WatchStatsRequest, error) {
+    query := r.URL.Query()
+    req := &dto.WatchStatsRequest{}
+
+    for _, value := range query["banner_id"] {
+        id, err := strconv.ParseInt(value, 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid banner_id %q", value)
+        }
+        req.BannerIDs = append(req.BannerIDs, id)
+    }
+
+    if value := query.Get("ts_from"); value != "" {
+        ts, err := strconv.ParseInt(value, 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid ts_from %q", value)
+        }
+        req.TsFrom = ts
+    }
+
+    return req, nil
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS banner_labels CASCADE;
+CREATE TABLE banner_labels (
+    banner_id INTEGER NOT NULL,
+    key VARCHAR(63) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (banner_id, key),
+    CONSTRAINT fk_banner
+        FOREIGN KEY (banner_id)
+        REFERENCES banners(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_banner_labels_key_value ON banner_labels(key, value);
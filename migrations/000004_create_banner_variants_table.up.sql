@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS banner_variants CASCADE;
+CREATE TABLE banner_variants (
+    id SERIAL PRIMARY KEY,
+    banner_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    creative_url TEXT NOT NULL DEFAULT '',
+    weight INTEGER NOT NULL DEFAULT 1 CHECK (weight >= 0),
+    CONSTRAINT fk_banner
+        FOREIGN KEY (banner_id)
+        REFERENCES banners(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_banner_variants_banner ON banner_variants(banner_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_clicks_banner_variant_timestamp;
+ALTER TABLE clicks DROP COLUMN IF EXISTS variant_id;
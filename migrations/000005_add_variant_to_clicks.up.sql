@@ -0,0 +1,3 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS variant_id INTEGER;
+
+CREATE INDEX idx_clicks_banner_variant_timestamp ON clicks(banner_id, variant_id, timestamp);
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS impressions CASCADE;
+CREATE TABLE impressions (
+    id SERIAL PRIMARY KEY,
+    banner_id INTEGER NOT NULL,
+    variant_id INTEGER,
+    timestamp TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    count INTEGER DEFAULT 1,
+    CONSTRAINT fk_banner
+        FOREIGN KEY (banner_id)
+        REFERENCES banners(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_impressions_banner_variant_timestamp ON impressions(banner_id, variant_id, timestamp);
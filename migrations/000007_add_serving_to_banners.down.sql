@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_banners_placement_status;
+ALTER TABLE banners
+    DROP COLUMN IF EXISTS url,
+    DROP COLUMN IF EXISTS creative_url,
+    DROP COLUMN IF EXISTS placement,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS weight,
+    DROP COLUMN IF EXISTS daily_impression_cap;
@@ -0,0 +1,11 @@
+ALTER TABLE banners
+    ADD COLUMN IF NOT EXISTS url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS creative_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS placement VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'paused')),
+    ADD COLUMN IF NOT EXISTS weight INTEGER NOT NULL DEFAULT 1 CHECK (weight >= 0),
+    ADD COLUMN IF NOT EXISTS daily_impression_cap INTEGER NOT NULL DEFAULT 0
+        CHECK (daily_impression_cap >= 0);
+
+CREATE INDEX idx_banners_placement_status ON banners(placement, status);
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS tenants CASCADE;
+CREATE TABLE tenants (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    api_key_hash CHAR(64) NOT NULL UNIQUE,
+    max_banners INTEGER NOT NULL DEFAULT 0 CHECK (max_banners >= 0),
+    max_daily_clicks BIGINT NOT NULL DEFAULT 0 CHECK (max_daily_clicks >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Существующие данные переходят к тенанту по умолчанию. Ключ случайный,
+-- его нужно выпустить заново через RotateTenantKey.
+INSERT INTO tenants (id, name, api_key_hash)
+VALUES (1, 'default', encode(sha256(gen_random_uuid()::text::bytea), 'hex'));
+
+SELECT setval('tenants_id_seq', (SELECT MAX(id) FROM tenants));
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_clicks_tenant_timestamp;
+DROP INDEX IF EXISTS idx_banners_tenant_placement_status;
+CREATE INDEX idx_banners_placement_status ON banners(placement, status);
+
+ALTER TABLE impressions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE clicks DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE banners DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,15 @@
+ALTER TABLE banners ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1
+    REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE banners ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1
+    REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE clicks ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE impressions ADD COLUMN IF NOT EXISTS tenant_id INTEGER NOT NULL DEFAULT 1
+    REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE impressions ALTER COLUMN tenant_id DROP DEFAULT;
+
+DROP INDEX IF EXISTS idx_banners_placement_status;
+CREATE INDEX idx_banners_tenant_placement_status ON banners(tenant_id, placement, status);
+CREATE INDEX idx_clicks_tenant_timestamp ON clicks(tenant_id, timestamp);
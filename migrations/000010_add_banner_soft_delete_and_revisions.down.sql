@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS banner_revisions CASCADE;
+
+ALTER TABLE impressions DROP CONSTRAINT IF EXISTS fk_banner;
+ALTER TABLE impressions ADD CONSTRAINT fk_banner
+    FOREIGN KEY (banner_id) REFERENCES banners(id) ON DELETE CASCADE;
+
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS fk_banner;
+ALTER TABLE clicks ADD CONSTRAINT fk_banner
+    FOREIGN KEY (banner_id) REFERENCES banners(id) ON DELETE CASCADE;
+
+ALTER TABLE banners DROP COLUMN IF EXISTS deleted_at;
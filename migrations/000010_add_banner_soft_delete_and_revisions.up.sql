@@ -0,0 +1,35 @@
+ALTER TABLE banners ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Клики и показы больше не удаляются вместе с баннером: баннеры удаляются
+-- мягко, а жёсткое удаление баннера с историей должно упасть.
+ALTER TABLE clicks DROP CONSTRAINT IF EXISTS fk_banner;
+ALTER TABLE clicks ADD CONSTRAINT fk_banner
+    FOREIGN KEY (banner_id) REFERENCES banners(id);
+
+ALTER TABLE impressions DROP CONSTRAINT IF EXISTS fk_banner;
+ALTER TABLE impressions ADD CONSTRAINT fk_banner
+    FOREIGN KEY (banner_id) REFERENCES banners(id);
+
+DROP TABLE IF EXISTS banner_revisions CASCADE;
+CREATE TABLE banner_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    banner_id INTEGER NOT NULL REFERENCES banners(id),
+    variant_id INTEGER,
+    action VARCHAR(32) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_banner_revisions_banner_created ON banner_revisions(banner_id, created_at);
+
+-- У существующих баннеров истории нет, начинаем её с текущего состояния.
+INSERT INTO banner_revisions (tenant_id, banner_id, action, actor, changes)
+SELECT tenant_id, id, 'create', 'migration', jsonb_build_array(
+    jsonb_build_object('field', 'name', 'old', '', 'new', name),
+    jsonb_build_object('field', 'url', 'old', '', 'new', url),
+    jsonb_build_object('field', 'status', 'old', '', 'new', status),
+    jsonb_build_object('field', 'weight', 'old', '', 'new', weight::text)
+)
+FROM banners;
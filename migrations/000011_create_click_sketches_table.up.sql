@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS click_sketches CASCADE;
+-- Почасовые HyperLogLog-скетчи уникальных кликеров в формате Redis (dense).
+-- Нули в скетче хорошо жмутся TOAST'ом, так что малые скетчи дёшевы.
+CREATE TABLE click_sketches (
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    banner_id INTEGER NOT NULL REFERENCES banners(id),
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    sketch BYTEA NOT NULL,
+    PRIMARY KEY (banner_id, bucket)
+);
+
+CREATE INDEX idx_click_sketches_tenant_bucket ON click_sketches(tenant_id, bucket);
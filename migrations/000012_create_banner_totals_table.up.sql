@@ -0,0 +1,19 @@
+-- Клики баннера за всё время, чтобы счётчик не суммировал всю историю.
+-- Обновляется в той же пачке, что и вставка кликов.
+BEGIN;
+
+CREATE TABLE banner_totals (
+    banner_id INTEGER PRIMARY KEY REFERENCES banners(id),
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    clicks BIGINT NOT NULL DEFAULT 0
+);
+
+-- Не даём вставлять клики, пока считаем уже имеющиеся.
+LOCK TABLE clicks IN SHARE MODE;
+
+INSERT INTO banner_totals (tenant_id, banner_id, clicks)
+SELECT tenant_id, banner_id, SUM(count)
+FROM clicks
+GROUP BY tenant_id, banner_id;
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+ALTER TABLE clicks RENAME TO clicks_partitioned;
+ALTER INDEX clicks_pkey RENAME TO clicks_partitioned_pkey;
+DROP INDEX IF EXISTS idx_clicks_banner_timestamp;
+DROP INDEX IF EXISTS idx_clicks_banner_variant_timestamp;
+DROP INDEX IF EXISTS idx_clicks_tenant_timestamp;
+
+ALTER SEQUENCE clicks_id_seq OWNED BY NONE;
+
+CREATE TABLE clicks (
+    id INTEGER PRIMARY KEY DEFAULT nextval('clicks_id_seq'),
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    banner_id INTEGER NOT NULL,
+    variant_id INTEGER,
+    timestamp TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    count INTEGER DEFAULT 1,
+    CONSTRAINT fk_banner
+        FOREIGN KEY (banner_id)
+        REFERENCES banners(id)
+);
+
+INSERT INTO clicks (id, tenant_id, banner_id, variant_id, timestamp, count)
+SELECT id, tenant_id, banner_id, variant_id, timestamp, count
+FROM clicks_partitioned;
+
+DROP TABLE clicks_partitioned;
+
+ALTER SEQUENCE clicks_id_seq AS INTEGER;
+ALTER SEQUENCE clicks_id_seq OWNED BY clicks.id;
+
+CREATE INDEX idx_clicks_banner_timestamp ON clicks(banner_id, timestamp);
+CREATE INDEX idx_clicks_banner_variant_timestamp ON clicks(banner_id, variant_id, timestamp);
+CREATE INDEX idx_clicks_tenant_timestamp ON clicks(tenant_id, timestamp);
+
+COMMIT;
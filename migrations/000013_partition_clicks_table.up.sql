@@ -0,0 +1,67 @@
+-- clicks становится секционированной по timestamp таблицей, по секции на
+-- месяц (UTC). Дальше секции заводит и удаляет само приложение, см.
+-- CLICKS_PARTITION_*. Миграция переписывает всю таблицу, её стоит
+-- запускать в окно обслуживания.
+BEGIN;
+
+ALTER TABLE clicks RENAME TO clicks_legacy;
+ALTER INDEX clicks_pkey RENAME TO clicks_legacy_pkey;
+DROP INDEX IF EXISTS idx_clicks_banner_timestamp;
+DROP INDEX IF EXISTS idx_clicks_banner_variant_timestamp;
+DROP INDEX IF EXISTS idx_clicks_tenant_timestamp;
+
+-- Последовательность переживёт старую таблицу, id продолжаются.
+ALTER SEQUENCE clicks_id_seq OWNED BY NONE;
+ALTER SEQUENCE clicks_id_seq AS BIGINT;
+
+-- Ключ секционирования обязан входить в первичный ключ.
+CREATE TABLE clicks (
+    id BIGINT NOT NULL DEFAULT nextval('clicks_id_seq'),
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    banner_id INTEGER NOT NULL,
+    variant_id INTEGER,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    count INTEGER DEFAULT 1,
+    PRIMARY KEY (id, timestamp),
+    CONSTRAINT fk_banner
+        FOREIGN KEY (banner_id)
+        REFERENCES banners(id)
+) PARTITION BY RANGE (timestamp);
+
+ALTER SEQUENCE clicks_id_seq OWNED BY clicks.id;
+
+-- Секции с месяца самого старого клика и на два месяца вперёд.
+DO $$
+DECLARE
+    month_start TIMESTAMP;
+BEGIN
+    FOR month_start IN
+        SELECT generate_series(
+            date_trunc('month', COALESCE(MIN(timestamp), now()) AT TIME ZONE 'UTC'),
+            date_trunc('month', now() AT TIME ZONE 'UTC') + interval '2 months',
+            interval '1 month'
+        )
+        FROM clicks_legacy
+    LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF clicks FOR VALUES FROM (%L) TO (%L)',
+            'clicks_p' || to_char(month_start, 'YYYYMM'),
+            month_start AT TIME ZONE 'UTC',
+            (month_start + interval '1 month') AT TIME ZONE 'UTC'
+        );
+    END LOOP;
+END
+$$;
+
+INSERT INTO clicks (id, tenant_id, banner_id, variant_id, timestamp, count)
+SELECT id, tenant_id, banner_id, variant_id, COALESCE(timestamp, now()), count
+FROM clicks_legacy;
+
+DROP TABLE clicks_legacy;
+
+-- Индексы на родителе создаются в каждой секции.
+CREATE INDEX idx_clicks_banner_timestamp ON clicks(banner_id, timestamp);
+CREATE INDEX idx_clicks_banner_variant_timestamp ON clicks(banner_id, variant_id, timestamp);
+CREATE INDEX idx_clicks_tenant_timestamp ON clicks(tenant_id, timestamp);
+
+COMMIT;
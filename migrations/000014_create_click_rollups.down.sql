@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS rollup_watermarks CASCADE;
+DROP TABLE IF EXISTS clicks_daily CASCADE;
+DROP TABLE IF EXISTS clicks_hourly CASCADE;
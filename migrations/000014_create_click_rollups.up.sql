@@ -0,0 +1,33 @@
+-- Почасовые и посуточные (UTC) агрегаты кликов. Их пополняет фоновая
+-- задача, а rollup_watermarks хранит, до какого момента агрегаты полны.
+CREATE TABLE clicks_hourly (
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    banner_id INTEGER NOT NULL REFERENCES banners(id),
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (banner_id, bucket)
+);
+
+CREATE INDEX idx_clicks_hourly_tenant_bucket ON clicks_hourly(tenant_id, bucket);
+
+CREATE TABLE clicks_daily (
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    banner_id INTEGER NOT NULL REFERENCES banners(id),
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (banner_id, bucket)
+);
+
+CREATE INDEX idx_clicks_daily_tenant_bucket ON clicks_daily(tenant_id, bucket);
+
+CREATE TABLE rollup_watermarks (
+    name VARCHAR(32) PRIMARY KEY,
+    watermark TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Агрегаты строятся с самого старого клика.
+INSERT INTO rollup_watermarks (name, watermark)
+SELECT 'hourly', date_trunc('hour', COALESCE(MIN(timestamp), now()), 'UTC') FROM clicks
+UNION ALL
+SELECT 'daily', date_trunc('day', COALESCE(MIN(timestamp), now()), 'UTC') FROM clicks;
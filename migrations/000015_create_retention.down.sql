@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS retention_runs CASCADE;
+DROP TABLE IF EXISTS retention_horizons CASCADE;
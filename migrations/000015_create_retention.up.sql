@@ -0,0 +1,21 @@
+-- Откуда начинаются данные уровня после очистки: raw для clicks,
+-- hourly для clicks_hourly. Нет строки - уровень хранится целиком.
+CREATE TABLE retention_horizons (
+    tier VARCHAR(32) PRIMARY KEY,
+    horizon TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE retention_runs (
+    id BIGSERIAL PRIMARY KEY,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    raw_horizon TIMESTAMP WITH TIME ZONE,
+    raw_deleted BIGINT NOT NULL DEFAULT 0,
+    partitions_dropped INTEGER NOT NULL DEFAULT 0,
+    hourly_horizon TIMESTAMP WITH TIME ZONE,
+    hourly_deleted BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_retention_runs_started_at ON retention_runs(started_at);
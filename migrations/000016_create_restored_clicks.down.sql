@@ -0,0 +1 @@
+DROP TABLE IF EXISTS restored_clicks CASCADE;
@@ -0,0 +1,14 @@
+-- Сюда clicker restore возвращает клики из архива. Отдельно от clicks,
+-- чтобы восстановленное не попало в статистику и не удалялось retention.
+CREATE TABLE restored_clicks (
+    id BIGINT NOT NULL,
+    tenant_id INTEGER NOT NULL,
+    banner_id INTEGER NOT NULL,
+    variant_id INTEGER,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    count INTEGER NOT NULL,
+    restored_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_restored_clicks_timestamp ON restored_clicks(timestamp);
+CREATE INDEX idx_restored_clicks_tenant_banner_timestamp ON restored_clicks(tenant_id, banner_id, timestamp);
@@ -0,0 +1,5 @@
+ALTER TABLE banners DROP CONSTRAINT IF EXISTS banners_weight_check;
+ALTER TABLE banners ADD CONSTRAINT banners_weight_check CHECK (weight >= 0);
+
+ALTER TABLE banner_variants DROP CONSTRAINT IF EXISTS banner_variants_weight_check;
+ALTER TABLE banner_variants ADD CONSTRAINT banner_variants_weight_check CHECK (weight >= 0);
@@ -0,0 +1,14 @@
+-- Вес 0 никогда не выпадает при выборе, такие баннеры и варианты создавались
+-- без веса по ошибке. Остановить показ можно паузой.
+BEGIN;
+
+UPDATE banners SET weight = 1 WHERE weight = 0;
+UPDATE banner_variants SET weight = 1 WHERE weight = 0;
+
+ALTER TABLE banners DROP CONSTRAINT IF EXISTS banners_weight_check;
+ALTER TABLE banners ADD CONSTRAINT banners_weight_check CHECK (weight > 0);
+
+ALTER TABLE banner_variants DROP CONSTRAINT IF EXISTS banner_variants_weight_check;
+ALTER TABLE banner_variants ADD CONSTRAINT banner_variants_weight_check CHECK (weight > 0);
+
+COMMIT;
@@ -0,0 +1,2 @@
+-- Клики, так и не перенесённые в секции, возвращать некуда, они теряются.
+DROP TABLE IF EXISTS clicks_default;
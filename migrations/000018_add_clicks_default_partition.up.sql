@@ -0,0 +1,5 @@
+-- Клик за пределами заведённых секций (часы клиента ушли вперёд, поздняя
+-- запись в уже удалённый диапазон) без DEFAULT-секции валит вставку всей
+-- пачки. Такие клики ждут здесь, пока обслуживание секций не перенесёт их
+-- в секцию их периода.
+CREATE TABLE IF NOT EXISTS clicks_default PARTITION OF clicks DEFAULT;
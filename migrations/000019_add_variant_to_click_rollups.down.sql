@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TEMP TABLE clicks_hourly_totals ON COMMIT DROP AS
+SELECT tenant_id, banner_id, bucket, SUM(clicks) AS clicks FROM clicks_hourly GROUP BY 1, 2, 3;
+DELETE FROM clicks_hourly;
+ALTER TABLE clicks_hourly DROP CONSTRAINT clicks_hourly_pkey;
+ALTER TABLE clicks_hourly DROP COLUMN variant_id;
+ALTER TABLE clicks_hourly ADD PRIMARY KEY (banner_id, bucket);
+INSERT INTO clicks_hourly (tenant_id, banner_id, bucket, clicks) SELECT * FROM clicks_hourly_totals;
+
+CREATE TEMP TABLE clicks_daily_totals ON COMMIT DROP AS
+SELECT tenant_id, banner_id, bucket, SUM(clicks) AS clicks FROM clicks_daily GROUP BY 1, 2, 3;
+DELETE FROM clicks_daily;
+ALTER TABLE clicks_daily DROP CONSTRAINT clicks_daily_pkey;
+ALTER TABLE clicks_daily DROP COLUMN variant_id;
+ALTER TABLE clicks_daily ADD PRIMARY KEY (banner_id, bucket);
+INSERT INTO clicks_daily (tenant_id, banner_id, bucket, clicks) SELECT * FROM clicks_daily_totals;
+
+COMMIT;
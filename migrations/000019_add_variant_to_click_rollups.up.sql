@@ -0,0 +1,56 @@
+-- Агрегаты кликов по вариантам, чтобы статистика вариантов переживала
+-- retention сырых кликов. Клики без варианта идут с variant_id = 0.
+BEGIN;
+
+ALTER TABLE clicks_hourly ADD COLUMN variant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE clicks_hourly DROP CONSTRAINT clicks_hourly_pkey;
+ALTER TABLE clicks_hourly ADD PRIMARY KEY (banner_id, variant_id, bucket);
+
+ALTER TABLE clicks_daily ADD COLUMN variant_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE clicks_daily DROP CONSTRAINT clicks_daily_pkey;
+ALTER TABLE clicks_daily ADD PRIMARY KEY (banner_id, variant_id, bucket);
+
+-- Уже собранное пересобираем по вариантам там, где сырые клики ещё есть.
+-- Старше остаётся как было, под variant_id = 0: суммы по баннеру верны,
+-- разбивки по вариантам для тех часов уже не восстановить.
+DO $$
+DECLARE
+    since TIMESTAMP WITH TIME ZONE;
+    hourly_watermark TIMESTAMP WITH TIME ZONE;
+    daily_watermark TIMESTAMP WITH TIME ZONE;
+    days_since TIMESTAMP WITH TIME ZONE;
+BEGIN
+    SELECT GREATEST(
+        (SELECT horizon FROM retention_horizons WHERE tier = 'raw'),
+        (SELECT date_trunc('hour', MIN(timestamp), 'UTC') FROM clicks)
+    ) INTO since;
+    SELECT watermark INTO hourly_watermark FROM rollup_watermarks WHERE name = 'hourly';
+    SELECT watermark INTO daily_watermark FROM rollup_watermarks WHERE name = 'daily';
+    IF since IS NULL THEN
+        RETURN;
+    END IF;
+    -- Сутки собираются из часов, часы до горизонта hourly уже удалены.
+    SELECT GREATEST(
+        date_trunc('day', since, 'UTC'),
+        (SELECT horizon FROM retention_horizons WHERE tier = 'hourly')
+    ) INTO days_since;
+
+    DELETE FROM clicks_hourly WHERE bucket >= since AND bucket < hourly_watermark;
+    INSERT INTO clicks_hourly (tenant_id, banner_id, variant_id, bucket, clicks)
+    SELECT tenant_id, banner_id, COALESCE(variant_id, 0), date_trunc('hour', timestamp, 'UTC') AS bucket, SUM(count)
+    FROM clicks
+    WHERE timestamp >= since
+    AND timestamp < hourly_watermark
+    GROUP BY tenant_id, banner_id, COALESCE(variant_id, 0), bucket;
+
+    DELETE FROM clicks_daily WHERE bucket >= days_since AND bucket < daily_watermark;
+    INSERT INTO clicks_daily (tenant_id, banner_id, variant_id, bucket, clicks)
+    SELECT tenant_id, banner_id, variant_id, date_trunc('day', bucket, 'UTC') AS day, SUM(clicks)
+    FROM clicks_hourly
+    WHERE bucket >= days_since
+    AND bucket < daily_watermark
+    GROUP BY tenant_id, banner_id, variant_id, day;
+END
+$$;
+
+COMMIT;
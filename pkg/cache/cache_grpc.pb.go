@@ -0,0 +1,152 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.27.1
+// source: cache.proto
+
+package cache
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CacheService_RebuildCache_FullMethodName    = "/clicker.CacheService/RebuildCache"
+	CacheService_GetCacheRebuild_FullMethodName = "/clicker.CacheService/GetCacheRebuild"
+)
+
+// CacheServiceClient is the client API for CacheService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CacheServiceClient interface {
+	// RebuildCache starts rebuilding the last 24 hours of clicks in Redis
+	// from Postgres in the background. If a rebuild is already running, it
+	// returns that one instead.
+	RebuildCache(ctx context.Context, in *RebuildCacheRequest, opts ...grpc.CallOption) (*CacheRebuild, error)
+	GetCacheRebuild(ctx context.Context, in *GetCacheRebuildRequest, opts ...grpc.CallOption) (*CacheRebuild, error)
+}
+
+type cacheServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCacheServiceClient(cc grpc.ClientConnInterface) CacheServiceClient {
+	return &cacheServiceClient{cc}
+}
+
+func (c *cacheServiceClient) RebuildCache(ctx context.Context, in *RebuildCacheRequest, opts ...grpc.CallOption) (*CacheRebuild, error) {
+	out := new(CacheRebuild)
+	err := c.cc.Invoke(ctx, CacheService_RebuildCache_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) GetCacheRebuild(ctx context.Context, in *GetCacheRebuildRequest, opts ...grpc.CallOption) (*CacheRebuild, error) {
+	out := new(CacheRebuild)
+	err := c.cc.Invoke(ctx, CacheService_GetCacheRebuild_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CacheServiceServer is the server API for CacheService service.
+// All implementations must embed UnimplementedCacheServiceServer
+// for forward compatibility
+type CacheServiceServer interface {
+	// RebuildCache starts rebuilding the last 24 hours of clicks in Redis
+	// from Postgres in the background. If a rebuild is already running, it
+	// returns that one instead.
+	RebuildCache(context.Context, *RebuildCacheRequest) (*CacheRebuild, error)
+	GetCacheRebuild(context.Context, *GetCacheRebuildRequest) (*CacheRebuild, error)
+	mustEmbedUnimplementedCacheServiceServer()
+}
+
+// UnimplementedCacheServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCacheServiceServer struct {
+}
+
+func (UnimplementedCacheServiceServer) RebuildCache(context.Context, *RebuildCacheRequest) (*CacheRebuild, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RebuildCache not implemented")
+}
+func (UnimplementedCacheServiceServer) GetCacheRebuild(context.Context, *GetCacheRebuildRequest) (*CacheRebuild, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCacheRebuild not implemented")
+}
+func (UnimplementedCacheServiceServer) mustEmbedUnimplementedCacheServiceServer() {}
+
+// UnsafeCacheServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CacheServiceServer will
+// result in compilation errors.
+type UnsafeCacheServiceServer interface {
+	mustEmbedUnimplementedCacheServiceServer()
+}
+
+func RegisterCacheServiceServer(s grpc.ServiceRegistrar, srv CacheServiceServer) {
+	s.RegisterService(&CacheService_ServiceDesc, srv)
+}
+
+func _CacheService_RebuildCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebuildCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).RebuildCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_RebuildCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).RebuildCache(ctx, req.(*RebuildCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_GetCacheRebuild_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCacheRebuildRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).GetCacheRebuild(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheService_GetCacheRebuild_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).GetCacheRebuild(ctx, req.(*GetCacheRebuildRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CacheService_ServiceDesc is the grpc.ServiceDesc for CacheService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CacheService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clicker.CacheService",
+	HandlerType: (*CacheServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RebuildCache",
+			Handler:    _CacheService_RebuildCache_Handler,
+		},
+		{
+			MethodName: "GetCacheRebuild",
+			Handler:    _CacheService_GetCacheRebuild_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cache.proto",
+}
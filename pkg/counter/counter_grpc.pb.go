@@ -19,7 +19,8 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	CounterService_Counter_FullMethodName = "/clicker.CounterService/Counter"
+	CounterService_Counter_FullMethodName    = "/clicker.CounterService/Counter"
+	CounterService_Impression_FullMethodName = "/clicker.CounterService/Impression"
 )
 
 // CounterServiceClient is the client API for CounterService service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CounterServiceClient interface {
 	Counter(ctx context.Context, in *CounterRequest, opts ...grpc.CallOption) (*CounterResponse, error)
+	Impression(ctx context.Context, in *ImpressionRequest, opts ...grpc.CallOption) (*ImpressionResponse, error)
 }
 
 type counterServiceClient struct {
@@ -46,11 +48,21 @@ func (c *counterServiceClient) Counter(ctx context.Context, in *CounterRequest,
 	return out, nil
 }
 
+func (c *counterServiceClient) Impression(ctx context.Context, in *ImpressionRequest, opts ...grpc.CallOption) (*ImpressionResponse, error) {
+	out := new(ImpressionResponse)
+	err := c.cc.Invoke(ctx, CounterService_Impression_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CounterServiceServer is the server API for CounterService service.
 // All implementations must embed UnimplementedCounterServiceServer
 // for forward compatibility
 type CounterServiceServer interface {
 	Counter(context.Context, *CounterRequest) (*CounterResponse, error)
+	Impression(context.Context, *ImpressionRequest) (*ImpressionResponse, error)
 	mustEmbedUnimplementedCounterServiceServer()
 }
 
@@ -61,6 +73,9 @@ type UnimplementedCounterServiceServer struct {
 func (UnimplementedCounterServiceServer) Counter(context.Context, *CounterRequest) (*CounterResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Counter not implemented")
 }
+func (UnimplementedCounterServiceServer) Impression(context.Context, *ImpressionRequest) (*ImpressionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Impression not implemented")
+}
 func (UnimplementedCounterServiceServer) mustEmbedUnimplementedCounterServiceServer() {}
 
 // UnsafeCounterServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -92,6 +107,24 @@ func _CounterService_Counter_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CounterService_Impression_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImpressionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CounterServiceServer).Impression(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CounterService_Impression_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CounterServiceServer).Impression(ctx, req.(*ImpressionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CounterService_ServiceDesc is the grpc.ServiceDesc for CounterService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -103,6 +136,10 @@ var CounterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Counter",
 			Handler:    _CounterService_Counter_Handler,
 		},
+		{
+			MethodName: "Impression",
+			Handler:    _CounterService_Impression_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "counter.proto",
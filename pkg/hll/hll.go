@@ -0,0 +1,285 @@
+// Package hll is a HyperLogLog sketch that is bit-compatible with Redis:
+// elements are hashed the way PFADD hashes them, sketches read from Redis
+// with GET can be merged with sketches built here, and Count gives the same
+// estimate as PFCOUNT. Marshaled sketches use the Redis dense encoding, so
+// they can also be loaded back into Redis with SET.
+package hll
+
+import (
+    "errors"
+    "math"
+)
+
+const (
+    precision = 14
+    registers = 1 << precision
+    q         = 64 - precision
+    bits      = 6
+    maxValue  = 1<<bits - 1
+
+    headerSize = 16
+    denseSize  = (registers*bits + 7) / 8
+
+    encodingDense  = 0
+    encodingSparse = 1
+
+    hashSeed = 0xadc83b19
+    alphaInf = 0.721347520444481703680
+)
+
+// maxRegister is the largest value Add can store: the run of zeros in the q
+// hash bits left after the index, plus one. Count indexes its histogram by
+// register value, so larger values are rejected when a sketch is loaded.
+const maxRegister = q + 1
+
+var ErrInvalidSketch = errors.New("invalid HyperLogLog sketch")
+
+type Sketch struct {
+    registers [registers]uint8
+}
+
+func New() *Sketch {
+    return &Sketch{}
+}
+
+// Add adds an element, the same as PFADD would.
+func (s *Sketch) Add(element []byte) {
+    hash := murmurHash64A(element, hashSeed)
+    index := hash & (registers - 1)
+    hash >>= precision
+    hash |= 1 << q
+
+    count := uint8(1)
+    for bit := uint64(1); hash&bit == 0; bit <<= 1 {
+        count++
+    }
+
+    if count > s.registers[index] {
+        s.registers[index] = count
+    }
+}
+
+func (s *Sketch) AddString(element string) {
+    s.Add([]byte(element))
+}
+
+// Merge makes s count the union of s and other. It reports whether any
+// register of s changed, so callers can skip storing an unchanged sketch.
+func (s *Sketch) Merge(other *Sketch) bool {
+    changed := false
+    for i, v := range other.registers {
+        if v > s.registers[i] {
+            s.registers[i] = v
+            changed = true
+        }
+    }
+    return changed
+}
+
+// Count estimates the number of distinct elements added, using the same
+// estimator as Redis (Otmar Ertl's improved raw estimate).
+func (s *Sketch) Count() uint64 {
+    var histogram [q + 2]int
+    for _, v := range s.registers {
+        histogram[v]++
+    }
+
+    m := float64(registers)
+    z := m * tau((m-float64(histogram[q+1]))/m)
+    for j := q; j >= 1; j-- {
+        z += float64(histogram[j])
+        z *= 0.5
+    }
+    z += m * sigma(float64(histogram[0])/m)
+
+    return uint64(math.Round(alphaInf * m * m / z))
+}
+
+// MarshalBinary encodes the sketch the way Redis stores a dense
+// HyperLogLog.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+    data := make([]byte, headerSize+denseSize)
+    copy(data, "HYLL")
+    data[4] = encodingDense
+    // Старший бит последнего байта кэша помечает кэш кардинальности как
+    // невалидный, Redis пересчитает его при первом PFCOUNT.
+    data[15] = 1 << 7
+
+    dense := data[headerSize:]
+    for i, v := range s.registers {
+        byteIndex := i * bits / 8
+        shift := uint(i * bits & 7)
+        dense[byteIndex] |= v << shift
+        if byteIndex+1 < len(dense) {
+            dense[byteIndex+1] |= v >> (8 - shift)
+        }
+    }
+
+    return data, nil
+}
+
+// UnmarshalBinary decodes a sketch in either Redis encoding, as returned by
+// MarshalBinary or by a GET of a PFADD key.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+    if len(data) < headerSize || string(data[:4]) != "HYLL" {
+        return ErrInvalidSketch
+    }
+
+    *s = Sketch{}
+    switch data[4] {
+    case encodingDense:
+        return s.decodeDense(data[headerSize:])
+    case encodingSparse:
+        return s.decodeSparse(data[headerSize:])
+    }
+    return ErrInvalidSketch
+}
+
+func (s *Sketch) decodeDense(dense []byte) error {
+    if len(dense) != denseSize {
+        return ErrInvalidSketch
+    }
+
+    for i := range s.registers {
+        byteIndex := i * bits / 8
+        shift := uint(i * bits & 7)
+        v := uint(dense[byteIndex]) >> shift
+        if byteIndex+1 < len(dense) {
+            v |= uint(dense[byteIndex+1]) << (8 - shift)
+        }
+        v &= maxValue
+        if v > maxRegister {
+            return ErrInvalidSketch
+        }
+        s.registers[i] = uint8(v)
+    }
+    return nil
+}
+
+// decodeSparse reads the run-length encoding Redis uses for small sets:
+// ZERO (00xxxxxx), XZERO (01xxxxxx yyyyyyyy) and VAL (1vvvvvxx) opcodes.
+func (s *Sketch) decodeSparse(sparse []byte) error {
+    index := 0
+    for i := 0; i < len(sparse); i++ {
+        op := sparse[i]
+        switch {
+        case op&0xc0 == 0x00:
+            index += int(op&0x3f) + 1
+        case op&0xc0 == 0x40:
+            if i+1 >= len(sparse) {
+                return ErrInvalidSketch
+            }
+            i++
+            index += (int(op&0x3f)<<8 | int(sparse[i])) + 1
+        default:
+            value := (op>>2)&0x1f + 1
+            run := int(op&0x03) + 1
+            if index+run > registers {
+                return ErrInvalidSketch
+            }
+            for j := 0; j < run; j++ {
+                s.registers[index+j] = value
+            }
+            index += run
+        }
+        if index > registers {
+            return ErrInvalidSketch
+        }
+    }
+
+    if index != registers {
+        return ErrInvalidSketch
+    }
+    return nil
+}
+
+func sigma(x float64) float64 {
+    if x == 1 {
+        return math.Inf(1)
+    }
+
+    y := 1.0
+    z := x
+    for {
+        x *= x
+        prev := z
+        z += x * y
+        y += y
+        if prev == z {
+            return z
+        }
+    }
+}
+
+func tau(x float64) float64 {
+    if x == 0 || x == 1 {
+        return 0
+    }
+
+    y := 1.0
+    z := 1 - x
+    for {
+        x = math.Sqrt(x)
+        prev := z
+        y *= 0.5
+        z -= math.Pow(1-x, 2) * y
+        if prev == z {
+            return z / 3
+        }
+    }
+}
+
+// murmurHash64A is the hash Redis uses for HyperLogLog elements, including
+// its little-endian reading of 8-byte blocks.
+func murmurHash64A(key []byte, seed uint64) uint64 {
+    const (
+        m = 0xc6a4a7935bd1e995
+        r = 47
+    )
+
+    h := seed ^ (uint64(len(key)) * m)
+
+    blocks := len(key) / 8
+    for i := 0; i < blocks; i++ {
+        b := key[i*8:]
+        k := uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 |
+            uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7])<<56
+
+        k *= m
+        k ^= k >> r
+        k *= m
+
+        h ^= k
+        h *= m
+    }
+
+    tail := key[blocks*8:]
+    switch len(tail) {
+    case 7:
+        h ^= uint64(tail[6]) << 48
+        fallthrough
+    case 6:
+        h ^= uint64(tail[5]) << 40
+        fallthrough
+    case 5:
+        h ^= uint64(tail[4]) << 32
+        fallthrough
+    case 4:
+        h ^= uint64(tail[3]) << 24
+        fallthrough
+    case 3:
+        h ^= uint64(tail[2]) << 16
+        fallthrough
+    case 2:
+        h ^= uint64(tail[1]) << 8
+        fallthrough
+    case 1:
+        h ^= uint64(tail[0])
+        h *= m
+    }
+
+    h ^= h >> r
+    h *= m
+    h ^= h >> r
+    return h
+}
@@ -0,0 +1,198 @@
+package hll
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "testing"
+)
+
+// Hashes from the reference MurmurHash64A (Austin Appleby's C code) with
+// the Redis seed, together with the register and value PFADD derives.
+var murmurVectors = []struct {
+    element string
+    hash    uint64
+    index   int
+    value   uint8
+}{
+    {"", 0xd8dfea6585bc9732, 5938, 2},
+    {"a", 0x53d2470a9b43b1a7, 12711, 2},
+    {"foo", 0xe64609b8b0141cb4, 7348, 5},
+    {"clicker", 0x95a83a3b7d8dec7f, 11391, 1},
+    {"0123456789abcdef", 0x9f8565428eaa573d, 5949, 1},
+    {"user-1234567", 0xec96254614a9e9d0, 10704, 1},
+}
+
+func TestMurmurHash64A(t *testing.T) {
+    for _, v := range murmurVectors {
+        if got := murmurHash64A([]byte(v.element), hashSeed); got != v.hash {
+            t.Errorf("murmurHash64A(%q) = %#016x, want %#016x", v.element, got, v.hash)
+        }
+    }
+}
+
+func TestAdd(t *testing.T) {
+    for _, v := range murmurVectors {
+        s := New()
+        s.AddString(v.element)
+        for i, r := range s.registers {
+            want := uint8(0)
+            if i == v.index {
+                want = v.value
+            }
+            if r != want {
+                t.Fatalf("Add(%q): register %d = %d, want %d", v.element, i, r, want)
+            }
+        }
+    }
+}
+
+func TestAddKeepsLargestValue(t *testing.T) {
+    s := New()
+    s.registers[7348] = 9
+    s.AddString("foo")
+    if s.registers[7348] != 9 {
+        t.Fatalf("register = %d, want 9", s.registers[7348])
+    }
+}
+
+// sparseA is what Redis stores after PFADD key a: XZERO 12711, VAL 2 x1,
+// XZERO 3672.
+func sparseA() []byte {
+    data := []byte{'H', 'Y', 'L', 'L', encodingSparse, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+    return append(data,
+        0x40|byte((12711-1)>>8), byte((12711-1)&0xff),
+        0x80|(2-1)<<2,
+        0x40|byte((3672-1)>>8), byte((3672-1)&0xff),
+    )
+}
+
+func TestUnmarshalSparse(t *testing.T) {
+    var s Sketch
+    if err := s.UnmarshalBinary(sparseA()); err != nil {
+        t.Fatal(err)
+    }
+
+    want := New()
+    want.AddString("a")
+    if s.registers != want.registers {
+        t.Fatal("sparse PFADD a does not match Add(\"a\")")
+    }
+    if got := s.Count(); got != 1 {
+        t.Fatalf("Count() = %d, want 1", got)
+    }
+}
+
+func TestUnmarshalEmptySparse(t *testing.T) {
+    // A fresh Redis key: one XZERO opcode covering every register.
+    data := []byte{'H', 'Y', 'L', 'L', encodingSparse, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x7f, 0xff}
+    var s Sketch
+    if err := s.UnmarshalBinary(data); err != nil {
+        t.Fatal(err)
+    }
+    if got := s.Count(); got != 0 {
+        t.Fatalf("Count() = %d, want 0", got)
+    }
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+    s := New()
+    for i := 0; i < 5000; i++ {
+        s.AddString(fmt.Sprintf("user-%d", i))
+    }
+    s.registers[0] = maxRegister
+    s.registers[registers-1] = maxRegister
+
+    data, err := s.MarshalBinary()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(data) != headerSize+denseSize {
+        t.Fatalf("len = %d, want %d", len(data), headerSize+denseSize)
+    }
+
+    var loaded Sketch
+    if err := loaded.UnmarshalBinary(data); err != nil {
+        t.Fatal(err)
+    }
+    if loaded.registers != s.registers {
+        t.Fatal("registers differ after a round trip")
+    }
+}
+
+func TestUnmarshalRejectsLargeRegister(t *testing.T) {
+    s := New()
+    data, err := s.MarshalBinary()
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Register 0 occupies the low six bits of the first dense byte.
+    data[headerSize] = maxValue
+
+    var loaded Sketch
+    if err := loaded.UnmarshalBinary(data); !errors.Is(err, ErrInvalidSketch) {
+        t.Fatalf("err = %v, want ErrInvalidSketch", err)
+    }
+}
+
+func TestUnmarshalRejectsCorruptSparse(t *testing.T) {
+    for name, data := range map[string][]byte{
+        "short":     sparseA()[:len(sparseA())-2],
+        "truncated": sparseA()[:len(sparseA())-1],
+        "overflow":  append(sparseA(), 0x00),
+        "magic":     append([]byte("HYLX"), sparseA()[4:]...),
+    } {
+        var s Sketch
+        if err := s.UnmarshalBinary(data); !errors.Is(err, ErrInvalidSketch) {
+            t.Errorf("%s: err = %v, want ErrInvalidSketch", name, err)
+        }
+    }
+}
+
+func TestMerge(t *testing.T) {
+    a := New()
+    a.AddString("a")
+    a.AddString("foo")
+    b := New()
+    b.AddString("foo")
+    b.AddString("clicker")
+    b.registers[12711] = 1
+
+    if !a.Merge(b) {
+        t.Fatal("Merge() = false, want true")
+    }
+    want := map[int]uint8{12711: 2, 7348: 5, 11391: 1}
+    for i, r := range a.registers {
+        if r != want[i] {
+            t.Fatalf("register %d = %d, want %d", i, r, want[i])
+        }
+    }
+    if got := a.Count(); got != 3 {
+        t.Fatalf("Count() = %d, want 3", got)
+    }
+    if a.Merge(b) {
+        t.Fatal("Merge() of a subset = true, want false")
+    }
+}
+
+func TestCount(t *testing.T) {
+    if got := New().Count(); got != 0 {
+        t.Fatalf("empty Count() = %d, want 0", got)
+    }
+
+    for _, n := range []int{1, 10, 100, 1000, 10000, 100000} {
+        s := New()
+        for i := 0; i < n; i++ {
+            s.AddString(fmt.Sprintf("user-%d", i))
+            // Repeats must not change the estimate.
+            s.AddString(fmt.Sprintf("user-%d", i/2))
+        }
+
+        got := s.Count()
+        // Three standard errors of 1.04/sqrt(m).
+        tolerance := math.Max(1, 3*0.0081*float64(n))
+        if math.Abs(float64(got)-float64(n)) > tolerance {
+            t.Errorf("Count() of %d elements = %d", n, got)
+        }
+    }
+}
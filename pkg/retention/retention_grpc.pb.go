@@ -0,0 +1,109 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.27.1
+// source: retention.proto
+
+package retention
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	RetentionService_ListRetentionRuns_FullMethodName = "/clicker.RetentionService/ListRetentionRuns"
+)
+
+// RetentionServiceClient is the client API for RetentionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RetentionServiceClient interface {
+	ListRetentionRuns(ctx context.Context, in *ListRetentionRunsRequest, opts ...grpc.CallOption) (*ListRetentionRunsResponse, error)
+}
+
+type retentionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRetentionServiceClient(cc grpc.ClientConnInterface) RetentionServiceClient {
+	return &retentionServiceClient{cc}
+}
+
+func (c *retentionServiceClient) ListRetentionRuns(ctx context.Context, in *ListRetentionRunsRequest, opts ...grpc.CallOption) (*ListRetentionRunsResponse, error) {
+	out := new(ListRetentionRunsResponse)
+	err := c.cc.Invoke(ctx, RetentionService_ListRetentionRuns_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RetentionServiceServer is the server API for RetentionService service.
+// All implementations must embed UnimplementedRetentionServiceServer
+// for forward compatibility
+type RetentionServiceServer interface {
+	ListRetentionRuns(context.Context, *ListRetentionRunsRequest) (*ListRetentionRunsResponse, error)
+	mustEmbedUnimplementedRetentionServiceServer()
+}
+
+// UnimplementedRetentionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRetentionServiceServer struct {
+}
+
+func (UnimplementedRetentionServiceServer) ListRetentionRuns(context.Context, *ListRetentionRunsRequest) (*ListRetentionRunsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRetentionRuns not implemented")
+}
+func (UnimplementedRetentionServiceServer) mustEmbedUnimplementedRetentionServiceServer() {}
+
+// UnsafeRetentionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RetentionServiceServer will
+// result in compilation errors.
+type UnsafeRetentionServiceServer interface {
+	mustEmbedUnimplementedRetentionServiceServer()
+}
+
+func RegisterRetentionServiceServer(s grpc.ServiceRegistrar, srv RetentionServiceServer) {
+	s.RegisterService(&RetentionService_ServiceDesc, srv)
+}
+
+func _RetentionService_ListRetentionRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRetentionRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RetentionServiceServer).ListRetentionRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RetentionService_ListRetentionRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RetentionServiceServer).ListRetentionRuns(ctx, req.(*ListRetentionRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RetentionService_ServiceDesc is the grpc.ServiceDesc for RetentionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RetentionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clicker.RetentionService",
+	HandlerType: (*RetentionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListRetentionRuns",
+			Handler:    _RetentionService_ListRetentionRuns_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "retention.proto",
+}
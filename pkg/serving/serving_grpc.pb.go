@@ -0,0 +1,109 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.27.1
+// source: serving.proto
+
+package serving
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ServingService_ServeBanner_FullMethodName = "/clicker.ServingService/ServeBanner"
+)
+
+// ServingServiceClient is the client API for ServingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ServingServiceClient interface {
+	ServeBanner(ctx context.Context, in *ServeBannerRequest, opts ...grpc.CallOption) (*ServeBannerResponse, error)
+}
+
+type servingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServingServiceClient(cc grpc.ClientConnInterface) ServingServiceClient {
+	return &servingServiceClient{cc}
+}
+
+func (c *servingServiceClient) ServeBanner(ctx context.Context, in *ServeBannerRequest, opts ...grpc.CallOption) (*ServeBannerResponse, error) {
+	out := new(ServeBannerResponse)
+	err := c.cc.Invoke(ctx, ServingService_ServeBanner_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ServingServiceServer is the server API for ServingService service.
+// All implementations must embed UnimplementedServingServiceServer
+// for forward compatibility
+type ServingServiceServer interface {
+	ServeBanner(context.Context, *ServeBannerRequest) (*ServeBannerResponse, error)
+	mustEmbedUnimplementedServingServiceServer()
+}
+
+// UnimplementedServingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedServingServiceServer struct {
+}
+
+func (UnimplementedServingServiceServer) ServeBanner(context.Context, *ServeBannerRequest) (*ServeBannerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ServeBanner not implemented")
+}
+func (UnimplementedServingServiceServer) mustEmbedUnimplementedServingServiceServer() {}
+
+// UnsafeServingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ServingServiceServer will
+// result in compilation errors.
+type UnsafeServingServiceServer interface {
+	mustEmbedUnimplementedServingServiceServer()
+}
+
+func RegisterServingServiceServer(s grpc.ServiceRegistrar, srv ServingServiceServer) {
+	s.RegisterService(&ServingService_ServiceDesc, srv)
+}
+
+func _ServingService_ServeBanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServeBannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServingServiceServer).ServeBanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServingService_ServeBanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServingServiceServer).ServeBanner(ctx, req.(*ServeBannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ServingService_ServiceDesc is the grpc.ServiceDesc for ServingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ServingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clicker.ServingService",
+	HandlerType: (*ServingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ServeBanner",
+			Handler:    _ServingService_ServeBanner_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "serving.proto",
+}
@@ -0,0 +1,49 @@
+package signed
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestSignVerify(t *testing.T) {
+    s := NewSigner([]byte("key"))
+    now := time.Unix(1700000000, 0)
+    token := s.Sign("click", now.Add(time.Hour), 7, -1, 0)
+
+    values, err := s.Verify("click", token, now)
+    if err != nil {
+        t.Fatalf("Verify: %v", err)
+    }
+    if len(values) != 3 || values[0] != 7 || values[1] != -1 || values[2] != 0 {
+        t.Fatalf("values = %v, want [7 -1 0]", values)
+    }
+
+    values, err = s.Verify("click", token, now.Add(time.Hour))
+    if !errors.Is(err, ErrExpiredToken) || len(values) != 3 {
+        t.Fatalf("expired token: values %v, err %v", values, err)
+    }
+}
+
+func TestVerifyRejects(t *testing.T) {
+    s := NewSigner([]byte("key"))
+    now := time.Unix(1700000000, 0)
+    token := s.Sign("click", now.Add(time.Hour), 7)
+
+    cases := map[string]struct {
+        signer  *Signer
+        purpose string
+        token   string
+    }{
+        "other purpose": {s, "stream", token},
+        "other key":     {NewSigner([]byte("other")), "click", token},
+        "tampered":      {s, "click", "A" + token[1:]},
+        "no mac":        {s, "click", token[:len(token)-23]},
+        "garbage":       {s, "click", "not a token"},
+    }
+    for name, c := range cases {
+        if _, err := c.signer.Verify(c.purpose, c.token, now); !errors.Is(err, ErrInvalidToken) {
+            t.Errorf("%s: err = %v, want ErrInvalidToken", name, err)
+        }
+    }
+}
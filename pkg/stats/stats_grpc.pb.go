@@ -8,6 +8,7 @@ package stats
 
 import (
 	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,7 +20,14 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	StatsService_Stats_FullMethodName = "/clicker.StatsService/Stats"
+	StatsService_Stats_FullMethodName            = "/clicker.StatsService/Stats"
+	StatsService_StatsByLabels_FullMethodName    = "/clicker.StatsService/StatsByLabels"
+	StatsService_CompareVariants_FullMethodName  = "/clicker.StatsService/CompareVariants"
+	StatsService_BatchStats_FullMethodName       = "/clicker.StatsService/BatchStats"
+	StatsService_TopBanners_FullMethodName       = "/clicker.StatsService/TopBanners"
+	StatsService_WatchStats_FullMethodName       = "/clicker.StatsService/WatchStats"
+	StatsService_CreateWatchToken_FullMethodName = "/clicker.StatsService/CreateWatchToken"
+	StatsService_ExportClicks_FullMethodName     = "/clicker.StatsService/ExportClicks"
 )
 
 // StatsServiceClient is the client API for StatsService service.
@@ -27,6 +35,20 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type StatsServiceClient interface {
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	StatsByLabels(ctx context.Context, in *LabelStatsRequest, opts ...grpc.CallOption) (*LabelStatsResponse, error)
+	CompareVariants(ctx context.Context, in *CompareVariantsRequest, opts ...grpc.CallOption) (*CompareVariantsResponse, error)
+	BatchStats(ctx context.Context, in *BatchStatsRequest, opts ...grpc.CallOption) (*BatchStatsResponse, error)
+	TopBanners(ctx context.Context, in *TopBannersRequest, opts ...grpc.CallOption) (*TopBannersResponse, error)
+	// WatchStats streams running click totals as clicks are flushed. Over
+	// HTTP it is served as server-sent events at GET /stats/watch.
+	WatchStats(ctx context.Context, in *WatchStatsRequest, opts ...grpc.CallOption) (StatsService_WatchStatsClient, error)
+	// CreateWatchToken issues a short-lived token for opening the
+	// server-sent event stream from a browser:
+	// GET /stats/watch?token=<token>.
+	CreateWatchToken(ctx context.Context, in *CreateWatchTokenRequest, opts ...grpc.CallOption) (*WatchToken, error)
+	// ExportClicks streams raw clicks or bucketed totals for loading into a
+	// warehouse. Over HTTP the chunks form a single response body.
+	ExportClicks(ctx context.Context, in *ExportClicksRequest, opts ...grpc.CallOption) (StatsService_ExportClicksClient, error)
 }
 
 type statsServiceClient struct {
@@ -46,11 +68,134 @@ func (c *statsServiceClient) Stats(ctx context.Context, in *StatsRequest, opts .
 	return out, nil
 }
 
+func (c *statsServiceClient) StatsByLabels(ctx context.Context, in *LabelStatsRequest, opts ...grpc.CallOption) (*LabelStatsResponse, error) {
+	out := new(LabelStatsResponse)
+	err := c.cc.Invoke(ctx, StatsService_StatsByLabels_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statsServiceClient) CompareVariants(ctx context.Context, in *CompareVariantsRequest, opts ...grpc.CallOption) (*CompareVariantsResponse, error) {
+	out := new(CompareVariantsResponse)
+	err := c.cc.Invoke(ctx, StatsService_CompareVariants_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statsServiceClient) BatchStats(ctx context.Context, in *BatchStatsRequest, opts ...grpc.CallOption) (*BatchStatsResponse, error) {
+	out := new(BatchStatsResponse)
+	err := c.cc.Invoke(ctx, StatsService_BatchStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statsServiceClient) TopBanners(ctx context.Context, in *TopBannersRequest, opts ...grpc.CallOption) (*TopBannersResponse, error) {
+	out := new(TopBannersResponse)
+	err := c.cc.Invoke(ctx, StatsService_TopBanners_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statsServiceClient) WatchStats(ctx context.Context, in *WatchStatsRequest, opts ...grpc.CallOption) (StatsService_WatchStatsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StatsService_ServiceDesc.Streams[0], StatsService_WatchStats_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &statsServiceWatchStatsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StatsService_WatchStatsClient interface {
+	Recv() (*StatsUpdate, error)
+	grpc.ClientStream
+}
+
+type statsServiceWatchStatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *statsServiceWatchStatsClient) Recv() (*StatsUpdate, error) {
+	m := new(StatsUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *statsServiceClient) CreateWatchToken(ctx context.Context, in *CreateWatchTokenRequest, opts ...grpc.CallOption) (*WatchToken, error) {
+	out := new(WatchToken)
+	err := c.cc.Invoke(ctx, StatsService_CreateWatchToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statsServiceClient) ExportClicks(ctx context.Context, in *ExportClicksRequest, opts ...grpc.CallOption) (StatsService_ExportClicksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StatsService_ServiceDesc.Streams[1], StatsService_ExportClicks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &statsServiceExportClicksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StatsService_ExportClicksClient interface {
+	Recv() (*httpbody.HttpBody, error)
+	grpc.ClientStream
+}
+
+type statsServiceExportClicksClient struct {
+	grpc.ClientStream
+}
+
+func (x *statsServiceExportClicksClient) Recv() (*httpbody.HttpBody, error) {
+	m := new(httpbody.HttpBody)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // StatsServiceServer is the server API for StatsService service.
 // All implementations must embed UnimplementedStatsServiceServer
 // for forward compatibility
 type StatsServiceServer interface {
 	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	StatsByLabels(context.Context, *LabelStatsRequest) (*LabelStatsResponse, error)
+	CompareVariants(context.Context, *CompareVariantsRequest) (*CompareVariantsResponse, error)
+	BatchStats(context.Context, *BatchStatsRequest) (*BatchStatsResponse, error)
+	TopBanners(context.Context, *TopBannersRequest) (*TopBannersResponse, error)
+	// WatchStats streams running click totals as clicks are flushed. Over
+	// HTTP it is served as server-sent events at GET /stats/watch.
+	WatchStats(*WatchStatsRequest, StatsService_WatchStatsServer) error
+	// CreateWatchToken issues a short-lived token for opening the
+	// server-sent event stream from a browser:
+	// GET /stats/watch?token=<token>.
+	CreateWatchToken(context.Context, *CreateWatchTokenRequest) (*WatchToken, error)
+	// ExportClicks streams raw clicks or bucketed totals for loading into a
+	// warehouse. Over HTTP the chunks form a single response body.
+	ExportClicks(*ExportClicksRequest, StatsService_ExportClicksServer) error
 	mustEmbedUnimplementedStatsServiceServer()
 }
 
@@ -61,6 +206,27 @@ type UnimplementedStatsServiceServer struct {
 func (UnimplementedStatsServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
+func (UnimplementedStatsServiceServer) StatsByLabels(context.Context, *LabelStatsRequest) (*LabelStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StatsByLabels not implemented")
+}
+func (UnimplementedStatsServiceServer) CompareVariants(context.Context, *CompareVariantsRequest) (*CompareVariantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompareVariants not implemented")
+}
+func (UnimplementedStatsServiceServer) BatchStats(context.Context, *BatchStatsRequest) (*BatchStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchStats not implemented")
+}
+func (UnimplementedStatsServiceServer) TopBanners(context.Context, *TopBannersRequest) (*TopBannersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopBanners not implemented")
+}
+func (UnimplementedStatsServiceServer) WatchStats(*WatchStatsRequest, StatsService_WatchStatsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStats not implemented")
+}
+func (UnimplementedStatsServiceServer) CreateWatchToken(context.Context, *CreateWatchTokenRequest) (*WatchToken, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWatchToken not implemented")
+}
+func (UnimplementedStatsServiceServer) ExportClicks(*ExportClicksRequest, StatsService_ExportClicksServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportClicks not implemented")
+}
 func (UnimplementedStatsServiceServer) mustEmbedUnimplementedStatsServiceServer() {}
 
 // UnsafeStatsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -92,6 +258,138 @@ func _StatsService_Stats_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StatsService_StatsByLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LabelStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatsServiceServer).StatsByLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatsService_StatsByLabels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatsServiceServer).StatsByLabels(ctx, req.(*LabelStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatsService_CompareVariants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareVariantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatsServiceServer).CompareVariants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatsService_CompareVariants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatsServiceServer).CompareVariants(ctx, req.(*CompareVariantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatsService_BatchStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatsServiceServer).BatchStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatsService_BatchStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatsServiceServer).BatchStats(ctx, req.(*BatchStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatsService_TopBanners_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopBannersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatsServiceServer).TopBanners(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatsService_TopBanners_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatsServiceServer).TopBanners(ctx, req.(*TopBannersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatsService_WatchStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatsServiceServer).WatchStats(m, &statsServiceWatchStatsServer{stream})
+}
+
+type StatsService_WatchStatsServer interface {
+	Send(*StatsUpdate) error
+	grpc.ServerStream
+}
+
+type statsServiceWatchStatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *statsServiceWatchStatsServer) Send(m *StatsUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StatsService_CreateWatchToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWatchTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatsServiceServer).CreateWatchToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatsService_CreateWatchToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatsServiceServer).CreateWatchToken(ctx, req.(*CreateWatchTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatsService_ExportClicks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportClicksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatsServiceServer).ExportClicks(m, &statsServiceExportClicksServer{stream})
+}
+
+type StatsService_ExportClicksServer interface {
+	Send(*httpbody.HttpBody) error
+	grpc.ServerStream
+}
+
+type statsServiceExportClicksServer struct {
+	grpc.ServerStream
+}
+
+func (x *statsServiceExportClicksServer) Send(m *httpbody.HttpBody) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // StatsService_ServiceDesc is the grpc.ServiceDesc for StatsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -103,7 +401,38 @@ var StatsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stats",
 			Handler:    _StatsService_Stats_Handler,
 		},
+		{
+			MethodName: "StatsByLabels",
+			Handler:    _StatsService_StatsByLabels_Handler,
+		},
+		{
+			MethodName: "CompareVariants",
+			Handler:    _StatsService_CompareVariants_Handler,
+		},
+		{
+			MethodName: "BatchStats",
+			Handler:    _StatsService_BatchStats_Handler,
+		},
+		{
+			MethodName: "TopBanners",
+			Handler:    _StatsService_TopBanners_Handler,
+		},
+		{
+			MethodName: "CreateWatchToken",
+			Handler:    _StatsService_CreateWatchToken_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStats",
+			Handler:       _StatsService_WatchStats_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportClicks",
+			Handler:       _StatsService_ExportClicks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "stats.proto",
 }
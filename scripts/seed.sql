@@ -1,33 +1,39 @@
 TRUNCATE TABLE banners CASCADE;
 
-INSERT INTO banners (id, name) VALUES 
-(1, 'Banner #1 - Homepage Hero'),
-(2, 'Banner #2 - Sidebar Promo'),
-(3, 'Banner #3 - Footer Ad'),
-(4, 'Banner #4 - Product Page Top'),
-(5, 'Banner #5 - Category Showcase'),
-(6, 'Banner #6 - Mobile App Promo'),
-(7, 'Banner #7 - Newsletter Signup'),
-(8, 'Banner #8 - Special Offer'),
-(9, 'Banner #9 - Holiday Campaign'),
-(10, 'Banner #10 - Flash Sale'),
-(11, 'Banner #11 - Blog Sidebar'),
-(12, 'Banner #12 - Search Results'),
-(13, 'Banner #13 - Account Page'),
-(14, 'Banner #14 - Checkout Upsell'),
-(15, 'Banner #15 - Social Media'),
-(16, 'Banner #16 - Email Campaign'),
-(17, 'Banner #17 - Partner Promo'),
-(18, 'Banner #18 - Seasonal Deal'),
-(19, 'Banner #19 - Limited Time'),
-(20, 'Banner #20 - Member Exclusive');
+-- Dev API key for the default tenant: ck_dev_default
+UPDATE tenants
+SET api_key_hash = encode(sha256('ck_dev_default'::bytea), 'hex')
+WHERE id = 1;
+
+INSERT INTO banners (id, tenant_id, name) VALUES 
+(1, 1, 'Banner #1 - Homepage Hero'),
+(2, 1, 'Banner #2 - Sidebar Promo'),
+(3, 1, 'Banner #3 - Footer Ad'),
+(4, 1, 'Banner #4 - Product Page Top'),
+(5, 1, 'Banner #5 - Category Showcase'),
+(6, 1, 'Banner #6 - Mobile App Promo'),
+(7, 1, 'Banner #7 - Newsletter Signup'),
+(8, 1, 'Banner #8 - Special Offer'),
+(9, 1, 'Banner #9 - Holiday Campaign'),
+(10, 1, 'Banner #10 - Flash Sale'),
+(11, 1, 'Banner #11 - Blog Sidebar'),
+(12, 1, 'Banner #12 - Search Results'),
+(13, 1, 'Banner #13 - Account Page'),
+(14, 1, 'Banner #14 - Checkout Upsell'),
+(15, 1, 'Banner #15 - Social Media'),
+(16, 1, 'Banner #16 - Email Campaign'),
+(17, 1, 'Banner #17 - Partner Promo'),
+(18, 1, 'Banner #18 - Seasonal Deal'),
+(19, 1, 'Banner #19 - Limited Time'),
+(20, 1, 'Banner #20 - Member Exclusive');
 
 WITH series AS (
   SELECT generate_series(21, 100) as id
 )
-INSERT INTO banners (id, name)
+INSERT INTO banners (id, tenant_id, name)
 SELECT 
     id,
+    1,
     'Banner #' || id::text || ' - ' || 
     CASE (id % 5)
         WHEN 0 THEN 'Premium Ad'
@@ -38,6 +44,55 @@ SELECT
     END as name
 FROM series;
 
+SELECT setval('banners_id_seq', (SELECT MAX(id) FROM banners));
+
+UPDATE banners
+SET url = 'https://example.com/promo/' || id::text,
+    creative_url = 'https://cdn.example.com/banners/' || id::text || '.png',
+    placement = CASE WHEN id <= 20 THEN 'homepage' ELSE 'sidebar' END;
+
+INSERT INTO banner_revisions (tenant_id, banner_id, action, actor, changes)
+SELECT tenant_id, id, 'create', 'seed', jsonb_build_array(
+    jsonb_build_object('field', 'name', 'old', '', 'new', name),
+    jsonb_build_object('field', 'url', 'old', '', 'new', url),
+    jsonb_build_object('field', 'status', 'old', '', 'new', status),
+    jsonb_build_object('field', 'weight', 'old', '', 'new', weight::text)
+)
+FROM banners;
+
+INSERT INTO banner_labels (banner_id, key, value)
+SELECT
+    id,
+    'site',
+    CASE (id % 3)
+        WHEN 0 THEN 'news'
+        WHEN 1 THEN 'shop'
+        WHEN 2 THEN 'blog'
+    END
+FROM banners;
+
+INSERT INTO banner_labels (banner_id, key, value)
+SELECT
+    id,
+    'format',
+    CASE (id % 2)
+        WHEN 0 THEN '300x250'
+        WHEN 1 THEN '728x90'
+    END
+FROM banners;
+
+INSERT INTO banner_variants (banner_id, name, creative_url, weight) VALUES
+(1, 'Control', 'https://cdn.example.com/banners/1/a.png', 50),
+(1, 'Bold headline', 'https://cdn.example.com/banners/1/b.png', 50);
+
+INSERT INTO banner_revisions (tenant_id, banner_id, variant_id, action, actor, changes)
+SELECT b.tenant_id, v.banner_id, v.id, 'variant_create', 'seed', jsonb_build_array(
+    jsonb_build_object('field', 'name', 'old', '', 'new', v.name),
+    jsonb_build_object('field', 'weight', 'old', '', 'new', v.weight::text)
+)
+FROM banner_variants v
+JOIN banners b ON b.id = v.banner_id;
+
 -- Добавляем клики для баннера #1 за последние 24 часа
 WITH RECURSIVE hours AS (
     SELECT 
@@ -48,9 +103,24 @@ WITH RECURSIVE hours AS (
     FROM hours
     WHERE hour_time < date_trunc('hour', NOW())
 )
-INSERT INTO clicks (banner_id, timestamp, count)
+INSERT INTO clicks (tenant_id, banner_id, timestamp, count)
 SELECT 
+    1 as tenant_id,
     1 as banner_id,
     hour_time as timestamp,
     50 as count
 FROM hours;
+
+INSERT INTO banner_totals (tenant_id, banner_id, clicks)
+SELECT tenant_id, banner_id, SUM(count)
+FROM clicks
+GROUP BY tenant_id, banner_id
+ON CONFLICT (banner_id) DO UPDATE SET clicks = EXCLUDED.clicks;
+
+-- Клики выше вставлены задним числом, агрегаты надо пересчитать с них.
+UPDATE rollup_watermarks
+SET watermark = LEAST(watermark, date_trunc(
+    CASE name WHEN 'hourly' THEN 'hour' ELSE 'day' END,
+    (SELECT MIN(timestamp) FROM clicks),
+    'UTC'
+));
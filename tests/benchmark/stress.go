@@ -16,7 +16,8 @@ func runStressTest(rps int) (success float64, latency time.Duration) {
         Method: "GET",
         URL:    "http://localhost:8080/counter/1",
         Header: http.Header{
-            "Content-Type": []string{"application/json"},
+            "Content-Type":  []string{"application/json"},
+            "Authorization": []string{"Bearer ck_dev_default"},
         },
     })
 
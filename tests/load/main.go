@@ -12,6 +12,8 @@ import (
     "encoding/json"
 )
 
+const apiKey = "ck_dev_default"
+
 type CounterResponse struct {
     TotalClicks int64 `json:"total_clicks"`
 }
@@ -42,7 +44,13 @@ func main() {
                     return
                 default:
                     start := time.Now()
-                    resp, err := http.Get("http://localhost:8080/counter/1")
+                    req, err := http.NewRequest(http.MethodGet, "http://localhost:8080/counter/1", nil)
+                    if err != nil {
+                        errorCount.Add(1)
+                        continue
+                    }
+                    req.Header.Set("Authorization", "Bearer "+apiKey)
+                    resp, err := http.DefaultClient.Do(req)
                     duration := time.Since(start)
                     
                     if err != nil {